@@ -1,13 +1,28 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	// Часовые пояса пользователей не должны зависеть от tzdata в образе
+	_ "time/tzdata"
 
 	_ "github.com/ZeroZeroZerooZeroo/subscription-service/docs"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/config"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/handler"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/notify"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/ratelimit"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/server"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/service"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/pkg/database"
 )
@@ -16,42 +31,217 @@ import (
 // @version 1.0
 // @description Сервис управления подписками с расчетом стоимости
 // @host localhost:8080
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT в формате "Bearer {token}"
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Ключ API для межсервисных вызовов
 func main() {
 
+	cfg, args, err := config.Load("api", os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) > 0 {
+		if err := runCommand(cfg, args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	log.Println("Starting subscription service")
-	cfg := config.LoadConfig()
 
-	db, err := database.NewPostgres(cfg.Database.GetDBConnectionString())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// SIGHUP перехватывается до открытия хранилища: по умолчанию он завершает
+	// процесс, а сервер перечитывает по нему TLS-сертификат
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
 
+	store, err := openStorage(ctx, cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to open storage: %v", err)
 	}
+	defer store.close()
 
-	defer func() {
-		log.Println("Closing database connection")
-		db.Close()
-	}()
+	apiKeySvc := service.NewAPIKeyService(store.apiKeys)
+
+	guard := handler.Guard{MaxBodyBytes: cfg.Server.MaxBodyBytes}
+	if cfg.Auth.Enabled {
+		jwtAuthenticator, err := auth.NewJWTAuthenticator(cfg.Auth)
+		if err != nil {
+			log.Fatalf("Failed to configure authentication: %v", err)
+		}
+		guard.Authenticator = auth.Chain{jwtAuthenticator, auth.NewAPIKeyAuthenticator(apiKeySvc)}
+		log.Printf("Authentication enabled: JWT (%s) and API keys", cfg.Auth.JWTAlgorithm)
+	} else {
+		log.Println("WARNING: authentication is disabled (auth.insecure), all subscriptions are publicly accessible")
+	}
 
-	if err := database.RunMigrations(cfg.Database.GetMigrationConnectionString()); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	if cfg.RateLimit.Enabled {
+		guard.Limiter = ratelimit.NewLimiter(cfg.RateLimit)
+		log.Printf("Rate limiting enabled: default %.2f req/s, burst %d", cfg.RateLimit.Default.Rate, cfg.RateLimit.Default.Burst)
 	}
 
-	repo := repository.NewSubscriptionRepository(db.DB)
-	svc := service.NewSubscriptionService(repo)
-	handler := handler.NewSubscriptionHandler(svc)
+	repo := store.subscriptions
+	webhookSvc := service.NewWebhookService(store.webhooks)
+	svc := service.NewSubscriptionService(repo, store.services, store.users)
+	subscriptionHandler := handler.NewSubscriptionHandler(svc, guard)
+	catalogHandler := handler.NewCatalogHandler(service.NewCatalogService(store.services), guard)
+	userSvc := service.NewUserService(store.users, cfg.Users.DeletePolicy == config.DeletePolicyCascade)
+	userHandler := handler.NewUserHandler(userSvc, guard)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeySvc, guard)
+	notificationHandler := handler.NewNotificationHandler(service.NewNotificationService(store.notifications, store.users), guard)
+	webhookHandler := handler.NewWebhookHandler(webhookSvc, guard)
+	healthHandler := handler.NewHealthHandler(service.NewHealthService(store.health))
+
+	if cfg.Retention.DeletedTTL > 0 {
+		go service.NewRetentionJob(repo, cfg.Retention.DeletedTTL, cfg.Retention.Interval).Run(ctx)
+	}
+	if cfg.Renewal.Interval > 0 {
+		go service.NewRenewalJob(repo, cfg.Renewal.Interval, cfg.Renewal.BatchSize).Run(ctx)
+	}
+	if cfg.Notifications.Interval > 0 {
+		go service.NewReminderJob(repo, store.users, store.notifications, notify.NewSenders(cfg.Notifications),
+			cfg.Notifications.Interval, cfg.Notifications.LeadTime, cfg.Notifications.MaxAttempts).Run(ctx)
+	}
+	if cfg.Webhooks.Interval > 0 {
+		go service.NewWebhookDispatcher(store.webhooks, cfg.Webhooks.Interval, cfg.Webhooks.Timeout, cfg.Webhooks.BatchSize,
+			cfg.Webhooks.MaxAttempts, cfg.Webhooks.Backoff, cfg.Webhooks.MaxBackoff).Run(ctx)
+	}
 
 	mux := http.NewServeMux()
-	handler.SetupRoutes(mux)
+	subscriptionHandler.SetupRoutes(mux)
+	catalogHandler.SetupRoutes(mux)
+	userHandler.SetupRoutes(mux)
+	notificationHandler.SetupRoutes(mux)
+	apiKeyHandler.SetupRoutes(mux)
+	webhookHandler.SetupRoutes(mux)
+	healthHandler.SetupRoutes(mux)
 
-	server := &http.Server{
-		Addr:    ":" + cfg.Server.Port,
-		Handler: mux,
+	srv, err := server.New(cfg.Server, mux)
+	if err != nil {
+		log.Fatalf("Failed to configure server: %v", err)
 	}
+	go srv.WatchReload(ctx, hangups)
 
-	log.Printf("Server starting on port %s", cfg.Server.Port)
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err := srv.Run(ctx); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
 
 	log.Println("Server stopped")
 }
+
+// runCommand выполняет служебную команду вместо запуска сервера
+func runCommand(cfg *config.Config, args []string) error {
+	if len(args) == 2 && args[0] == "config" && args[1] == "print" {
+		return cfg.Print(os.Stdout)
+	}
+	return fmt.Errorf("unknown command %q, available: config print", strings.Join(args, " "))
+}
+
+type storage struct {
+	subscriptions repository.SubscriptionRepository
+	services      repository.ServiceCatalogRepository
+	users         repository.UserRepository
+	notifications repository.NotificationRepository
+	webhooks      repository.WebhookRepository
+	apiKeys       repository.APIKeyRepository
+	// health проверяет доступность базы; nil для хранилища в памяти
+	health service.StorageChecker
+	close  func()
+}
+
+func openStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		log.Println("WARNING: using in-memory storage, all data is lost on restart")
+		subscriptions := repository.NewMemorySubscriptionRepository()
+		return &storage{
+			subscriptions: subscriptions,
+			services:      repository.NewMemoryServiceCatalogRepository(subscriptions),
+			users:         repository.NewMemoryUserRepository(subscriptions),
+			notifications: repository.NewMemoryNotificationRepository(subscriptions),
+			webhooks:      repository.NewMemoryWebhookRepository(subscriptions),
+			apiKeys:       repository.NewMemoryAPIKeyRepository(),
+			close:         func() {},
+		}, nil
+
+	case config.StorageDatabase:
+		return openDatabase(ctx, cfg.Database)
+	}
+
+	return nil, fmt.Errorf("unknown storage %q, expected %s or %s", cfg.Storage, config.StorageDatabase, config.StorageMemory)
+}
+
+func openDatabase(ctx context.Context, cfg config.DatabaseConfig) (*storage, error) {
+	var (
+		db            *database.DB
+		subscriptions func(*sql.DB) repository.SubscriptionRepository
+		migrate       func() error
+		err           error
+	)
+
+	switch cfg.Driver() {
+	case config.DriverSQLite:
+		if db, err = database.NewSQLite(cfg.SQLitePath()); err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		subscriptions = repository.NewSQLiteSubscriptionRepository
+		migrate = func() error { return database.RunSQLiteMigrations(cfg.SQLitePath()) }
+
+	default:
+		db, err = database.NewPostgres(ctx, cfg.GetDBConnectionString(), database.PoolOptions{
+			MaxOpenConns:    cfg.Pool.MaxOpenConns,
+			MaxIdleConns:    cfg.Pool.MaxIdleConns,
+			ConnMaxLifetime: cfg.Pool.ConnMaxLifetime,
+			ConnMaxIdleTime: cfg.Pool.ConnMaxIdleTime,
+			ConnectTimeout:  cfg.Pool.ConnectTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		subscriptions = repository.NewSubscriptionRepository
+		migrate = func() error {
+			return database.MigratePostgres(ctx, db, cfg.GetMigrationConnectionString(), cfg.MigrationTimeout)
+		}
+	}
+
+	if cfg.AutoMigrate {
+		err = migrate()
+	} else {
+		log.Println("Automatic migrations are disabled, waiting for an up-to-date schema")
+		err = database.WaitForSchema(ctx, db, cfg.MigrationTimeout)
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	if cfg.Driver() == config.DriverPostgres && cfg.Pool.StatsInterval > 0 {
+		go db.LogStats(ctx, cfg.Pool.StatsInterval)
+	}
+
+	return &storage{
+		subscriptions: subscriptions(db.DB),
+		services:      repository.NewServiceCatalogRepository(db.DB),
+		users:         repository.NewUserRepository(db.DB),
+		notifications: repository.NewNotificationRepository(db.DB),
+		webhooks:      repository.NewWebhookRepository(db.DB),
+		apiKeys:       repository.NewAPIKeyRepository(db.DB),
+		health:        db,
+		close: func() {
+			log.Println("Closing database connection")
+			db.Close()
+		},
+	}, nil
+}
@@ -0,0 +1,161 @@
+// Команда migrate управляет схемой базы данных по миграциям, встроенным в сервис.
+// Подключение берется из тех же файла конфигурации, переменных окружения и флагов, что и у API.
+//
+//	migrate up [N]     применить все или N следующих миграций
+//	migrate down N     откатить N последних миграций
+//	migrate down --all откатить все миграции и удалить таблицы
+//	migrate goto V     перейти к версии V
+//	migrate version    показать текущую версию
+//	migrate force V    записать версию V без выполнения миграций
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/config"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/pkg/database"
+)
+
+const usage = `usage: migrate [flags] <command> [arg]
+
+flags are the same as for the API, e.g. --config or --database.dsn
+
+commands:
+  up [N]     apply all or N next migrations
+  down N     roll back N last migrations
+  down --all roll back all migrations, dropping every table
+  goto V     migrate up or down to version V
+  version    print current schema version
+  force V    set schema version to V without running migrations`
+
+func main() {
+	cfg, args, err := config.Load("migrate", os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, usage)
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	m, err := openMigrator(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to open migrator: %v", err)
+	}
+	defer m.Close()
+
+	if err := run(m, args[0], args[1:]); err != nil {
+		m.Close()
+		log.Fatalf("Migration %s failed: %v", args[0], err)
+	}
+}
+
+func openMigrator(cfg config.DatabaseConfig) (*database.Migrator, error) {
+	if cfg.Driver() == config.DriverSQLite {
+		return database.NewSQLiteMigrator(cfg.SQLitePath())
+	}
+	return database.NewMigrator(cfg.GetMigrationConnectionString())
+}
+
+func run(m *database.Migrator, command string, args []string) error {
+	switch command {
+	case "up":
+		steps := 0
+		if len(args) > 0 {
+			n, err := parseSteps(args[0])
+			if err != nil {
+				return err
+			}
+			steps = n
+		}
+		if err := m.Up(steps); err != nil {
+			return err
+		}
+
+	case "down":
+		// Полный откат удаляет все данные, поэтому требует явного --all
+		if len(args) == 0 {
+			return fmt.Errorf("down requires a number of steps or --all to roll back every migration")
+		}
+		steps := 0
+		if args[0] != "--all" && args[0] != "-all" {
+			n, err := parseSteps(args[0])
+			if err != nil {
+				return err
+			}
+			steps = n
+		}
+		if err := m.Down(steps); err != nil {
+			return err
+		}
+
+	case "goto":
+		version, err := versionArg(args)
+		if err != nil {
+			return err
+		}
+		if err := m.Goto(uint(version)); err != nil {
+			return err
+		}
+
+	case "force":
+		version, err := versionArg(args)
+		if err != nil {
+			return err
+		}
+		if err := m.Force(version); err != nil {
+			return err
+		}
+
+	case "version":
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+
+	return printVersion(m)
+}
+
+func versionArg(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("version is required")
+	}
+	version, err := strconv.Atoi(args[0])
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid version %q", args[0])
+	}
+	return version, nil
+}
+
+func printVersion(m *database.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return fmt.Errorf("failed to read version: %w", err)
+	}
+
+	if dirty {
+		fmt.Printf("version %d (dirty: fix the schema and run force %d)\n", version, version)
+	} else {
+		fmt.Printf("version %d\n", version)
+	}
+	return nil
+}
+
+// parseSteps разбирает положительное число миграций
+func parseSteps(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid number of steps %q", arg)
+	}
+	return n, nil
+}
@@ -15,48 +15,64 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/subscriptions": {
+        "/admin/api-keys": {
             "get": {
-                "description": "Возвращает детальную информацию о подписке по ее идентификатору",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все ключи API, включая отозванные, без секретов",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "подписки"
-                ],
-                "summary": "Получить подписку по ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    }
+                    "ключи API"
                 ],
+                "summary": "Список ключей API",
                 "responses": {
                     "200": {
-                        "description": "Найденная подписка",
+                        "description": "Список ключей",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Subscription"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.APIKey"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "ID обязателен",
+                    "401": {
+                        "description": "Требуется аутентификация",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Подписка не найдена",
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Обновляет данные существующей подписки. Все поля опциональны.",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает ключ для межсервисных вызовов. Секрет возвращается только в этом ответе.",
                 "consumes": [
                     "application/json"
                 ],
@@ -64,30 +80,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "подписки"
+                    "ключи API"
                 ],
-                "summary": "Обновить подписку",
+                "summary": "Создать ключ API",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "description": "Данные для обновления подписки",
+                        "description": "Название и области доступа ключа",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.UpdateSubscriptionRequest"
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Подписка успешно обновлена"
+                    "201": {
+                        "description": "Созданный ключ",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.APIKeyWithSecret"
+                        }
                     },
                     "400": {
                         "description": "Неверное тело запроса",
@@ -95,46 +107,96 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Подписка не найдена",
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Создает новую подписку для пользователя с автоматическим расчетом даты окончания (+1 месяц)",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+            }
+        },
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Отзывает ключ; запросы с ним больше не принимаются",
                 "tags": [
-                    "подписки"
+                    "ключи API"
                 ],
-                "summary": "Создать новую подписку",
+                "summary": "Отозвать ключ API",
                 "parameters": [
                     {
-                        "description": "Данные для создания подписки",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateSubscriptionRequest"
-                        }
+                        "type": "string",
+                        "description": "ID ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Созданная подписка",
+                    "204": {
+                        "description": "Ключ отозван"
+                    },
+                    "400": {
+                        "description": "Неверный ID",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Subscription"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Неверное тело запроса",
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Ключ не найден или уже отозван",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
                         "schema": {
                             "type": "string"
                         }
@@ -146,37 +208,71 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаляет подписку по идентификатору",
+            }
+        },
+        "/admin/api-keys/{id}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выпускает новый секрет для ключа; старый секрет сразу перестает действовать",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "подписки"
+                    "ключи API"
                 ],
-                "summary": "Удалить подписку",
+                "summary": "Ротация ключа API",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID подписки",
+                        "description": "ID ключа",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Подписка успешно удалена"
+                    "200": {
+                        "description": "Ключ с новым секретом",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.APIKeyWithSecret"
+                        }
                     },
                     "400": {
-                        "description": "ID обязателен",
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Подписка не найдена",
+                        "description": "Ключ не найден или отозван",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "string"
                         }
@@ -184,42 +280,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions/list": {
+        "/admin/webhooks": {
             "get": {
-                "description": "Возвращает пагинированный список всех подписок",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все webhook без секретов",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "подписки"
-                ],
-                "summary": "Список подписок",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Лимит (по умолчанию: 10, максимум: 100)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Смещение (по умолчанию: 0)",
-                        "name": "offset",
-                        "in": "query"
-                    }
+                    "webhook"
                 ],
+                "summary": "Список webhook",
                 "responses": {
                     "200": {
-                        "description": "Список подписок",
+                        "description": "Список webhook",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Subscription"
+                                "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Webhook"
                             }
                         }
                     },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -227,11 +330,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/subscriptions/total-cost": {
+            },
             "post": {
-                "description": "Рассчитывает общую стоимость подписок за указанный период с фильтрацией по пользователю и сервису",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Подписывает внешнюю систему на события подписок subscription.created, subscription.updated и subscription.deleted. Каждый запрос подписан заголовком X-Webhook-Signature: sha256=HMAC-SHA256 секрета от строки \"{X-Webhook-Timestamp}.{тело}\". Секрет возвращается только в этом ответе.",
                 "consumes": [
                     "application/json"
                 ],
@@ -239,25 +345,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "стоимость"
+                    "webhook"
                 ],
-                "summary": "Расчет общей стоимости",
+                "summary": "Создать webhook",
                 "parameters": [
                     {
-                        "description": "Данные для расчета стоимости",
+                        "description": "URL, секрет и типы событий",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CalculateCostRequest"
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateWebhookRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Результат расчета стоимости",
+                    "201": {
+                        "description": "Созданный webhook",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CalculateCostResponse"
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.WebhookWithSecret"
                         }
                     },
                     "400": {
@@ -266,96 +372,2790 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CalculateCostRequest": {
-            "description": "Тело запроса для расчета общей стоимости подписок",
-            "type": "object",
-            "required": [
-                "end_period",
-                "service_name",
-                "start_period",
-                "user_id"
-            ],
-            "properties": {
-                "end_period": {
-                    "type": "string",
-                    "example": "12-2025"
-                },
-                "service_name": {
-                    "type": "string",
-                    "example": "Yandex Plus"
-                },
-                "start_period": {
-                    "type": "string",
-                    "example": "01-2025"
-                },
-                "user_id": {
-                    "type": "string",
+        },
+        "/admin/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет webhook вместе с журналом доставок; недоставленные события больше не отправляются",
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Удалить webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Webhook удален"
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает доставки событий на webhook, начиная с последних: статус, число попыток, код ответа, ошибку и время следующей попытки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Журнал доставок webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Лимит (по умолчанию: 10, максимум: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение (по умолчанию: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Доставки событий",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Отвечает 200, пока процесс обрабатывает запросы",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "состояние"
+                ],
+                "summary": "Проверка жизнеспособности",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Проверяет доступность базы данных и что версия схемы не ниже ожидаемой",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "состояние"
+                ],
+                "summary": "Проверка готовности",
+                "responses": {
+                    "200": {
+                        "description": "Сервис готов",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Readiness"
+                        }
+                    },
+                    "503": {
+                        "description": "Сервис не готов",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Readiness"
+                        }
+                    }
+                }
+            }
+        },
+        "/services": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает все сервисы каталога с псевдонимами и тарифами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "каталог сервисов"
+                ],
+                "summary": "Список сервисов каталога",
+                "responses": {
+                    "200": {
+                        "description": "Сервисы каталога",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Service"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет сервис с псевдонимами и тарифами. Подписки без сервиса, чье название совпадает с названием или псевдонимом, привязываются к нему.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "каталог сервисов"
+                ],
+                "summary": "Добавить сервис в каталог",
+                "parameters": [
+                    {
+                        "description": "Название, псевдонимы, категория и тарифы",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.ServiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный сервис",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Название или псевдоним занят другим сервисом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/resolve": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Сопоставляет свободное название с сервисом каталога по названию и псевдонимам без учета регистра",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "каталог сервисов"
+                ],
+                "summary": "Найти сервис по названию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название или псевдоним",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Найденный сервис",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Название обязательно",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сервис не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает сервис каталога с псевдонимами и тарифами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "каталог сервисов"
+                ],
+                "summary": "Получить сервис каталога",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сервиса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сервис",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Service"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сервис не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет сервис целиком. Новое название переносится в привязанные подписки.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "каталог сервисов"
+                ],
+                "summary": "Изменить сервис каталога",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сервиса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Название, псевдонимы, категория и тарифы",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.ServiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Измененный сервис",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сервис не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Название или псевдоним занят другим сервисом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет сервис, если на него не ссылается ни одна подписка, включая удаленные",
+                "tags": [
+                    "каталог сервисов"
+                ],
+                "summary": "Удалить сервис из каталога",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сервиса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Сервис удален"
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сервис не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Сервис используется подписками",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает детальную информацию о подписке по ее идентификатору. Общая подписка доступна владельцу и участникам.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Получить подписку по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Найденная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "ID обязателен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Обновляет данные существующей подписки. Все поля опциональны. Изменять подписку может только владелец; members заменяет участников целиком.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Обновить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для обновления подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.UpdateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка успешно обновлена"
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создает новую подписку для пользователя с автоматическим расчетом даты окончания (+1 месяц). С trial_months подписка начинается с пробного периода по цене trial_price (может быть 0), а дата окончания сдвигается на его длину. Участники members делят стоимость с владельцем и видят подписку в своих списках. С auto_renew подписка продлевается на месяц каждый раз, когда наступает дата окончания.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Создать новую подписку",
+                "parameters": [
+                    {
+                        "description": "Данные для создания подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Помечает подписку удаленной; ее можно восстановить, пока она не очищена по сроку хранения. Параметр permanent удаляет подписку сразу (только администратор).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Удалить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Удалить безвозвратно",
+                        "name": "permanent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка успешно удалена"
+                    },
+                    "400": {
+                        "description": "ID обязателен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Безвозвратное удаление доступно только администратору",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает пагинированный список подписок с необязательным отбором по категории и тегу, включая общие подписки, где пользователь участник",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Список подписок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Лимит (по умолчанию: 10, максимум: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение (по умолчанию: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Категория",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тег",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список подписок",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Subscription"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/total-cost": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Рассчитывает общую стоимость подписок за указанный период с фильтрацией по пользователю и сервису. Пробный период учитывается по trial_price, после него — обычная цена. Каждый месяц подписки учитывается в периоде, в котором начался, за вычетом действующих скидок; месяцы, начавшиеся во время приостановки или после отмены, не учитываются. С group_by сервис можно не указывать, а сумма дополнительно разбивается по категориям, тегам или месяцам со скидками каждой группы. perspective=member считает долю пользователя во всех подписках, где он владелец или участник, вместо полной стоимости оплачиваемых им подписок.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "стоимость"
+                ],
+                "summary": "Расчет общей стоимости",
+                "parameters": [
+                    {
+                        "description": "Данные для расчета стоимости",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CalculateCostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результат расчета стоимости",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CalculateCostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к подпискам другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отменяет активную или приостановленную подписку сразу либо, с at_period_end, в конце текущего расчетного периода. Месяцы, начавшиеся после отмены, не оплачиваются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Отменить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Отменить в конце текущего расчетного периода",
+                        "name": "at_period_end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка после перехода",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Subscription"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Переход из текущего состояния недопустим",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/discounts": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Добавляет скидку на оплачиваемые месяцы подписки: процент от цены, сумму или цену на время скидки. Скидка действует с start_period до end_period или первые cycles месяцев; месяцы считаются в часовом поясе владельца подписки.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Добавить скидку к подписке",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Вид, размер и срок действия скидки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.DiscountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Добавленная скидка",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Discount"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/discounts/{discount_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаляет скидку; расчет стоимости за прошедшие месяцы тоже перестает ее учитывать",
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Удалить скидку подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID скидки",
+                        "name": "discount_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Скидка удалена"
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка или скидка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает журнал создания, изменений и удаления подписки с состоянием до и после каждого изменения",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "История изменений подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Записи журнала в хронологическом порядке",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.SubscriptionAuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Приостанавливает активную подписку. Месяцы, начавшиеся во время приостановки, не оплачиваются. Подписку с назначенной отменой приостановить нельзя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Приостановить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка после перехода",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Subscription"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Переход из текущего состояния недопустим",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Снимает пометку об удалении с подписки, которая еще не очищена по сроку хранения",
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Восстановить удаленную подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка восстановлена"
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Подписка не удалена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возобновляет приостановленную подписку; оплата продолжается с ближайшего расчетного месяца.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "подписки"
+                ],
+                "summary": "Возобновить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка после перехода",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Subscription"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Переход из текущего состояния недопустим",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает пагинированный список профилей пользователей",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "пользователи"
+                ],
+                "summary": "Список пользователей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Лимит (по умолчанию: 10, максимум: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение (по умолчанию: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список пользователей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.User"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Требуется роль администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создает профиль пользователя. Подписки можно создавать только существующим пользователям. Часовой пояс по умолчанию — UTC, валюта — RUB.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "пользователи"
+                ],
+                "summary": "Создать пользователя",
+                "parameters": [
+                    {
+                        "description": "ID, имя, email, часовой пояс и валюта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к профилю другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь уже существует",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает профиль пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "пользователи"
+                ],
+                "summary": "Получить пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к профилю другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменяет переданные поля профиля. Новый часовой пояс применяется к датам начала, заданным после изменения; сохраненные подписки не сдвигаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "пользователи"
+                ],
+                "summary": "Изменить пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля профиля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Измененный пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к профилю другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаляет профиль пользователя. По политике USERS_DELETE_POLICY=restrict пользователя с подписками, включая удаленные, удалить нельзя; при cascade его подписки удаляются окончательно.",
+                "tags": [
+                    "пользователи"
+                ],
+                "summary": "Удалить пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Пользователь удален"
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к профилю другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "У пользователя есть подписки",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает настройки напоминаний о продлении подписок. Пока пользователь их не менял, напоминания приходят на email профиля за срок по умолчанию.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "уведомления"
+                ],
+                "summary": "Получить настройки уведомлений",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Настройки уведомлений",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.NotificationPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к профилю другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменяет переданные настройки напоминаний. lead_days от 1 до 30 задает свой срок напоминания, 0 возвращает срок по умолчанию; пустой webhook_url отключает webhook.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "уведомления"
+                ],
+                "summary": "Изменить настройки уведомлений",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые настройки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.UpdateNotificationPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Измененные настройки",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.NotificationPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к профилю другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса слишком большое",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Тело запроса должно быть в формате JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/notifications/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает записи об отправке напоминаний пользователю, начиная с последних. Неудачная отправка повторяется, пока не исчерпано число попыток.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "уведомления"
+                ],
+                "summary": "Отправленные напоминания",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Лимит (по умолчанию: 10, максимум: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение (по умолчанию: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Записи об отправке",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.NotificationDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к профилю другого пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.APIKey": {
+            "description": "Информация о ключе API (без секрета)",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "billing-backend"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "sk_3f9a1c2e"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscriptions:read",
+                        "reports:read"
+                    ]
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.APIKeyWithSecret": {
+            "description": "Ответ при создании или ротации ключа; секрет показывается только один раз",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "key": {
+                    "type": "string",
+                    "example": "sk_3f9a1c2e5b7d4a6f8e0c1b3d5f7a9c2e4b6d8f0a1c3e5b7d"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "billing-backend"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "sk_3f9a1c2e"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscriptions:read",
+                        "reports:read"
+                    ]
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CalculateCostRequest": {
+            "description": "Тело запроса для расчета общей стоимости подписок",
+            "type": "object",
+            "required": [
+                "end_period",
+                "start_period",
+                "user_id"
+            ],
+            "properties": {
+                "end_period": {
+                    "type": "string",
+                    "example": "02-2025"
+                },
+                "group_by": {
+                    "type": "string",
+                    "enum": [
+                        "category",
+                        "tag",
+                        "month"
+                    ],
+                    "example": "category"
+                },
+                "perspective": {
+                    "description": "Perspective payer считает полную стоимость подписок, которые оплачивает\nпользователь; member — его долю во всех подписках, где он участвует",
+                    "type": "string",
+                    "enum": [
+                        "payer",
+                        "member"
+                    ],
+                    "example": "payer"
+                },
+                "service_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "start_period": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CalculateCostResponse": {
+            "description": "Ответ с результатом расчета общей стоимости",
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "Currency — валюта по умолчанию из профиля пользователя",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_period": {
+                    "type": "string",
+                    "example": "02-2025"
+                },
+                "group_by": {
+                    "type": "string",
+                    "example": "category"
+                },
+                "groups": {
+                    "description": "Подписка с несколькими тегами входит в каждую из их групп",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CostGroup"
+                    }
+                },
+                "perspective": {
+                    "type": "string",
+                    "example": "payer"
+                },
+                "service_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "start_period": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "total_cost": {
+                    "type": "integer",
+                    "example": 18000
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CostGroup": {
+            "description": "Итог по группе; пустой ключ — подписки без категории или без тегов. discount — сумма скидок, уже вычтенная из total_cost.",
+            "type": "object",
+            "properties": {
+                "discount": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "key": {
+                    "type": "string",
+                    "example": "music"
+                },
+                "total_cost": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateAPIKeyRequest": {
+            "description": "Тело запроса для создания ключа API",
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "billing-backend"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscriptions:read",
+                        "reports:read"
+                    ]
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateSubscriptionRequest": {
+            "description": "Тело запроса для создания новой подписки",
+            "type": "object",
+            "required": [
+                "start_date",
+                "user_id"
+            ],
+            "properties": {
+                "auto_renew": {
+                    "description": "AutoRenew включает ежемесячное продление подписки",
+                    "type": "boolean",
+                    "example": true
+                },
+                "category": {
+                    "description": "Category заменяет категорию сервиса из каталога",
+                    "type": "string",
+                    "example": "streaming"
+                },
+                "members": {
+                    "description": "Members разделяет стоимость подписки с другими пользователями",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.SubscriptionMember"
+                    }
+                },
+                "plan": {
+                    "type": "string",
+                    "example": "Семейный"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 1500
+                },
+                "service_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "family",
+                        "work"
+                    ]
+                },
+                "trial_months": {
+                    "description": "TrialMonths — длина пробного периода в месяцах, TrialPrice — его стоимость",
+                    "type": "integer",
+                    "example": 1
+                },
+                "trial_price": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateUserRequest": {
+            "description": "Тело запроса для создания профиля. Без id пользователь с JWT создает свой профиль.",
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "ivan@example.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Иван Петров"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateWebhookRequest": {
+            "description": "Тело запроса для создания webhook. Без events webhook получает все события, без secret секрет генерируется.",
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscription.created",
+                        "subscription.deleted"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "3f9a1c2e5b7d4a6f8e0c1b3d"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://billing.example.com/hooks/subscriptions"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Discount": {
+            "description": "Скидка на оплачиваемые расчетные периоды (месяцы) подписки, начавшиеся с starts_at: до ends_at или первые cycles периодов. percent — процент от цены, amount — сумма, на которую снижается цена, price — цена на время скидки.",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "cycles": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Первые 3 месяца за 99 ₽"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "amount",
+                        "price"
+                    ],
+                    "example": "price"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer",
+                    "example": 99
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.DiscountRequest": {
+            "description": "Тело запроса для добавления скидки. Без start_period скидка действует с первого оплачиваемого месяца; end_period и cycles взаимоисключающие, без них скидка бессрочная.",
+            "type": "object",
+            "required": [
+                "kind"
+            ],
+            "properties": {
+                "cycles": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Первые 3 месяца за 99 ₽"
+                },
+                "end_period": {
+                    "type": "string",
+                    "example": "03-2025"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "amount",
+                        "price"
+                    ],
+                    "example": "price"
+                },
+                "start_period": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "value": {
+                    "type": "integer",
+                    "example": 99
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.NotificationDelivery": {
+            "description": "Запись об отправке напоминания. Для каждой подписки, канала и даты продления создается одна запись, поэтому напоминание не повторяется.",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "webhook"
+                    ],
+                    "example": "email"
+                },
+                "claimed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "renews_at": {
+                    "type": "string"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "sent",
+                        "failed"
+                    ],
+                    "example": "sent"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "user_id": {
+                    "type": "string",
                     "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
                 }
             }
         },
-        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CalculateCostResponse": {
-            "description": "Ответ с результатом расчета общей стоимости",
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.NotificationPreferences": {
+            "description": "Настройки напоминаний о продлении подписок с auto_renew. Напоминание приходит за lead_days дней до продления (0 — срок по умолчанию) на email профиля и/или POST-запросом на webhook_url.",
             "type": "object",
             "properties": {
-                "end_period": {
+                "email": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "lead_days": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "renewal_reminders": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string",
-                    "example": "12-2025"
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
                 },
-                "service_name": {
+                "webhook_url": {
                     "type": "string",
-                    "example": "Yandex Plus"
+                    "example": "https://hooks.example.com/renewals"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Readiness": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
                 },
-                "start_period": {
+                "expected_schema_version": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "schema_dirty": {
+                    "type": "boolean"
+                },
+                "schema_version": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "status": {
                     "type": "string",
-                    "example": "01-2025"
+                    "example": "ready"
                 },
-                "total_cost": {
+                "storage": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Service": {
+            "description": "Сервис каталога: каноническое название, псевдонимы и тарифы",
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Яндекс Плюс",
+                        "yandex+"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "music"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer",
-                    "example": 18000
+                    "example": 1
                 },
-                "user_id": {
+                "name": {
                     "type": "string",
-                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                    "example": "Yandex Plus"
+                },
+                "plans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.ServicePlan"
+                    }
                 }
             }
         },
-        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.CreateSubscriptionRequest": {
-            "description": "Тело запроса для создания новой подписки",
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.ServicePlan": {
             "type": "object",
-            "required": [
-                "price",
-                "service_name",
-                "start_date",
-                "user_id"
-            ],
             "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Семейный"
+                },
                 "price": {
                     "type": "integer",
-                    "example": 1500
+                    "example": 649
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.ServiceRequest": {
+            "description": "Тело запроса для создания или изменения сервиса каталога",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Яндекс Плюс",
+                        "yandex+"
+                    ]
                 },
-                "service_name": {
+                "category": {
+                    "type": "string",
+                    "example": "music"
+                },
+                "name": {
                     "type": "string",
                     "example": "Yandex Plus"
                 },
-                "start_date": {
+                "plans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.ServicePlan"
+                    }
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.StatusTransition": {
+            "description": "Переход между состояниями подписки. effective_at — момент, с которого действует новое состояние; для отмены в конце периода он позже created_at.",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "effective_at": {
+                    "type": "string"
+                },
+                "from": {
                     "type": "string",
-                    "example": "01-2025"
+                    "example": "active"
                 },
-                "user_id": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to": {
                     "type": "string",
-                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                    "example": "paused"
                 }
             }
         },
@@ -363,6 +3163,30 @@ const docTemplate = `{
             "description": "Информация о подписке",
             "type": "object",
             "properties": {
+                "auto_renew": {
+                    "description": "AutoRenew продлевает подписку на месяц, когда наступает EndDate",
+                    "type": "boolean"
+                },
+                "cancel_at": {
+                    "type": "string"
+                },
+                "category": {
+                    "description": "Category берется из каталога, если не задана у самой подписки (CustomCategory)",
+                    "type": "string",
+                    "example": "music"
+                },
+                "custom_category": {
+                    "type": "boolean"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Discount"
+                    }
+                },
                 "end_date": {
                     "type": "string",
                     "example": "02-2025"
@@ -371,10 +3195,21 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "members": {
+                    "description": "Members — пользователи, с которыми разделена стоимость; платит владелец UserID",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.SubscriptionMember"
+                    }
+                },
                 "price": {
                     "type": "integer",
                     "example": 1500
                 },
+                "service_id": {
+                    "type": "integer",
+                    "example": 1
+                },
                 "service_name": {
                     "type": "string",
                     "example": "Yandex Plus"
@@ -383,20 +3218,157 @@ const docTemplate = `{
                     "type": "string",
                     "example": "01-2025"
                 },
+                "status": {
+                    "description": "Status — состояние подписки, CancelAt — момент, с которого она отменена\n(в будущем, если отмена назначена на конец расчетного периода)",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "paused",
+                        "cancelled",
+                        "expired"
+                    ],
+                    "example": "active"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "family",
+                        "work"
+                    ]
+                },
+                "transitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.StatusTransition"
+                    }
+                },
+                "trial_ends_at": {
+                    "type": "string"
+                },
+                "trial_months": {
+                    "description": "Пробный период длиной TrialMonths месяцев от StartDate до TrialEndsAt стоит\nTrialPrice за весь период; после него подписка оплачивается по Price",
+                    "type": "integer",
+                    "example": 1
+                },
+                "trial_price": {
+                    "type": "integer",
+                    "example": 0
+                },
                 "user_id": {
                     "type": "string",
                     "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
                 }
             }
         },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.SubscriptionAuditEntry": {
+            "description": "Запись истории изменений подписки",
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "user:60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "changed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "operation": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "restore",
+                        "renew"
+                    ],
+                    "example": "update"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.SubscriptionMember": {
+            "description": "Участник платит фиксированную сумму amount за каждый месяц или долю остатка пропорционально weight (по умолчанию 1). Владелец подписки участвует с весом 1, если не указан среди участников явно.",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "0b9b2c55-7c7a-4c61-9d8c-1f6e2a3b4c5d"
+                },
+                "weight": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.UpdateNotificationPreferencesRequest": {
+            "description": "Тело запроса для изменения настроек уведомлений; изменяются только переданные поля. Пустой webhook_url отключает webhook.",
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "lead_days": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "renewal_reminders": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "webhook_url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/renewals"
+                }
+            }
+        },
         "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.UpdateSubscriptionRequest": {
             "description": "Тело запроса для обновления существующей подписки",
             "type": "object",
             "properties": {
+                "auto_renew": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "category": {
+                    "description": "Category задает свою категорию; пустая строка возвращает категорию из каталога",
+                    "type": "string",
+                    "example": "streaming"
+                },
+                "members": {
+                    "description": "Members заменяет участников целиком; пустой список делает подписку личной",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.SubscriptionMember"
+                    }
+                },
                 "price": {
                     "type": "integer",
                     "example": 2000
                 },
+                "service_id": {
+                    "type": "integer",
+                    "example": 1
+                },
                 "service_name": {
                     "type": "string",
                     "example": "Yandex Plus Premium"
@@ -405,11 +3377,211 @@ const docTemplate = `{
                     "type": "string",
                     "example": "02-2025"
                 },
+                "tags": {
+                    "description": "Tags заменяет теги целиком; пустой список удаляет все теги",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "family"
+                    ]
+                },
+                "trial_months": {
+                    "description": "TrialMonths меняет длину пробного периода, 0 убирает его",
+                    "type": "integer",
+                    "example": 2
+                },
+                "trial_price": {
+                    "type": "integer",
+                    "example": 99
+                },
                 "user_id": {
                     "type": "string",
                     "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
                 }
             }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.UpdateUserRequest": {
+            "description": "Тело запроса для изменения профиля; изменяются только переданные поля",
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "ivan@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Иван Петров"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Asia/Yekaterinburg"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.User": {
+            "description": "Профиль пользователя с часовым поясом и валютой по умолчанию",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "ivan@example.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Иван Петров"
+                },
+                "timezone": {
+                    "description": "Timezone — часовой пояс IANA, в котором считаются границы месяцев подписок",
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.Webhook": {
+            "description": "Webhook, на который отправляются события подписок (без секрета)",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscription.created",
+                        "subscription.deleted"
+                    ]
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://billing.example.com/hooks/subscriptions"
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.WebhookDelivery": {
+            "description": "Запись журнала доставок. Неудачная доставка повторяется в next_attempt_at с удваивающейся задержкой; после последней попытки получает статус failed.",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string",
+                    "example": "subscription.created"
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "2f1c6a0e-8d4b-4f4e-9a57-1b2c3d4e5f60"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "sent",
+                        "failed"
+                    ],
+                    "example": "sent"
+                },
+                "webhook_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "github_com_ZeroZeroZerooZeroo_subscription-service_internal_model.WebhookWithSecret": {
+            "description": "Ответ при создании webhook; секрет показывается только один раз",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscription.created",
+                        "subscription.deleted"
+                    ]
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_3f9a1c2e5b7d4a6f8e0c1b3d5f7a9c2e4b6d8f0a1c3e5b7d"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://billing.example.com/hooks/subscriptions"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Ключ API для межсервисных вызовов",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT в формате \"Bearer {token}\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyVerifier проверяет ключ API и возвращает соответствующего клиента
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*Principal, error)
+}
+
+type APIKeyAuthenticator struct {
+	verifier APIKeyVerifier
+}
+
+func NewAPIKeyAuthenticator(verifier APIKeyVerifier) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{verifier: verifier}
+}
+
+// Authenticate принимает ключ из заголовка X-API-Key или Authorization: ApiKey <key>
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if ok && strings.EqualFold(scheme, "ApiKey") {
+			key = value
+		}
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	return a.verifier.VerifyAPIKey(r.Context(), key)
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type JWTAuthenticator struct {
+	parser      *jwt.Parser
+	keyFunc     jwt.Keyfunc
+	userIDClaim string
+	rolesClaim  string
+	adminRole   string
+}
+
+func NewJWTAuthenticator(cfg config.AuthConfig) (*JWTAuthenticator, error) {
+	keyFunc, err := newKeyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.JWTAlgorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.JWTAudience))
+	}
+
+	return &JWTAuthenticator{
+		parser:      jwt.NewParser(opts...),
+		keyFunc:     keyFunc,
+		userIDClaim: cfg.UserIDClaim,
+		rolesClaim:  cfg.RolesClaim,
+		adminRole:   cfg.AdminRole,
+	}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	header := r.Header.Get("Authorization")
+	scheme, tokenString, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+	if tokenString == "" {
+		return nil, fmt.Errorf("%w: empty bearer token", ErrUnauthorized)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(tokenString, claims, a.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	principal := &Principal{Roles: claimStrings(claims[a.rolesClaim])}
+	principal.Subject, _ = claims.GetSubject()
+	for _, role := range principal.Roles {
+		if role == a.adminRole {
+			principal.Admin = true
+		}
+	}
+
+	rawUserID, _ := claims[a.userIDClaim].(string)
+	if rawUserID != "" {
+		userID, err := uuid.Parse(rawUserID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s claim", ErrUnauthorized, a.userIDClaim)
+		}
+		principal.UserID = userID
+	} else if !principal.Admin {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrUnauthorized, a.userIDClaim)
+	}
+
+	return principal, nil
+}
+
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func newKeyFunc(cfg config.AuthConfig) (jwt.Keyfunc, error) {
+	switch cfg.JWTAlgorithm {
+	case "HS256":
+		if cfg.JWTSecret == "" {
+			return nil, fmt.Errorf("JWT_SECRET is required for HS256")
+		}
+		secret := []byte(cfg.JWTSecret)
+		return func(*jwt.Token) (interface{}, error) { return secret, nil }, nil
+
+	case "RS256":
+		if cfg.JWKSFile != "" {
+			keys, err := loadJWKS(cfg.JWKSFile)
+			if err != nil {
+				return nil, err
+			}
+			return func(token *jwt.Token) (interface{}, error) {
+				kid, _ := token.Header["kid"].(string)
+				if key, ok := keys[kid]; ok {
+					return key, nil
+				}
+				if kid == "" && len(keys) == 1 {
+					for _, key := range keys {
+						return key, nil
+					}
+				}
+				return nil, fmt.Errorf("unknown key id %q", kid)
+			}, nil
+		}
+
+		if cfg.JWTPublicKeyFile == "" {
+			return nil, fmt.Errorf("JWT_PUBLIC_KEY_FILE or JWT_JWKS_FILE is required for RS256")
+		}
+		data, err := os.ReadFile(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		return func(*jwt.Token) (interface{}, error) { return key, nil }, nil
+	}
+
+	return nil, fmt.Errorf("unsupported JWT algorithm: %s", cfg.JWTAlgorithm)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func loadJWKS(filename string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus for key %q: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent for key %q: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %s contains no RSA signing keys", filename)
+	}
+	return keys, nil
+}
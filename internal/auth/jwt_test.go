@@ -0,0 +1,387 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testSecret = "test-secret"
+
+func testAuthConfig() config.AuthConfig {
+	return config.AuthConfig{
+		Enabled:      true,
+		JWTAlgorithm: "HS256",
+		JWTSecret:    testSecret,
+		JWTIssuer:    "https://issuer.example",
+		JWTAudience:  "subscription-service",
+		UserIDClaim:  "user_id",
+		RolesClaim:   "roles",
+		AdminRole:    "admin",
+	}
+}
+
+func validClaims(userID uuid.UUID) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":     "user",
+		"user_id": userID.String(),
+		"iss":     "https://issuer.example",
+		"aud":     "subscription-service",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func signHS256(t *testing.T, claims jwt.MapClaims, secret string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func signRS256(t *testing.T, claims jwt.MapClaims, key *rsa.PrivateKey, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func generateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	return key
+}
+
+func writePublicKeyPEM(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "public.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write public key: %v", err)
+	}
+	return path
+}
+
+func writeJWKS(t *testing.T, keys map[string]*rsa.PrivateKey) string {
+	t.Helper()
+	type jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to marshal JWKS: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+	return path
+}
+
+func bearerRequest(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions", nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+// unsignedToken собирает токен с alg=none без подписи
+func unsignedToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("failed to build unsigned token: %v", err)
+	}
+	return token
+}
+
+func TestJWTAuthenticatorHS256(t *testing.T) {
+	userID := uuid.New()
+	rsaKey := generateRSAKey(t)
+
+	authenticator, err := auth.NewJWTAuthenticator(testAuthConfig())
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator: %v", err)
+	}
+
+	with := func(mutate func(jwt.MapClaims)) jwt.MapClaims {
+		claims := validClaims(userID)
+		mutate(claims)
+		return claims
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		wantErr   error
+		wantAdmin bool
+		wantUser  uuid.UUID
+	}{
+		{
+			name:     "valid token",
+			token:    signHS256(t, validClaims(userID), testSecret),
+			wantUser: userID,
+		},
+		{
+			name:    "wrong secret",
+			token:   signHS256(t, validClaims(userID), "other-secret"),
+			wantErr: auth.ErrUnauthorized,
+		},
+		{
+			name:    "algorithm switched to RS256",
+			token:   signRS256(t, validClaims(userID), rsaKey, ""),
+			wantErr: auth.ErrUnauthorized,
+		},
+		{
+			name:    "alg none",
+			token:   unsignedToken(t, validClaims(userID)),
+			wantErr: auth.ErrUnauthorized,
+		},
+		{
+			name:    "expired",
+			token:   signHS256(t, with(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }), testSecret),
+			wantErr: auth.ErrUnauthorized,
+		},
+		{
+			name:    "missing exp",
+			token:   signHS256(t, with(func(c jwt.MapClaims) { delete(c, "exp") }), testSecret),
+			wantErr: auth.ErrUnauthorized,
+		},
+		{
+			name:    "issuer mismatch",
+			token:   signHS256(t, with(func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }), testSecret),
+			wantErr: auth.ErrUnauthorized,
+		},
+		{
+			name:    "audience mismatch",
+			token:   signHS256(t, with(func(c jwt.MapClaims) { c["aud"] = "other-service" }), testSecret),
+			wantErr: auth.ErrUnauthorized,
+		},
+		{
+			name:    "missing user_id",
+			token:   signHS256(t, with(func(c jwt.MapClaims) { delete(c, "user_id") }), testSecret),
+			wantErr: auth.ErrUnauthorized,
+		},
+		{
+			name:    "invalid user_id",
+			token:   signHS256(t, with(func(c jwt.MapClaims) { c["user_id"] = "not-a-uuid" }), testSecret),
+			wantErr: auth.ErrUnauthorized,
+		},
+		{
+			name: "admin without user_id",
+			token: signHS256(t, with(func(c jwt.MapClaims) {
+				delete(c, "user_id")
+				c["roles"] = []string{"admin"}
+			}), testSecret),
+			wantAdmin: true,
+		},
+		{
+			name:      "roles as space separated string",
+			token:     signHS256(t, with(func(c jwt.MapClaims) { c["roles"] = "viewer admin" }), testSecret),
+			wantAdmin: true,
+			wantUser:  userID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(bearerRequest(tt.token))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got principal %+v and error %v", tt.wantErr, principal, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if principal.Admin != tt.wantAdmin {
+				t.Errorf("Admin = %v, want %v", principal.Admin, tt.wantAdmin)
+			}
+			if principal.UserID != tt.wantUser {
+				t.Errorf("UserID = %s, want %s", principal.UserID, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticatorNoCredentials(t *testing.T) {
+	authenticator, err := auth.NewJWTAuthenticator(testAuthConfig())
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator: %v", err)
+	}
+
+	for _, header := range []string{"", "Basic dXNlcjpwYXNz", "Token abc"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		if _, err := authenticator.Authenticate(r); !errors.Is(err, auth.ErrNoCredentials) {
+			t.Errorf("header %q: expected ErrNoCredentials, got %v", header, err)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer ")
+	if _, err := authenticator.Authenticate(r); !errors.Is(err, auth.ErrUnauthorized) {
+		t.Errorf("empty bearer token: expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestJWTAuthenticatorRS256(t *testing.T) {
+	userID := uuid.New()
+	key := generateRSAKey(t)
+	otherKey := generateRSAKey(t)
+
+	cfg := testAuthConfig()
+	cfg.JWTAlgorithm = "RS256"
+	cfg.JWTSecret = ""
+	cfg.JWTPublicKeyFile = writePublicKeyPEM(t, key)
+	publicPEM, err := os.ReadFile(cfg.JWTPublicKeyFile)
+	if err != nil {
+		t.Fatalf("failed to read public key: %v", err)
+	}
+
+	authenticator, err := auth.NewJWTAuthenticator(cfg)
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "valid token", token: signRS256(t, validClaims(userID), key, "")},
+		{name: "signed by another key", token: signRS256(t, validClaims(userID), otherKey, ""), wantErr: true},
+		// HS256 с публичным ключом в качестве секрета — классическая подмена алгоритма
+		{name: "algorithm switched to HS256", token: signHS256(t, validClaims(userID), string(publicPEM)), wantErr: true},
+		{name: "alg none", token: unsignedToken(t, validClaims(userID)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(bearerRequest(tt.token))
+			if tt.wantErr {
+				if !errors.Is(err, auth.ErrUnauthorized) {
+					t.Fatalf("expected ErrUnauthorized, got principal %+v and error %v", principal, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if principal.UserID != userID {
+				t.Errorf("UserID = %s, want %s", principal.UserID, userID)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticatorJWKS(t *testing.T) {
+	userID := uuid.New()
+	first := generateRSAKey(t)
+	second := generateRSAKey(t)
+
+	cfg := testAuthConfig()
+	cfg.JWTAlgorithm = "RS256"
+	cfg.JWTSecret = ""
+	cfg.JWKSFile = writeJWKS(t, map[string]*rsa.PrivateKey{"first": first, "second": second})
+
+	authenticator, err := auth.NewJWTAuthenticator(cfg)
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "first kid", token: signRS256(t, validClaims(userID), first, "first")},
+		{name: "second kid", token: signRS256(t, validClaims(userID), second, "second")},
+		{name: "kid of another key", token: signRS256(t, validClaims(userID), first, "second"), wantErr: true},
+		{name: "unknown kid", token: signRS256(t, validClaims(userID), first, "third"), wantErr: true},
+		{name: "missing kid with several keys", token: signRS256(t, validClaims(userID), first, ""), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(bearerRequest(tt.token))
+			if tt.wantErr && !errors.Is(err, auth.ErrUnauthorized) {
+				t.Fatalf("expected ErrUnauthorized, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewJWTAuthenticatorConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*config.AuthConfig)
+	}{
+		{name: "HS256 without secret", mutate: func(c *config.AuthConfig) { c.JWTSecret = "" }},
+		{name: "RS256 without key", mutate: func(c *config.AuthConfig) { c.JWTAlgorithm = "RS256" }},
+		{name: "unsupported algorithm", mutate: func(c *config.AuthConfig) { c.JWTAlgorithm = "none" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testAuthConfig()
+			tt.mutate(&cfg)
+			if _, err := auth.NewJWTAuthenticator(cfg); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Chain пробует аутентификаторы по очереди и переходит к следующему, только если
+// предыдущий не нашел в запросе своих учетных данных.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+func Middleware(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				log.Printf("Authentication failed: %v", err)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="subscription-service"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireScope пропускает запрос, только если клиенту разрешена область доступа.
+// Должен располагаться после Middleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return require(func(p *Principal) bool { return p.HasScope(scope) }, "missing scope "+scope)
+}
+
+// RequireAdmin пропускает только пользователей с ролью администратора
+func RequireAdmin() func(http.Handler) http.Handler {
+	return require(func(p *Principal) bool { return p.Admin }, "admin role required")
+}
+
+func require(allowed func(*Principal) bool, message string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				log.Printf("Access denied for %s %s: no authenticated principal", r.Method, r.URL.Path)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="subscription-service"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if !allowed(principal) {
+				log.Printf("Access denied for %s %s: %s", r.Method, r.URL.Path, message)
+				http.Error(w, "Forbidden: "+message, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/google/uuid"
+)
+
+type staticAuthenticator struct {
+	principal *auth.Principal
+	err       error
+}
+
+func (a staticAuthenticator) Authenticate(*http.Request) (*auth.Principal, error) {
+	return a.principal, a.err
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func serve(handler http.Handler, principal *auth.Principal) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if principal != nil {
+		r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestRequireScopeAndAdmin(t *testing.T) {
+	user := &auth.Principal{UserID: uuid.New()}
+	admin := &auth.Principal{Admin: true}
+	readKey := &auth.Principal{UserID: uuid.New(), APIKeyID: 1, Scopes: []string{auth.ScopeSubscriptionsRead}}
+
+	tests := []struct {
+		name       string
+		middleware func(http.Handler) http.Handler
+		principal  *auth.Principal
+		wantStatus int
+	}{
+		{name: "scope without principal", middleware: auth.RequireScope(auth.ScopeSubscriptionsRead), wantStatus: http.StatusUnauthorized},
+		{name: "scope granted to key", middleware: auth.RequireScope(auth.ScopeSubscriptionsRead), principal: readKey, wantStatus: http.StatusOK},
+		{name: "scope missing on key", middleware: auth.RequireScope(auth.ScopeSubscriptionsWrite), principal: readKey, wantStatus: http.StatusForbidden},
+		{name: "scope not applied to JWT user", middleware: auth.RequireScope(auth.ScopeReportsRead), principal: user, wantStatus: http.StatusOK},
+		{name: "scope for admin", middleware: auth.RequireScope(auth.ScopeUsersWrite), principal: admin, wantStatus: http.StatusOK},
+		{name: "admin without principal", middleware: auth.RequireAdmin(), wantStatus: http.StatusUnauthorized},
+		{name: "admin for user", middleware: auth.RequireAdmin(), principal: user, wantStatus: http.StatusForbidden},
+		{name: "admin for API key", middleware: auth.RequireAdmin(), principal: readKey, wantStatus: http.StatusForbidden},
+		{name: "admin for admin", middleware: auth.RequireAdmin(), principal: admin, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(tt.middleware(okHandler()), tt.principal)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	principal := &auth.Principal{UserID: uuid.New()}
+
+	tests := []struct {
+		name          string
+		authenticator auth.Authenticator
+		wantStatus    int
+	}{
+		{name: "authenticated", authenticator: staticAuthenticator{principal: principal}, wantStatus: http.StatusOK},
+		{name: "no credentials", authenticator: staticAuthenticator{err: auth.ErrNoCredentials}, wantStatus: http.StatusUnauthorized},
+		{name: "invalid credentials", authenticator: staticAuthenticator{err: auth.ErrUnauthorized}, wantStatus: http.StatusUnauthorized},
+		{
+			name: "chain falls through to next authenticator",
+			authenticator: auth.Chain{
+				staticAuthenticator{err: auth.ErrNoCredentials},
+				staticAuthenticator{principal: principal},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "chain stops on invalid credentials",
+			authenticator: auth.Chain{
+				staticAuthenticator{err: auth.ErrUnauthorized},
+				staticAuthenticator{principal: principal},
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *auth.Principal
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = auth.PrincipalFromContext(r.Context())
+			})
+			w := serve(auth.Middleware(tt.authenticator)(next), nil)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && got != principal {
+				t.Errorf("principal not stored in context: %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrForbidden     = errors.New("forbidden")
+	ErrNoCredentials = errors.New("no credentials provided")
+)
+
+const (
+	ScopeSubscriptionsRead  = "subscriptions:read"
+	ScopeSubscriptionsWrite = "subscriptions:write"
+	ScopeReportsRead        = "reports:read"
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+)
+
+// Scopes перечисляет все области доступа, которые можно выдать ключу API
+var Scopes = []string{ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeReportsRead, ScopeUsersRead, ScopeUsersWrite}
+
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal описывает аутентифицированного клиента запроса: пользователя с JWT
+// или сервис с ключом API (APIKeyID != 0)
+type Principal struct {
+	Subject  string
+	UserID   uuid.UUID
+	Roles    []string
+	Admin    bool
+	APIKeyID int
+	Scopes   []string
+}
+
+// HasScope сообщает, разрешена ли клиенту область доступа. Области ограничивают
+// только ключи API: пользователи с JWT ограничены собственными данными.
+func (p *Principal) HasScope(scope string) bool {
+	if p.Admin || p.APIKeyID == 0 {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// UserScope возвращает пользователя, данными которого ограничен вызывающий.
+// Второе значение false означает, что ограничения нет: запрос без аутентификации
+// (внутренние вызовы), администратор или сервис с ключом API.
+func UserScope(ctx context.Context) (uuid.UUID, bool) {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || p.Admin || p.APIKeyID != 0 {
+		return uuid.Nil, false
+	}
+	return p.UserID, true
+}
+
+// Actor возвращает идентификатор инициатора действия для журналов аудита
+func Actor(ctx context.Context) string {
+	p, ok := PrincipalFromContext(ctx)
+	switch {
+	case !ok:
+		return "anonymous"
+	case p.APIKeyID != 0:
+		return p.Subject
+	case p.UserID != uuid.Nil:
+		return "user:" + p.UserID.String()
+	case p.Subject != "":
+		return "subject:" + p.Subject
+	}
+	return "unknown"
+}
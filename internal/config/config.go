@@ -1,91 +1,445 @@
 package config
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"math"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// Каждое поле конфигурации задается ключом в файле (тег yaml), переменной
+// окружения (тег env) и флагом командной строки с именем полного ключа,
+// например --database.pool.max_open_conns. Поля с тегом secret скрываются
+// при выводе командой config print и могут читаться из файла по NAME_FILE.
+
+// DatabaseConfig описывает подключение к базе данных. Если задан DSN (DB_DSN
+// или DATABASE_URL), драйвер выбирается по его схеме: sqlite://путь/к/файлу.db
+// или postgres://..., а строка без схемы считается строкой PostgreSQL в формате
+// ключ=значение. Отдельные параметры подключения при этом не используются.
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	DSN      string     `yaml:"dsn" env:"DB_DSN,DATABASE_URL" secret:"url"`
+	Host     string     `yaml:"host" env:"DB_HOST"`
+	Port     string     `yaml:"port" env:"DB_PORT"`
+	User     string     `yaml:"user" env:"DB_USER"`
+	Password string     `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	DBName   string     `yaml:"name" env:"DB_NAME"`
+	SSLMode  string     `yaml:"sslmode" env:"DB_SSLMODE"`
+	Pool     PoolConfig `yaml:"pool"`
+	// AutoMigrate применяет миграции при старте API; при false схемой
+	// управляют вручную командой cmd/migrate
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+	// MigrationTimeout ограничивает ожидание блокировки миграций или нужной версии схемы
+	MigrationTimeout time.Duration `yaml:"migration_timeout" env:"DB_MIGRATION_TIMEOUT"`
+}
+
+// PoolConfig задает размер пула соединений и ожидание базы при старте.
+// ConnectTimeout — сколько всего ждать доступности базы, повторяя попытки
+// с экспоненциальной задержкой; нулевой StatsInterval отключает журнал статистики.
+type PoolConfig struct {
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	StatsInterval   time.Duration `yaml:"stats_interval" env:"DB_POOL_STATS_INTERVAL"`
 }
 
+// ServerConfig задает адрес, на котором слушает API, и таймауты HTTP-сервера.
+// Пустой Host — все интерфейсы; нулевой таймаут отключает ограничение.
 type ServerConfig struct {
-	Host string
-	Port string
+	Host              string        `yaml:"host" env:"SERVER_HOST"`
+	Port              string        `yaml:"port" env:"SERVER_PORT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+	// MaxBodyBytes ограничивает размер тела JSON-запроса
+	MaxBodyBytes int64 `yaml:"max_body_bytes" env:"SERVER_MAX_BODY_BYTES"`
+	// TLSCertFile и TLSKeyFile включают HTTPS; по SIGHUP сертификат перечитывается
+	TLSCertFile string `yaml:"tls_cert_file" env:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile  string `yaml:"tls_key_file" env:"SERVER_TLS_KEY_FILE"`
+	// H2C разрешает HTTP/2 без TLS, например за балансировщиком с HTTP/2 до бэкенда
+	H2C bool `yaml:"h2c" env:"SERVER_H2C"`
 }
 
-type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+// TLSEnabled сообщает, настроен ли HTTPS
+func (c *ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != ""
+}
+
+type AuthConfig struct {
+	Enabled          bool   `yaml:"enabled" env:"AUTH_ENABLED"`
+	JWTAlgorithm     string `yaml:"jwt_algorithm" env:"JWT_ALGORITHM"`
+	JWTSecret        string `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	JWTPublicKeyFile string `yaml:"jwt_public_key_file" env:"JWT_PUBLIC_KEY_FILE"`
+	JWKSFile         string `yaml:"jwks_file" env:"JWT_JWKS_FILE"`
+	JWTIssuer        string `yaml:"jwt_issuer" env:"JWT_ISSUER"`
+	JWTAudience      string `yaml:"jwt_audience" env:"JWT_AUDIENCE"`
+	UserIDClaim      string `yaml:"user_id_claim" env:"JWT_USER_ID_CLAIM"`
+	RolesClaim       string `yaml:"roles_claim" env:"JWT_ROLES_CLAIM"`
+	AdminRole        string `yaml:"admin_role" env:"JWT_ADMIN_ROLE"`
+	// Insecure разрешает запуск с отключенной аутентификацией, когда все подписки
+	// доступны без учетных данных; только для разработки
+	Insecure bool `yaml:"insecure" env:"AUTH_INSECURE"`
+}
+
+// RateLimit задает скорость пополнения (запросов в секунду) и емкость корзины токенов.
+// В файле, окружении и флагах записывается как "rate:burst", например "2:5".
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RouteLimits — лимиты отдельных маршрутов по шаблону ServeMux. В окружении
+// и флагах записываются списком "POST /a=1:5;GET /b=10:20", в файле — словарем.
+type RouteLimits map[string]RateLimit
+
+// Prefixes — список подсетей. В окружении и флагах записывается через запятую,
+// отдельный адрес означает подсеть из одного адреса.
+type Prefixes []netip.Prefix
+
+type RateLimitConfig struct {
+	Enabled    bool        `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Default    RateLimit   `yaml:"default" env:"RATE_LIMIT_DEFAULT"`
+	Routes     RouteLimits `yaml:"routes" env:"RATE_LIMIT_ROUTES"`
+	TrustProxy bool        `yaml:"trust_proxy" env:"RATE_LIMIT_TRUST_PROXY"`
+	// TrustedProxies перечисляет адреса промежуточных прокси перед сервисом. Клиентом
+	// считается самый правый адрес X-Forwarded-For, не входящий в этот список.
+	TrustedProxies Prefixes `yaml:"trusted_proxies" env:"RATE_LIMIT_TRUSTED_PROXIES"`
+	// PerIP ограничивает запросы с одного IP-адреса ко всем маршрутам вместе еще
+	// до аутентификации, поэтому неверные учетные данные не нагружают базу
+	PerIP RateLimit `yaml:"per_ip" env:"RATE_LIMIT_PER_IP"`
+}
+
+// RetentionConfig задает, сколько хранятся удаленные подписки до окончательной очистки.
+// Нулевой DeletedTTL отключает очистку.
+type RetentionConfig struct {
+	DeletedTTL time.Duration `yaml:"deleted_ttl" env:"RETENTION_DELETED_TTL"`
+	Interval   time.Duration `yaml:"interval" env:"RETENTION_INTERVAL"`
+}
+
+// RenewalConfig задает фоновое продление подписок с auto_renew: задача запускается
+// каждые Interval и продлевает подписки пачками по BatchSize. Нулевой Interval отключает продление.
+type RenewalConfig struct {
+	Interval  time.Duration `yaml:"interval" env:"RENEWAL_INTERVAL"`
+	BatchSize int           `yaml:"batch_size" env:"RENEWAL_BATCH_SIZE"`
+}
+
+// NotificationsConfig задает напоминания о продлении подписок с auto_renew: задача
+// запускается каждые Interval и напоминает за LeadTime до продления, если пользователь
+// не задал свой срок. Неудачная отправка повторяется, пока попыток меньше MaxAttempts.
+// Нулевой Interval отключает напоминания; с LogOnly они только пишутся в журнал.
+type NotificationsConfig struct {
+	Interval       time.Duration `yaml:"interval" env:"NOTIFICATIONS_INTERVAL"`
+	LeadTime       time.Duration `yaml:"lead_time" env:"NOTIFICATIONS_LEAD_TIME"`
+	MaxAttempts    int           `yaml:"max_attempts" env:"NOTIFICATIONS_MAX_ATTEMPTS"`
+	LogOnly        bool          `yaml:"log_only" env:"NOTIFICATIONS_LOG_ONLY"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout" env:"NOTIFICATIONS_WEBHOOK_TIMEOUT"`
+	SMTP           SMTPConfig    `yaml:"smtp"`
+}
+
+// SMTPConfig задает почтовый сервер для напоминаний. Без Host письма не отправляются,
+// а только пишутся в журнал.
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     string `yaml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
 }
 
-func LoadConfig() *Config {
+// WebhooksConfig задает доставку событий подписок на webhook: задача запускается
+// каждые Interval и отправляет до BatchSize событий, ожидая ответа не дольше Timeout.
+// Неудачная доставка повторяется через Backoff, удваивая задержку после каждой попытки
+// до MaxBackoff, пока попыток меньше MaxAttempts. Нулевой Interval отключает доставку.
+type WebhooksConfig struct {
+	Interval    time.Duration `yaml:"interval" env:"WEBHOOKS_INTERVAL"`
+	BatchSize   int           `yaml:"batch_size" env:"WEBHOOKS_BATCH_SIZE"`
+	Timeout     time.Duration `yaml:"timeout" env:"WEBHOOKS_TIMEOUT"`
+	MaxAttempts int           `yaml:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS"`
+	Backoff     time.Duration `yaml:"backoff" env:"WEBHOOKS_BACKOFF"`
+	MaxBackoff  time.Duration `yaml:"max_backoff" env:"WEBHOOKS_MAX_BACKOFF"`
+}
+
+// UsersConfig задает политику удаления пользователей: restrict запрещает удалять
+// пользователя с подписками, cascade удаляет его подписки вместе с ним
+type UsersConfig struct {
+	DeletePolicy string `yaml:"delete_policy" env:"USERS_DELETE_POLICY"`
+}
+
+const (
+	DeletePolicyRestrict = "restrict"
+	DeletePolicyCascade  = "cascade"
+)
+
+const (
+	StorageDatabase = "database"
+	StorageMemory   = "memory"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+const sqliteScheme = "sqlite://"
 
-	loadEnvFile("../../.env")
+type Config struct {
+	// Storage выбирает хранилище: database (драйвер определяется по Database.DSN)
+	// или memory (данные теряются при перезапуске)
+	Storage   string          `yaml:"storage" env:"STORAGE"`
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Auth      AuthConfig      `yaml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Retention RetentionConfig `yaml:"retention"`
+	Renewal   RenewalConfig   `yaml:"renewal"`
+	// Notifications задает напоминания о продлении подписок
+	Notifications NotificationsConfig `yaml:"notifications"`
+	// Webhooks задает доставку событий подписок внешним системам
+	Webhooks WebhooksConfig `yaml:"webhooks"`
+	Users    UsersConfig    `yaml:"users"`
+}
 
+// Default возвращает конфигурацию по умолчанию, поверх которой применяются
+// файл, переменные окружения и флаги
+func Default() *Config {
 	return &Config{
+		Storage: StorageDatabase,
 		Server: ServerConfig{
-			Host: getEnv("SERVER_HOST", "localhost"),
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:              "8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   10 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			MaxBodyBytes:      1 << 20,
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			DBName:   getEnv("DB_NAME", "subscription_service"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:             "localhost",
+			Port:             "5432",
+			User:             "postgres",
+			Password:         "password",
+			DBName:           "subscription_service",
+			SSLMode:          "disable",
+			AutoMigrate:      true,
+			MigrationTimeout: 2 * time.Minute,
+			Pool: PoolConfig{
+				MaxOpenConns:    25,
+				MaxIdleConns:    5,
+				ConnMaxLifetime: 30 * time.Minute,
+				ConnMaxIdleTime: 5 * time.Minute,
+				ConnectTimeout:  30 * time.Second,
+				StatsInterval:   5 * time.Minute,
+			},
+		},
+		Auth: AuthConfig{
+			Enabled:      true,
+			JWTAlgorithm: "HS256",
+			UserIDClaim:  "user_id",
+			RolesClaim:   "roles",
+			AdminRole:    "admin",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Default: RateLimit{Rate: 20, Burst: 40},
+			PerIP:   RateLimit{Rate: 50, Burst: 100},
+			Routes:  RouteLimits{"POST /subscriptions/total-cost": {Rate: 2, Burst: 5}},
+		},
+		Retention: RetentionConfig{
+			DeletedTTL: 30 * 24 * time.Hour,
+			Interval:   time.Hour,
+		},
+		Renewal: RenewalConfig{
+			Interval:  15 * time.Minute,
+			BatchSize: 100,
+		},
+		Notifications: NotificationsConfig{
+			Interval:       time.Hour,
+			LeadTime:       72 * time.Hour,
+			MaxAttempts:    3,
+			WebhookTimeout: 10 * time.Second,
+			SMTP: SMTPConfig{
+				Port: "587",
+			},
+		},
+		Webhooks: WebhooksConfig{
+			Interval:    5 * time.Second,
+			BatchSize:   100,
+			Timeout:     10 * time.Second,
+			MaxAttempts: 8,
+			Backoff:     30 * time.Second,
+			MaxBackoff:  time.Hour,
+		},
+		Users: UsersConfig{
+			DeletePolicy: DeletePolicyRestrict,
 		},
 	}
 }
 
+// Driver возвращает драйвер базы данных по схеме DSN; по умолчанию postgres
+func (c *DatabaseConfig) Driver() string {
+	if strings.HasPrefix(c.DSN, sqliteScheme) {
+		return DriverSQLite
+	}
+	return DriverPostgres
+}
+
+// SQLitePath возвращает путь к файлу базы SQLite из DSN
+func (c *DatabaseConfig) SQLitePath() string {
+	return strings.TrimPrefix(c.DSN, sqliteScheme)
+}
+
+// GetDBConnectionString возвращает строку подключения в формате ключ=значение.
+// Строка содержит пароль и не должна попадать в журнал.
 func (c *DatabaseConfig) GetDBConnectionString() string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
+	if c.DSN != "" {
+		return c.DSN
+	}
+
+	params := []struct{ key, value string }{
+		{"host", c.Host},
+		{"port", c.Port},
+		{"user", c.User},
+		{"password", c.Password},
+		{"dbname", c.DBName},
+		{"sslmode", c.SSLMode},
+	}
+
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		parts = append(parts, param.key+"="+quoteDSNValue(param.value))
+	}
+	return strings.Join(parts, " ")
+}
 
+// quoteDSNValue заключает значение в кавычки по правилам libpq, чтобы пробелы,
+// кавычки и обратные слэши в пароле не ломали строку подключения
+func quoteDSNValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
 }
 
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// GetMigrationConnectionString возвращает строку подключения в виде URL
+// с экранированными именем пользователя, паролем и именем базы. DSN в формате
+// ключ=значение переводится в URL, которого требует golang-migrate.
+func (c *DatabaseConfig) GetMigrationConnectionString() string {
+	if c.DSN != "" {
+		if isURLDSN(c.DSN) {
+			return c.DSN
+		}
+		// Неверный формат отклоняет Validate
+		if migrationURL, err := keyValueDSNToURL(c.DSN); err == nil {
+			return migrationURL
+		}
+		return c.DSN
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, c.Port),
+		Path:     "/" + c.DBName,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
 	}
-	return value
+	return u.String()
 }
 
-func loadEnvFile(filename string) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return
+// UnmarshalText разбирает лимит в формате "rate:burst". Без burst емкость
+// равна скорости, округленной вверх.
+func (l *RateLimit) UnmarshalText(text []byte) error {
+	rateStr, burstStr, hasBurst := strings.Cut(string(text), ":")
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+	if err != nil || rate < 0 {
+		return fmt.Errorf("invalid rate limit %q, expected \"rate:burst\" with non-negative rate", text)
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(line, "#") || len(line) == 0 {
+	burst := int(math.Max(1, math.Ceil(rate)))
+	if hasBurst {
+		burst, err = strconv.Atoi(strings.TrimSpace(burstStr))
+		if err != nil || burst < 1 {
+			return fmt.Errorf("invalid rate limit %q, burst must be a positive integer", text)
+		}
+	}
+
+	*l = RateLimit{Rate: rate, Burst: burst}
+	return nil
+}
+
+func (l RateLimit) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(l.Rate, 'g', -1, 64) + ":" + strconv.Itoa(l.Burst)), nil
+}
+
+// UnmarshalText разбирает список вида "POST /a=1:5;GET /b=10:20"
+func (r *RouteLimits) UnmarshalText(text []byte) error {
+	routes := make(RouteLimits)
+	for _, entry := range strings.Split(string(text), ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
 			continue
 		}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
+		route, limitStr, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid route rate limit %q, expected \"METHOD /path=rate:burst\"", entry)
+		}
+
+		var limit RateLimit
+		if err := limit.UnmarshalText([]byte(limitStr)); err != nil {
+			return err
+		}
+		routes[strings.TrimSpace(route)] = limit
+	}
 
-			if os.Getenv(key) == "" {
-				os.Setenv(key, value)
+	*r = routes
+	return nil
+}
+
+// UnmarshalText разбирает список вида "10.0.0.0/8,192.168.1.10"
+func (p *Prefixes) UnmarshalText(text []byte) error {
+	var prefixes Prefixes
+	for _, entry := range strings.Split(string(text), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return fmt.Errorf("invalid address %q, expected an IP address or CIDR subnet", entry)
 			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return fmt.Errorf("invalid subnet %q, expected an IP address or CIDR subnet", entry)
 		}
+		prefixes = append(prefixes, prefix.Masked())
 	}
+
+	*p = prefixes
+	return nil
 }
 
-func (c *DatabaseConfig) GetMigrationConnectionString() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		c.User, c.Password, c.Host, c.Port, c.DBName, c.SSLMode)
+// Contains сообщает, входит ли адрес в одну из подсетей
+func (p Prefixes) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
 }
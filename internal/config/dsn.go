@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// dsnParam — параметр строки подключения libpq в формате ключ=значение
+type dsnParam struct {
+	key, value string
+}
+
+// isURLDSN сообщает, записана ли строка подключения в виде URL, а не ключ=значение
+func isURLDSN(dsn string) bool {
+	return strings.Contains(dsn, "://")
+}
+
+// parseKeyValueDSN разбирает строку подключения libpq вида host=db password='p w'.
+// Значение в одинарных кавычках может содержать пробелы и экранированные \' и \\.
+// Ошибка не содержит саму строку, чтобы пароль не попал в журнал.
+func parseKeyValueDSN(dsn string) ([]dsnParam, error) {
+	var params []dsnParam
+	rest := strings.TrimSpace(dsn)
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("expected key=value pairs separated by spaces")
+		}
+		key := strings.TrimSpace(rest[:eq])
+		if key == "" || strings.ContainsAny(key, " \t'") {
+			return nil, fmt.Errorf("expected key=value pairs separated by spaces")
+		}
+		rest = strings.TrimLeft(rest[eq+1:], " \t")
+
+		var value strings.Builder
+		if strings.HasPrefix(rest, "'") {
+			i := 1
+			for ; i < len(rest) && rest[i] != '\''; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				value.WriteByte(rest[i])
+			}
+			if i >= len(rest) {
+				return nil, fmt.Errorf("unterminated quoted value of %s", key)
+			}
+			rest = rest[i+1:]
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			value.WriteString(rest[:end])
+			rest = rest[end:]
+		}
+
+		params = append(params, dsnParam{key: key, value: value.String()})
+		rest = strings.TrimLeft(rest, " \t")
+	}
+	return params, nil
+}
+
+// keyValueDSNToURL переводит строку подключения ключ=значение в URL postgres://,
+// которого требует golang-migrate. Путь к сокету в host передается параметром запроса.
+func keyValueDSNToURL(dsn string) (string, error) {
+	params, err := parseKeyValueDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+
+	u := url.URL{Scheme: "postgres", Path: "/"}
+	query := url.Values{}
+	var host, port, user, password string
+	var hasPassword bool
+	for _, param := range params {
+		switch param.key {
+		case "host":
+			host = param.value
+		case "port":
+			port = param.value
+		case "user":
+			user = param.value
+		case "password":
+			password, hasPassword = param.value, true
+		case "dbname":
+			u.Path = "/" + param.value
+		default:
+			query.Set(param.key, param.value)
+		}
+	}
+
+	if strings.HasPrefix(host, "/") {
+		query.Set("host", host)
+		host = ""
+	}
+	u.Host = host
+	if port != "" {
+		u.Host = host + ":" + port
+	}
+	if hasPassword {
+		u.User = url.UserPassword(user, password)
+	} else if user != "" {
+		u.User = url.User(user)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// redactDSN скрывает пароль в строке подключения: в учетных данных и параметре
+// password URL или в значении password строки ключ=значение
+func redactDSN(dsn string) string {
+	if isURLDSN(dsn) {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return redacted
+		}
+		// Redacted скрывает только пароль в учетных данных, параметр запроса
+		// заменяем тем же "xxxxx"
+		if query := u.Query(); query.Has("password") {
+			query.Set("password", "xxxxx")
+			u.RawQuery = query.Encode()
+		}
+		return u.Redacted()
+	}
+
+	params, err := parseKeyValueDSN(dsn)
+	if err != nil {
+		return redacted
+	}
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		if param.key == "password" {
+			param.value = redacted
+		}
+		parts = append(parts, param.key+"="+quoteDSNValue(param.value))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// dsnPasswords — пароли с символами, которые ломают строку подключения
+// без экранирования
+var dsnPasswords = []struct {
+	name, password string
+}{
+	{"space", "pass word"},
+	{"at", "p@ssw0rd"},
+	{"quote", "it's-secret"},
+	{"backslash", `back\slash`},
+	{"equals", "key=value"},
+	{"question", "why?not"},
+	{"hash", "hash#tag"},
+	{"percent", "100%sure"},
+	{"all", `s p@c'e\=?#%25`},
+}
+
+// assertNoPassword проверяет, что пароль не встречается в строке ни как есть,
+// ни в экранированном для URL виде
+func assertNoPassword(t *testing.T, s, password string) {
+	t.Helper()
+	for _, form := range []string{password, url.QueryEscape(password), url.PathEscape(password), quoteDSNValue(password)} {
+		if strings.Contains(s, form) {
+			t.Errorf("%q leaks password as %q", s, form)
+		}
+	}
+}
+
+func passwordParam(t *testing.T, dsn string) string {
+	t.Helper()
+	params, err := parseKeyValueDSN(dsn)
+	if err != nil {
+		t.Fatalf("parseKeyValueDSN(%q): %v", dsn, err)
+	}
+	for _, param := range params {
+		if param.key == "password" {
+			return param.value
+		}
+	}
+	t.Fatalf("no password in %q", dsn)
+	return ""
+}
+
+func urlPassword(t *testing.T, dsn string) string {
+	t.Helper()
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", dsn, err)
+	}
+	password, ok := u.User.Password()
+	if !ok {
+		t.Fatalf("no password in %q", dsn)
+	}
+	return password
+}
+
+func TestConnectionStringsFromFields(t *testing.T) {
+	for _, tt := range dsnPasswords {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &DatabaseConfig{Host: "db", Port: "5432", User: "app", Password: tt.password, DBName: "subs", SSLMode: "disable"}
+
+			if got := passwordParam(t, c.GetDBConnectionString()); got != tt.password {
+				t.Errorf("key=value password = %q, want %q", got, tt.password)
+			}
+
+			migrationURL := c.GetMigrationConnectionString()
+			if got := urlPassword(t, migrationURL); got != tt.password {
+				t.Errorf("URL password = %q, want %q", got, tt.password)
+			}
+			u, _ := url.Parse(migrationURL)
+			if u.Host != "db:5432" || u.Path != "/subs" || u.Query().Get("sslmode") != "disable" {
+				t.Errorf("URL = %q, want host db:5432, path /subs and sslmode=disable", migrationURL)
+			}
+		})
+	}
+}
+
+func TestConnectionStringsFromKeyValueDSN(t *testing.T) {
+	for _, tt := range dsnPasswords {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn := "host=db port=5432 user=app password=" + quoteDSNValue(tt.password) + " dbname=subs sslmode=disable"
+			c := &DatabaseConfig{DSN: dsn}
+
+			if got := c.GetDBConnectionString(); got != dsn {
+				t.Errorf("GetDBConnectionString() = %q, want DSN as is", got)
+			}
+			if got := passwordParam(t, dsn); got != tt.password {
+				t.Errorf("parsed password = %q, want %q", got, tt.password)
+			}
+
+			migrationURL := c.GetMigrationConnectionString()
+			if got := urlPassword(t, migrationURL); got != tt.password {
+				t.Errorf("URL password = %q, want %q", got, tt.password)
+			}
+			u, _ := url.Parse(migrationURL)
+			if u.User.Username() != "app" || u.Host != "db:5432" || u.Path != "/subs" || u.Query().Get("sslmode") != "disable" {
+				t.Errorf("URL = %q, want user app, host db:5432, path /subs and sslmode=disable", migrationURL)
+			}
+		})
+	}
+}
+
+func TestConnectionStringsFromURLDSN(t *testing.T) {
+	for _, tt := range dsnPasswords {
+		t.Run(tt.name, func(t *testing.T) {
+			u := url.URL{Scheme: "postgres", User: url.UserPassword("app", tt.password), Host: "db:5432", Path: "/subs"}
+			c := &DatabaseConfig{DSN: u.String()}
+
+			for _, dsn := range []string{c.GetDBConnectionString(), c.GetMigrationConnectionString()} {
+				if got := urlPassword(t, dsn); got != tt.password {
+					t.Errorf("password = %q, want %q", got, tt.password)
+				}
+			}
+		})
+	}
+}
+
+func TestParseKeyValueDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+		want []dsnParam
+	}{
+		{"plain", "host=db user=app", []dsnParam{{"host", "db"}, {"user", "app"}}},
+		{"spaces around", "  host = db   user=app ", []dsnParam{{"host", "db"}, {"user", "app"}}},
+		{"quoted", `password='p w\'s\\' dbname=subs`, []dsnParam{{"password", `p w's\`}, {"dbname", "subs"}}},
+		{"empty quoted", "password='' host=db", []dsnParam{{"password", ""}, {"host", "db"}}},
+		{"equals in value", "password=a=b", []dsnParam{{"password", "a=b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseKeyValueDSN(tt.dsn)
+			if err != nil {
+				t.Fatalf("parseKeyValueDSN: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("param %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseKeyValueDSNErrors(t *testing.T) {
+	for _, dsn := range []string{"host", "=db", "host=db password='secret", "it's=x"} {
+		_, err := parseKeyValueDSN(dsn)
+		if err == nil {
+			t.Errorf("parseKeyValueDSN(%q) succeeded, want error", dsn)
+			continue
+		}
+		if strings.Contains(err.Error(), "secret") {
+			t.Errorf("error %q leaks the DSN", err)
+		}
+	}
+}
+
+func TestKeyValueDSNToURLSocket(t *testing.T) {
+	got, err := keyValueDSNToURL("host=/var/run/postgresql user=app dbname=subs")
+	if err != nil {
+		t.Fatalf("keyValueDSNToURL: %v", err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", got, err)
+	}
+	if u.Host != "" || u.Query().Get("host") != "/var/run/postgresql" || u.User.Username() != "app" || u.Path != "/subs" {
+		t.Errorf("URL = %q, want socket path in host parameter", got)
+	}
+}
+
+func TestRedactDSN(t *testing.T) {
+	for _, tt := range dsnPasswords {
+		t.Run(tt.name, func(t *testing.T) {
+			forms := map[string]string{
+				"key=value": "host=pg.internal user=app password=" + quoteDSNValue(tt.password) + " dbname=subs",
+				"url": (&url.URL{Scheme: "postgres", User: url.UserPassword("app", tt.password),
+					Host: "pg.internal", Path: "/subs"}).String(),
+				"query": (&url.URL{Scheme: "postgres", Host: "pg.internal", Path: "/subs",
+					RawQuery: url.Values{"user": {"app"}, "password": {tt.password}}.Encode()}).String(),
+			}
+
+			for form, dsn := range forms {
+				got := redactDSN(dsn)
+				assertNoPassword(t, got, tt.password)
+				if !strings.Contains(got, "pg.internal") {
+					t.Errorf("%s: redactDSN(%q) = %q, want host kept", form, dsn, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactDSNInvalid(t *testing.T) {
+	for _, dsn := range []string{"host=db password='unterminated", "postgres://app:se%zzcret@db/subs"} {
+		if got := redactDSN(dsn); got != redacted {
+			t.Errorf("redactDSN(%q) = %q, want %q", dsn, got, redacted)
+		}
+	}
+}
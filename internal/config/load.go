@@ -0,0 +1,281 @@
+package config
+
+import (
+	"bufio"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	configFileEnv    = "CONFIG_FILE"
+	envFileEnv       = "ENV_FILE"
+	secretFileSuffix = "_FILE"
+)
+
+// ValidationError перечисляет все ошибочные ключи конфигурации
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load собирает конфигурацию в порядке возрастания приоритета: значения
+// по умолчанию, файл (--config или CONFIG_FILE), переменные окружения, флаги.
+// Переменные из .env (--env-file или ENV_FILE) не перекрывают уже заданные.
+// Возвращает аргументы, оставшиеся после флагов.
+func Load(name string, args []string) (*Config, []string, error) {
+	cfg := Default()
+	settings := fields(cfg)
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to YAML or JSON config file (env "+configFileEnv+")")
+	envFile := fs.String("env-file", "", "path to .env file, default .env (env "+envFileEnv+")")
+
+	overrides := make(map[string]string)
+	for _, setting := range settings {
+		key := setting.key
+		usage := "override " + key
+		if len(setting.envs) > 0 {
+			usage += " (env " + strings.Join(setting.envs, " or ") + ")"
+		}
+		override := func(value string) error {
+			overrides[key] = value
+			return nil
+		}
+		if setting.value.Kind() == reflect.Bool {
+			fs.BoolFunc(key, usage, override)
+		} else {
+			fs.Func(key, usage, override)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	loadEnvFile(firstNonEmpty(*envFile, os.Getenv(envFileEnv), ".env"))
+
+	var problems []string
+	if path := firstNonEmpty(*configFile, os.Getenv(configFileEnv)); path != "" {
+		problems = append(problems, loadFile(path, cfg)...)
+	}
+
+	for _, setting := range settings {
+		value, source, err := setting.lookupEnv()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", setting.key, err))
+			continue
+		}
+		if source == "" {
+			continue
+		}
+		if err := setting.set(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (env %s): %v", setting.key, source, err))
+		}
+	}
+
+	for _, setting := range settings {
+		if value, ok := overrides[setting.key]; ok {
+			if err := setting.set(value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s (flag --%s): %v", setting.key, setting.key, err))
+			}
+		}
+	}
+
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, nil, &ValidationError{Problems: problems}
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+// loadFile читает YAML или JSON (JSON — подмножество YAML). Неизвестные ключи
+// считаются ошибкой, чтобы опечатки не проходили незамеченными.
+func loadFile(path string, cfg *Config) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return []string{fmt.Sprintf("config file: %v", err)}
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(cfg)
+	var typeErr *yaml.TypeError
+	switch {
+	case err == nil || errors.Is(err, io.EOF):
+		return nil
+	case errors.As(err, &typeErr):
+		problems := make([]string, 0, len(typeErr.Errors))
+		for _, problem := range typeErr.Errors {
+			problems = append(problems, fmt.Sprintf("config file %s: %s", path, problem))
+		}
+		return problems
+	default:
+		return []string{fmt.Sprintf("config file %s: %v", path, err)}
+	}
+}
+
+// setting — одно поле конфигурации с ключом в файле и переменными окружения.
+// Переменные перечислены в теге env через запятую в порядке приоритета.
+type setting struct {
+	key    string
+	envs   []string
+	secret string
+	value  reflect.Value
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// fields перечисляет поля cfg в порядке объявления; значения ссылаются на cfg
+func fields(cfg *Config) []setting {
+	var settings []setting
+	collectFields(reflect.ValueOf(cfg).Elem(), "", &settings)
+	return settings
+}
+
+func collectFields(v reflect.Value, prefix string, settings *[]setting) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("yaml")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if field.Type.Kind() == reflect.Struct && !reflect.PointerTo(field.Type).Implements(textUnmarshalerType) {
+			collectFields(v.Field(i), key, settings)
+			continue
+		}
+
+		var envs []string
+		if env := field.Tag.Get("env"); env != "" {
+			envs = strings.Split(env, ",")
+		}
+
+		*settings = append(*settings, setting{
+			key:    key,
+			envs:   envs,
+			secret: field.Tag.Get("secret"),
+			value:  v.Field(i),
+		})
+	}
+}
+
+// lookupEnv возвращает значение из окружения и имя переменной, откуда оно взято.
+// Секреты можно передать файлом: переменная NAME_FILE содержит путь к файлу
+// со значением NAME, как принято для Docker secrets.
+func (s setting) lookupEnv() (value, source string, err error) {
+	for _, env := range s.envs {
+		value := os.Getenv(env)
+		if s.secret == "" {
+			if value != "" {
+				return value, env, nil
+			}
+			continue
+		}
+
+		path := os.Getenv(env + secretFileSuffix)
+		switch {
+		case value != "" && path != "":
+			return "", "", fmt.Errorf("both %s and %s%s are set, use only one", env, env, secretFileSuffix)
+		case value != "":
+			return value, env, nil
+		case path != "":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to read %s%s: %w", env, secretFileSuffix, err)
+			}
+			// Редакторы и echo добавляют перевод строки в конец файла
+			return strings.TrimRight(string(data), "\r\n"), env + secretFileSuffix, nil
+		}
+	}
+	return "", "", nil
+}
+
+func (s setting) set(value string) error {
+	if unmarshaler, ok := s.value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	switch s.value.Interface().(type) {
+	case time.Duration:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected a value like 30s or 5m", value)
+		}
+		s.value.SetInt(int64(duration))
+	case string:
+		s.value.SetString(value)
+	case bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, expected true or false", value)
+		}
+		s.value.SetBool(parsed)
+	case int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		s.value.SetInt(int64(parsed))
+	case int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		s.value.SetInt(parsed)
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func loadEnvFile(filename string) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || len(line) == 0 {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 {
+			key := strings.TrimSpace(parts[0])
+			value := strings.TrimSpace(parts[1])
+
+			if os.Getenv(key) == "" {
+				os.Setenv(key, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolateEnv очищает все переменные конфигурации, чтобы окружение, в котором
+// запущены тесты, не влияло на результат, и задает обязательный секрет JWT
+func isolateEnv(t *testing.T) {
+	t.Helper()
+	for _, setting := range fields(Default()) {
+		for _, env := range setting.envs {
+			t.Setenv(env, "")
+			t.Setenv(env+secretFileSuffix, "")
+		}
+	}
+	t.Setenv(configFileEnv, "")
+	t.Setenv(envFileEnv, filepath.Join(t.TempDir(), "missing.env"))
+	t.Setenv("JWT_SECRET", "test-secret")
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func validationProblems(t *testing.T, err error) []string {
+	t.Helper()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("err = %v, want *ValidationError", err)
+	}
+	return validationErr.Problems
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		file bool
+		env  bool
+		flag bool
+		want string
+	}{
+		{"default", false, false, false, "8080"},
+		{"file over default", true, false, false, "9000"},
+		{"env over file", true, true, false, "9100"},
+		{"flag over env", true, true, true, "9200"},
+		{"flag over default", false, false, true, "9200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateEnv(t)
+			var args []string
+			if tt.file {
+				args = append(args, "--config", writeFile(t, "config.yaml", "server:\n  port: \"9000\"\n"))
+			}
+			if tt.env {
+				t.Setenv("SERVER_PORT", "9100")
+			}
+			if tt.flag {
+				args = append(args, "--server.port", "9200")
+			}
+
+			cfg, _, err := Load("test", args)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Server.Port != tt.want {
+				t.Errorf("server.port = %q, want %q", cfg.Server.Port, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSources(t *testing.T) {
+	isolateEnv(t)
+	t.Setenv(configFileEnv, writeFile(t, "config.json", `{"server": {"read_timeout": "20s"}, "renewal": {"batch_size": 10}}`))
+	t.Setenv("DATABASE_URL", "postgres://app@fallback/subs")
+	t.Setenv("DB_DSN", "postgres://app@primary/subs")
+	t.Setenv(envFileEnv, writeFile(t, ".env", "# comment\nSERVER_HOST=127.0.0.1\nDB_SSLMODE=require\n"))
+	t.Setenv("DB_SSLMODE", "verify-full")
+
+	cfg, args, err := Load("test", []string{"--auth.insecure", "--renewal.batch_size=50", "migrate", "up"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.Server.ReadTimeout != 20*time.Second {
+		t.Errorf("server.read_timeout = %v, want 20s from JSON file", cfg.Server.ReadTimeout)
+	}
+	if cfg.Renewal.BatchSize != 50 {
+		t.Errorf("renewal.batch_size = %d, want 50 from flag", cfg.Renewal.BatchSize)
+	}
+	if cfg.Database.DSN != "postgres://app@primary/subs" {
+		t.Errorf("database.dsn = %q, want DB_DSN over DATABASE_URL", cfg.Database.DSN)
+	}
+	if cfg.Server.Host != "127.0.0.1" {
+		t.Errorf("server.host = %q, want 127.0.0.1 from .env", cfg.Server.Host)
+	}
+	if cfg.Database.SSLMode != "verify-full" {
+		t.Errorf("database.sslmode = %q, want environment over .env", cfg.Database.SSLMode)
+	}
+	if !cfg.Auth.Insecure {
+		t.Error("auth.insecure = false, want true from boolean flag")
+	}
+	if strings.Join(args, " ") != "migrate up" {
+		t.Errorf("args = %q, want [migrate up]", args)
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	t.Run("read from file", func(t *testing.T) {
+		isolateEnv(t)
+		t.Setenv("JWT_SECRET", "")
+		t.Setenv("JWT_SECRET_FILE", writeFile(t, "jwt", "from-file\r\n"))
+		t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db", "p@ss word\n"))
+
+		cfg, _, err := Load("test", nil)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Auth.JWTSecret != "from-file" {
+			t.Errorf("auth.jwt_secret = %q, want trailing newline trimmed", cfg.Auth.JWTSecret)
+		}
+		if cfg.Database.Password != "p@ss word" {
+			t.Errorf("database.password = %q, want %q", cfg.Database.Password, "p@ss word")
+		}
+	})
+
+	t.Run("ignored for plain settings", func(t *testing.T) {
+		isolateEnv(t)
+		t.Setenv("SERVER_PORT_FILE", writeFile(t, "port", "9000"))
+
+		cfg, _, err := Load("test", nil)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Server.Port != "8080" {
+			t.Errorf("server.port = %q, want default", cfg.Server.Port)
+		}
+	})
+
+	t.Run("both set", func(t *testing.T) {
+		isolateEnv(t)
+		t.Setenv("JWT_SECRET_FILE", writeFile(t, "jwt", "from-file"))
+
+		_, _, err := Load("test", nil)
+		problems := validationProblems(t, err)
+		if !strings.HasPrefix(problems[0], "auth.jwt_secret: both JWT_SECRET and JWT_SECRET_FILE") {
+			t.Errorf("problems = %q, want conflict between JWT_SECRET and JWT_SECRET_FILE", problems)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		isolateEnv(t)
+		t.Setenv("DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		_, _, err := Load("test", nil)
+		problems := validationProblems(t, err)
+		if len(problems) != 1 || !strings.HasPrefix(problems[0], "database.password: failed to read DB_PASSWORD_FILE") {
+			t.Errorf("problems = %q, want read error for DB_PASSWORD_FILE", problems)
+		}
+	})
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	isolateEnv(t)
+	path := writeFile(t, "config.yaml", "server:\n  prot: \"9000\"\nwebhook:\n  interval: 1s\n")
+
+	_, _, err := Load("test", []string{"--config", path})
+	problems := validationProblems(t, err)
+	joined := strings.Join(problems, "\n")
+	for _, key := range []string{"prot", "webhook"} {
+		if !strings.Contains(joined, "field "+key+" not found") {
+			t.Errorf("problems = %q, want unknown key %q reported", problems, key)
+		}
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	isolateEnv(t)
+	path := writeFile(t, "config.yaml", "storage: cloud\nserver:\n  max_header_bytes: lots\n")
+	t.Setenv("SERVER_READ_TIMEOUT", "soon")
+	t.Setenv("JWT_SECRET", "")
+
+	_, _, err := Load("test", []string{
+		"--config", path,
+		"--server.port", "0",
+		"--renewal.batch_size", "many",
+		"--users.delete_policy", "orphan",
+	})
+	problems := validationProblems(t, err)
+
+	wantKeys := []string{
+		"config.yaml: line 3: cannot unmarshal",
+		"server.read_timeout (env SERVER_READ_TIMEOUT)",
+		"renewal.batch_size (flag --renewal.batch_size)",
+		"storage:",
+		"server.port:",
+		"auth.jwt_secret:",
+		"users.delete_policy:",
+	}
+	joined := strings.Join(problems, "\n")
+	for _, key := range wantKeys {
+		if !strings.Contains(joined, key) {
+			t.Errorf("problems do not mention %q:\n%s", key, joined)
+		}
+	}
+	if !strings.Contains(err.Error(), "invalid configuration:\n  - ") {
+		t.Errorf("Error() = %q, want a list of problems", err)
+	}
+}
+
+func TestValidateDatabase(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *DatabaseConfig)
+		want   []string
+	}{
+		{"defaults", func(c *DatabaseConfig) {}, nil},
+		{"fields", func(c *DatabaseConfig) {
+			c.Host, c.Port, c.DBName, c.SSLMode = "", "99999", "", "sometimes"
+		}, []string{"database.host", "database.port", "database.name", "database.sslmode"}},
+		{"sqlite without path", func(c *DatabaseConfig) { c.DSN = "sqlite://" }, []string{"database.dsn"}},
+		{"unknown scheme", func(c *DatabaseConfig) { c.DSN = "mysql://app@db/subs" }, []string{"database.dsn"}},
+		{"bad key=value", func(c *DatabaseConfig) { c.DSN = "host=db password='open" }, []string{"database.dsn"}},
+		{"pool", func(c *DatabaseConfig) {
+			c.Pool.MaxOpenConns, c.Pool.MaxIdleConns, c.Pool.ConnectTimeout = 2, 5, -time.Second
+		}, []string{"database.pool.max_idle_conns", "database.pool.connect_timeout"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(&cfg.Database)
+
+			var keys []string
+			cfg.Database.validate(func(key, format string, args ...interface{}) {
+				keys = append(keys, key)
+			})
+			if strings.Join(keys, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("problems for %q, want %q", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateHidesDSN(t *testing.T) {
+	cfg := Default()
+	cfg.Database.DSN = "postgres://app:se%zzcret@db/subs"
+
+	cfg.Auth.JWTSecret = "test-secret"
+
+	problems := cfg.validate()
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], "database.dsn:") {
+		t.Fatalf("problems = %q, want invalid DSN", problems)
+	}
+	for _, problem := range problems {
+		if strings.Contains(problem, "zzcret") {
+			t.Errorf("problem %q leaks the DSN", problem)
+		}
+	}
+}
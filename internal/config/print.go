@@ -0,0 +1,35 @@
+package config
+
+import (
+	"io"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const redacted = "******"
+
+// Print выводит действующую конфигурацию в YAML. Значения секретов заменяются
+// на звездочки, в строке подключения скрывается пароль.
+func (c *Config) Print(w io.Writer) error {
+	clone := *c
+	for _, setting := range fields(&clone) {
+		value := setting.value.String()
+		if value == "" {
+			continue
+		}
+
+		switch setting.secret {
+		case "true":
+			setting.value.SetString(redacted)
+		case "url":
+			setting.value.SetString(redactDSN(value))
+		}
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&clone); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// validate возвращает описание каждой ошибки в виде "ключ: проблема"
+func (c *Config) validate() []string {
+	var problems []string
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	if c.Storage != StorageDatabase && c.Storage != StorageMemory {
+		add("storage", "must be %s or %s, got %q", StorageDatabase, StorageMemory, c.Storage)
+	}
+
+	if !validPort(c.Server.Port) {
+		add("server.port", "must be a port number between 1 and 65535, got %q", c.Server.Port)
+	}
+
+	c.Server.validate(add)
+
+	if c.Storage == StorageDatabase {
+		c.Database.validate(add)
+	}
+
+	if !c.Auth.Enabled && !c.Auth.Insecure {
+		add("auth.enabled", "authentication can only be disabled together with auth.insecure (AUTH_INSECURE=true) for development")
+	}
+	if c.Auth.Enabled {
+		switch c.Auth.JWTAlgorithm {
+		case "HS256":
+			if c.Auth.JWTSecret == "" {
+				add("auth.jwt_secret", "is required for HS256")
+			}
+		case "RS256":
+			if c.Auth.JWTPublicKeyFile == "" && c.Auth.JWKSFile == "" {
+				add("auth.jwt_public_key_file", "either jwt_public_key_file or jwks_file is required for RS256")
+			}
+		default:
+			add("auth.jwt_algorithm", "must be HS256 or RS256, got %q", c.Auth.JWTAlgorithm)
+		}
+		if c.Auth.UserIDClaim == "" {
+			add("auth.user_id_claim", "must not be empty")
+		}
+	}
+
+	for route := range c.RateLimit.Routes {
+		method, path, ok := strings.Cut(route, " ")
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			add("rate_limit.routes", "route %q must look like \"METHOD /path\"", route)
+		}
+	}
+
+	if c.Retention.DeletedTTL < 0 {
+		add("retention.deleted_ttl", "must not be negative")
+	}
+	if c.Retention.DeletedTTL > 0 && c.Retention.Interval <= 0 {
+		add("retention.interval", "must be positive when retention is enabled")
+	}
+
+	if c.Renewal.Interval < 0 {
+		add("renewal.interval", "must not be negative")
+	}
+	if c.Renewal.Interval > 0 && c.Renewal.BatchSize <= 0 {
+		add("renewal.batch_size", "must be positive when renewal is enabled")
+	}
+
+	if c.Notifications.Interval < 0 {
+		add("notifications.interval", "must not be negative")
+	}
+	if c.Notifications.Interval > 0 {
+		if c.Notifications.LeadTime <= 0 {
+			add("notifications.lead_time", "must be positive when notifications are enabled")
+		}
+		if c.Notifications.MaxAttempts <= 0 {
+			add("notifications.max_attempts", "must be positive when notifications are enabled")
+		}
+		if c.Notifications.WebhookTimeout <= 0 {
+			add("notifications.webhook_timeout", "must be positive when notifications are enabled")
+		}
+	}
+	if c.Notifications.SMTP.Host != "" {
+		if !validPort(c.Notifications.SMTP.Port) {
+			add("notifications.smtp.port", "must be a port number between 1 and 65535, got %q", c.Notifications.SMTP.Port)
+		}
+		if c.Notifications.SMTP.From == "" {
+			add("notifications.smtp.from", "is required when smtp.host is set")
+		}
+	}
+
+	if c.Webhooks.Interval < 0 {
+		add("webhooks.interval", "must not be negative")
+	}
+	if c.Webhooks.Interval > 0 {
+		if c.Webhooks.BatchSize <= 0 {
+			add("webhooks.batch_size", "must be positive when webhooks are enabled")
+		}
+		if c.Webhooks.Timeout <= 0 {
+			add("webhooks.timeout", "must be positive when webhooks are enabled")
+		}
+		if c.Webhooks.MaxAttempts <= 0 {
+			add("webhooks.max_attempts", "must be positive when webhooks are enabled")
+		}
+		if c.Webhooks.Backoff <= 0 {
+			add("webhooks.backoff", "must be positive when webhooks are enabled")
+		}
+		if c.Webhooks.MaxBackoff < c.Webhooks.Backoff {
+			add("webhooks.max_backoff", "must not be less than webhooks.backoff")
+		}
+	}
+
+	if c.Users.DeletePolicy != DeletePolicyRestrict && c.Users.DeletePolicy != DeletePolicyCascade {
+		add("users.delete_policy", "must be %s or %s, got %q", DeletePolicyRestrict, DeletePolicyCascade, c.Users.DeletePolicy)
+	}
+
+	return problems
+}
+
+func (c *ServerConfig) validate(add func(key, format string, args ...interface{})) {
+	for _, timeout := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"server.read_header_timeout", c.ReadHeaderTimeout},
+		{"server.read_timeout", c.ReadTimeout},
+		{"server.write_timeout", c.WriteTimeout},
+		{"server.idle_timeout", c.IdleTimeout},
+		{"server.shutdown_timeout", c.ShutdownTimeout},
+	} {
+		if timeout.value < 0 {
+			add(timeout.key, "must not be negative")
+		}
+	}
+	if c.MaxHeaderBytes < 0 {
+		add("server.max_header_bytes", "must not be negative")
+	}
+	if c.MaxBodyBytes <= 0 {
+		add("server.max_body_bytes", "must be positive")
+	}
+
+	if c.TLSEnabled() {
+		if c.TLSCertFile == "" {
+			add("server.tls_cert_file", "is required when tls_key_file is set")
+		}
+		if c.TLSKeyFile == "" {
+			add("server.tls_key_file", "is required when tls_cert_file is set")
+		}
+		if c.H2C {
+			add("server.h2c", "must not be enabled together with TLS, HTTP/2 is negotiated over TLS")
+		}
+	}
+}
+
+func (c *DatabaseConfig) validate(add func(key, format string, args ...interface{})) {
+	if c.DSN != "" {
+		if c.Driver() == DriverSQLite {
+			if c.SQLitePath() == "" {
+				add("database.dsn", "sqlite DSN must contain a file path, e.g. sqlite://./data.db")
+			}
+		} else if !isURLDSN(c.DSN) {
+			if _, err := parseKeyValueDSN(c.DSN); err != nil {
+				add("database.dsn", "is neither a URL nor a key=value connection string: %v", err)
+			}
+		} else if u, err := url.Parse(c.DSN); err != nil {
+			// Ошибка разбора содержит саму строку с паролем, поэтому не выводим ее
+			add("database.dsn", "is not a valid URL, check that the password is URL-encoded")
+		} else if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+			add("database.dsn", "must start with sqlite://, postgres:// or postgresql://")
+		}
+	} else {
+		if c.Host == "" {
+			add("database.host", "must not be empty")
+		}
+		if !validPort(c.Port) {
+			add("database.port", "must be a port number between 1 and 65535, got %q", c.Port)
+		}
+		if c.DBName == "" {
+			add("database.name", "must not be empty")
+		}
+		if !slices.Contains(sslModes, c.SSLMode) {
+			add("database.sslmode", "must be one of %s, got %q", strings.Join(sslModes, ", "), c.SSLMode)
+		}
+	}
+
+	if c.MigrationTimeout <= 0 {
+		add("database.migration_timeout", "must be positive")
+	}
+	if c.Pool.MaxOpenConns < 0 {
+		add("database.pool.max_open_conns", "must not be negative")
+	}
+	if c.Pool.MaxIdleConns < 0 {
+		add("database.pool.max_idle_conns", "must not be negative")
+	}
+	if c.Pool.MaxOpenConns > 0 && c.Pool.MaxIdleConns > c.Pool.MaxOpenConns {
+		add("database.pool.max_idle_conns", "must not exceed max_open_conns (%d)", c.Pool.MaxOpenConns)
+	}
+	for _, duration := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"database.pool.conn_max_lifetime", c.Pool.ConnMaxLifetime},
+		{"database.pool.conn_max_idle_time", c.Pool.ConnMaxIdleTime},
+		{"database.pool.connect_timeout", c.Pool.ConnectTimeout},
+		{"database.pool.stats_interval", c.Pool.StatsInterval},
+	} {
+		if duration.value < 0 {
+			add(duration.key, "must not be negative")
+		}
+	}
+}
+
+func validPort(port string) bool {
+	value, err := strconv.Atoi(port)
+	return err == nil && value > 0 && value <= 65535
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/service"
+)
+
+type APIKeyHandler struct {
+	service service.APIKeyService
+	guard   Guard
+}
+
+func NewAPIKeyHandler(service service.APIKeyService, guard Guard) *APIKeyHandler {
+	return &APIKeyHandler{service: service, guard: guard}
+}
+
+// CreateAPIKey godoc
+// @Summary Создать ключ API
+// @Description Создает ключ для межсервисных вызовов. Секрет возвращается только в этом ответе.
+// @Tags ключи API
+// @Accept json
+// @Produce json
+// @Param request body model.CreateAPIKeyRequest true "Название и области доступа ключа"
+// @Success 201 {object} model.APIKeyWithSecret "Созданный ключ"
+// @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Security BearerAuth
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Handling CreateAPIKey request")
+
+	var req model.CreateAPIKeyRequest
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(r.Context(), &req)
+	if err != nil {
+		log.Printf("Error creating API key: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(key); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// ListAPIKeys godoc
+// @Summary Список ключей API
+// @Description Возвращает все ключи API, включая отозванные, без секретов
+// @Tags ключи API
+// @Produce json
+// @Success 200 {array} model.APIKey "Список ключей"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Handling ListAPIKeys request")
+
+	keys, err := h.service.ListAPIKeys(r.Context())
+	if err != nil {
+		log.Printf("Error listing API keys: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(keys); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// RotateAPIKey godoc
+// @Summary Ротация ключа API
+// @Description Выпускает новый секрет для ключа; старый секрет сразу перестает действовать
+// @Tags ключи API
+// @Produce json
+// @Param id path string true "ID ключа"
+// @Success 200 {object} model.APIKeyWithSecret "Ключ с новым секретом"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 404 {string} string "Ключ не найден или отозван"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Security BearerAuth
+// @Router /admin/api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling RotateAPIKey request for ID: %s", id)
+
+	key, err := h.service.RotateAPIKey(r.Context(), id)
+	if err != nil {
+		log.Printf("Error rotating API key: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(key); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// RevokeAPIKey godoc
+// @Summary Отозвать ключ API
+// @Description Отзывает ключ; запросы с ним больше не принимаются
+// @Tags ключи API
+// @Param id path string true "ID ключа"
+// @Success 204 "Ключ отозван"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 404 {string} string "Ключ не найден или уже отозван"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Security BearerAuth
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling RevokeAPIKey request for ID: %s", id)
+
+	if err := h.service.RevokeAPIKey(r.Context(), id); err != nil {
+		log.Printf("Error revoking API key: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetupRoutes регистрирует маршруты управления ключами; они доступны только
+// администратору и закрыты, если аутентификация отключена
+func (h *APIKeyHandler) SetupRoutes(mux *http.ServeMux) {
+	h.guard.handleAdmin(mux, "POST /admin/api-keys", h.CreateAPIKey)
+	h.guard.handleAdmin(mux, "GET /admin/api-keys", h.ListAPIKeys)
+	h.guard.handleAdmin(mux, "POST /admin/api-keys/{id}/rotate", h.RotateAPIKey)
+	h.guard.handleAdmin(mux, "DELETE /admin/api-keys/{id}", h.RevokeAPIKey)
+}
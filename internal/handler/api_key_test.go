@@ -0,0 +1,69 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/handler"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/service"
+)
+
+func TestAPIKeyScopesPerRoute(t *testing.T) {
+	subscriptions := repository.NewMemorySubscriptionRepository()
+	users := repository.NewMemoryUserRepository(subscriptions)
+	apiKeys := service.NewAPIKeyService(repository.NewMemoryAPIKeyRepository())
+
+	guard := handler.Guard{Authenticator: auth.NewAPIKeyAuthenticator(apiKeys)}
+	mux := http.NewServeMux()
+	svc := service.NewSubscriptionService(subscriptions, repository.NewMemoryServiceCatalogRepository(subscriptions), users)
+	handler.NewSubscriptionHandler(svc, guard).SetupRoutes(mux)
+	handler.NewUserHandler(service.NewUserService(users, false), guard).SetupRoutes(mux)
+	handler.NewAPIKeyHandler(apiKeys, guard).SetupRoutes(mux)
+
+	readKey, err := apiKeys.CreateAPIKey(context.Background(), &model.CreateAPIKeyRequest{
+		Name:   "reader",
+		Scopes: []string{auth.ScopeSubscriptionsRead},
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		key        string
+		wantStatus int
+	}{
+		{name: "read route", method: http.MethodGet, target: "/subscriptions/list", key: readKey.Key, wantStatus: http.StatusOK},
+		{name: "write route", method: http.MethodPost, target: "/subscriptions", body: `{}`, key: readKey.Key, wantStatus: http.StatusForbidden},
+		{name: "reports route", method: http.MethodPost, target: "/subscriptions/total-cost", body: `{}`, key: readKey.Key, wantStatus: http.StatusForbidden},
+		{name: "users route", method: http.MethodPost, target: "/users", body: `{}`, key: readKey.Key, wantStatus: http.StatusForbidden},
+		{name: "admin route", method: http.MethodGet, target: "/admin/api-keys", key: readKey.Key, wantStatus: http.StatusForbidden},
+		{name: "unknown key", method: http.MethodGet, target: "/subscriptions/list", key: "sk_unknown", wantStatus: http.StatusUnauthorized},
+		{name: "no key", method: http.MethodGet, target: "/subscriptions/list", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.key != "" {
+				r.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
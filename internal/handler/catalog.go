@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/service"
+)
+
+type CatalogHandler struct {
+	service service.CatalogService
+	guard   Guard
+}
+
+func NewCatalogHandler(service service.CatalogService, guard Guard) *CatalogHandler {
+	return &CatalogHandler{service: service, guard: guard}
+}
+
+func writeService(w http.ResponseWriter, status int, svc *model.Service) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(svc); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// CreateService godoc
+// @Summary Добавить сервис в каталог
+// @Description Добавляет сервис с псевдонимами и тарифами. Подписки без сервиса, чье название совпадает с названием или псевдонимом, привязываются к нему.
+// @Tags каталог сервисов
+// @Accept json
+// @Produce json
+// @Param request body model.ServiceRequest true "Название, псевдонимы, категория и тарифы"
+// @Success 201 {object} model.Service "Созданный сервис"
+// @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 409 {string} string "Название или псевдоним занят другим сервисом"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Router /services [post]
+func (h *CatalogHandler) CreateService(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Handling CreateService request")
+
+	var req model.ServiceRequest
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
+		return
+	}
+
+	svc, err := h.service.CreateService(r.Context(), &req)
+	if err != nil {
+		log.Printf("Error creating service: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	writeService(w, http.StatusCreated, svc)
+}
+
+// ListServices godoc
+// @Summary Список сервисов каталога
+// @Description Возвращает все сервисы каталога с псевдонимами и тарифами
+// @Tags каталог сервисов
+// @Produce json
+// @Success 200 {array} model.Service "Сервисы каталога"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /services [get]
+func (h *CatalogHandler) ListServices(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Handling ListServices request")
+
+	services, err := h.service.ListServices(r.Context())
+	if err != nil {
+		log.Printf("Error listing services: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(services); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// GetService godoc
+// @Summary Получить сервис каталога
+// @Description Возвращает сервис каталога с псевдонимами и тарифами
+// @Tags каталог сервисов
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Success 200 {object} model.Service "Сервис"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 404 {string} string "Сервис не найден"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /services/{id} [get]
+func (h *CatalogHandler) GetService(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling GetService request for ID: %s", id)
+
+	svc, err := h.service.GetService(r.Context(), id)
+	if err != nil {
+		log.Printf("Error getting service: %v", err)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeService(w, http.StatusOK, svc)
+}
+
+// ResolveService godoc
+// @Summary Найти сервис по названию
+// @Description Сопоставляет свободное название с сервисом каталога по названию и псевдонимам без учета регистра
+// @Tags каталог сервисов
+// @Produce json
+// @Param name query string true "Название или псевдоним"
+// @Success 200 {object} model.Service "Найденный сервис"
+// @Failure 400 {string} string "Название обязательно"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 404 {string} string "Сервис не найден"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /services/resolve [get]
+func (h *CatalogHandler) ResolveService(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	log.Printf("Handling ResolveService request for name: %s", name)
+
+	svc, err := h.service.ResolveService(r.Context(), name)
+	if err != nil {
+		log.Printf("Error resolving service: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	writeService(w, http.StatusOK, svc)
+}
+
+// UpdateService godoc
+// @Summary Изменить сервис каталога
+// @Description Заменяет сервис целиком. Новое название переносится в привязанные подписки.
+// @Tags каталог сервисов
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Param request body model.ServiceRequest true "Название, псевдонимы, категория и тарифы"
+// @Success 200 {object} model.Service "Измененный сервис"
+// @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 404 {string} string "Сервис не найден"
+// @Failure 409 {string} string "Название или псевдоним занят другим сервисом"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Router /services/{id} [put]
+func (h *CatalogHandler) UpdateService(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling UpdateService request for ID: %s", id)
+
+	var req model.ServiceRequest
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
+		return
+	}
+
+	svc, err := h.service.UpdateService(r.Context(), id, &req)
+	if err != nil {
+		log.Printf("Error updating service: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	writeService(w, http.StatusOK, svc)
+}
+
+// DeleteService godoc
+// @Summary Удалить сервис из каталога
+// @Description Удаляет сервис, если на него не ссылается ни одна подписка, включая удаленные
+// @Tags каталог сервисов
+// @Param id path string true "ID сервиса"
+// @Success 204 "Сервис удален"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 404 {string} string "Сервис не найден"
+// @Failure 409 {string} string "Сервис используется подписками"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Router /services/{id} [delete]
+func (h *CatalogHandler) DeleteService(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling DeleteService request for ID: %s", id)
+
+	if err := h.service.DeleteService(r.Context(), id); err != nil {
+		log.Printf("Error deleting service: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetupRoutes регистрирует маршруты каталога. Читать каталог может любой клиент
+// с доступом к подпискам, изменять — только администратор.
+func (h *CatalogHandler) SetupRoutes(mux *http.ServeMux) {
+	h.guard.handle(mux, "POST /services", h.CreateService, auth.RequireAdmin())
+	h.guard.handle(mux, "GET /services", h.ListServices, auth.RequireScope(auth.ScopeSubscriptionsRead))
+	h.guard.handle(mux, "GET /services/resolve", h.ResolveService, auth.RequireScope(auth.ScopeSubscriptionsRead))
+	h.guard.handle(mux, "GET /services/{id}", h.GetService, auth.RequireScope(auth.ScopeSubscriptionsRead))
+	h.guard.handle(mux, "PUT /services/{id}", h.UpdateService, auth.RequireAdmin())
+	h.guard.handle(mux, "DELETE /services/{id}", h.DeleteService, auth.RequireAdmin())
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodyBytes ограничивает тело запроса, если лимит не задан в Guard
+const DefaultMaxBodyBytes = 1 << 20
+
+// decodeJSON читает тело запроса в dst. Тело должно иметь тип application/json,
+// не превышать maxBytes, содержать ровно один JSON-объект и только известные поля.
+// При ошибке ответ уже отправлен и возвращается false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}, maxBytes int64) bool {
+	if !isJSONContentType(r.Header.Get("Content-Type")) {
+		log.Printf("Rejected request body with Content-Type %q", r.Header.Get("Content-Type"))
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == nil {
+		// Второй объект или мусор после первого — ошибка клиента
+		if err = decoder.Decode(&struct{}{}); err == io.EOF {
+			return true
+		}
+		if err == nil {
+			err = errors.New("body must contain a single JSON object")
+		}
+	}
+
+	log.Printf("Error decoding request: %v", err)
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+		return false
+	}
+
+	http.Error(w, "Invalid request body: "+describeDecodeError(err), http.StatusBadRequest)
+	return false
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// describeDecodeError переводит ошибку encoding/json в понятное клиенту сообщение
+func describeDecodeError(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return "body must not be empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "malformed JSON"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed JSON at position %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return fmt.Sprintf("field %q must be %s", typeErr.Field, typeErr.Type)
+		}
+		return fmt.Sprintf("value must be %s", typeErr.Type)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	default:
+		return err.Error()
+	}
+}
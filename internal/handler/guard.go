@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/ratelimit"
+)
+
+// Guard описывает общую обвязку маршрутов: аутентификацию, ограничение частоты
+// запросов и проверки доступа. Пустое поле отключает соответствующий слой.
+type Guard struct {
+	Authenticator auth.Authenticator
+	Limiter       *ratelimit.Limiter
+	// MaxBodyBytes ограничивает размер тела запроса; ноль — DefaultMaxBodyBytes
+	MaxBodyBytes int64
+}
+
+// handle регистрирует маршрут, выполняя по порядку ограничение частоты по IP-адресу,
+// аутентификацию, ограничение частоты для клиента и проверки checks. Проверки
+// доступа имеют смысл только при включенной аутентификации.
+func (g Guard) handle(mux *http.ServeMux, pattern string, handlerFunc http.HandlerFunc, checks ...func(http.Handler) http.Handler) {
+	var handler http.Handler = handlerFunc
+
+	if g.Authenticator != nil {
+		for i := len(checks) - 1; i >= 0; i-- {
+			handler = checks[i](handler)
+		}
+	}
+	if g.Limiter != nil {
+		handler = g.Limiter.Middleware(pattern)(handler)
+	}
+	if g.Authenticator != nil {
+		handler = auth.Middleware(g.Authenticator)(handler)
+	}
+	if g.Limiter != nil {
+		handler = g.Limiter.IPMiddleware()(handler)
+	}
+
+	mux.Handle(pattern, handler)
+}
+
+// handleAdmin регистрирует маршрут администратора. Без аутентификации
+// администратора не определить, поэтому такой маршрут отвечает 403.
+func (g Guard) handleAdmin(mux *http.ServeMux, pattern string, handlerFunc http.HandlerFunc) {
+	if g.Authenticator == nil {
+		handlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			log.Printf("Access denied for %s %s: authentication is disabled", r.Method, r.URL.Path)
+			http.Error(w, "Forbidden: admin routes require authentication", http.StatusForbidden)
+		}
+	}
+	g.handle(mux, pattern, handlerFunc, auth.RequireAdmin())
+}
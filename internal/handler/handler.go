@@ -2,48 +2,75 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/service"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 type SubscriptionHandler struct {
 	service service.SubscriptionService
+	guard   Guard
 }
 
-func NewSubscriptionHandler(service service.SubscriptionService) *SubscriptionHandler {
-	return &SubscriptionHandler{service: service}
+func NewSubscriptionHandler(service service.SubscriptionService, guard Guard) *SubscriptionHandler {
+	return &SubscriptionHandler{service: service, guard: guard}
+}
+
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, repository.ErrInvalidID), errors.Is(err, service.ErrInvalidAPIKeyRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, auth.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, repository.ErrDiscountNotFound),
+		errors.Is(err, repository.ErrServiceNotFound), errors.Is(err, repository.ErrUserNotFound),
+		errors.Is(err, repository.ErrWebhookNotFound), errors.Is(err, repository.ErrAPIKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrServiceConflict), errors.Is(err, repository.ErrServiceInUse),
+		errors.Is(err, repository.ErrUserExists), errors.Is(err, repository.ErrUserInUse),
+		errors.Is(err, service.ErrInvalidTransition), errors.Is(err, repository.ErrNotDeleted):
+		return http.StatusConflict
+	}
+	return fallback
 }
 
 // CreateSubscription godoc
 // @Summary Создать новую подписку
-// @Description Создает новую подписку для пользователя с автоматическим расчетом даты окончания (+1 месяц)
+// @Description Создает новую подписку для пользователя с автоматическим расчетом даты окончания (+1 месяц). С trial_months подписка начинается с пробного периода по цене trial_price (может быть 0), а дата окончания сдвигается на его длину. Участники members делят стоимость с владельцем и видят подписку в своих списках. С auto_renew подписка продлевается на месяц каждый раз, когда наступает дата окончания.
 // @Tags подписки
 // @Accept json
 // @Produce json
 // @Param request body model.CreateSubscriptionRequest true "Данные для создания подписки"
 // @Success 201 {object} model.Subscription "Созданная подписка"
 // @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к подпискам другого пользователя"
 // @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Handling CreateSubscription request")
 
 	var req model.CreateSubscriptionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("Error decoding request: %v", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
 		return
 	}
 
-	subscription, err := h.service.CreateSubscription(&req)
+	subscription, err := h.service.CreateSubscription(r.Context(), &req)
 	if err != nil {
 		log.Printf("Error creating subscription: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -56,13 +83,18 @@ func (h *SubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.
 
 // GetSubscription godoc
 // @Summary Получить подписку по ID
-// @Description Возвращает детальную информацию о подписке по ее идентификатору
+// @Description Возвращает детальную информацию о подписке по ее идентификатору. Общая подписка доступна владельцу и участникам.
 // @Tags подписки
 // @Produce json
 // @Param id query string true "ID подписки"
 // @Success 200 {object} model.Subscription "Найденная подписка"
 // @Failure 400 {string} string "ID обязателен"
+// @Failure 401 {string} string "Требуется аутентификация"
 // @Failure 404 {string} string "Подписка не найдена"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions [get]
 func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
@@ -73,10 +105,10 @@ func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	subscription, err := h.service.GetSubscription(id)
+	subscription, err := h.service.GetSubscription(r.Context(), id)
 	if err != nil {
 		log.Printf("Error getting subscription: %v", err)
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -88,7 +120,7 @@ func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Req
 
 // UpdateSubscription godoc
 // @Summary Обновить подписку
-// @Description Обновляет данные существующей подписки. Все поля опциональны.
+// @Description Обновляет данные существующей подписки. Все поля опциональны. Изменять подписку может только владелец; members заменяет участников целиком.
 // @Tags подписки
 // @Accept json
 // @Produce json
@@ -96,7 +128,14 @@ func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Req
 // @Param request body model.UpdateSubscriptionRequest true "Данные для обновления подписки"
 // @Success 204 "Подписка успешно обновлена"
 // @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к подпискам другого пользователя"
 // @Failure 404 {string} string "Подписка не найдена"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions [put]
 func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
@@ -108,15 +147,13 @@ func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.
 	}
 
 	var req model.UpdateSubscriptionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("Error decoding request: %v", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
 		return
 	}
 
-	if err := h.service.UpdateSubscription(id, &req); err != nil {
+	if err := h.service.UpdateSubscription(r.Context(), id, &req); err != nil {
 		log.Printf("Error updating subscription: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -125,13 +162,19 @@ func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.
 
 // DeleteSubscription godoc
 // @Summary Удалить подписку
-// @Description Удаляет подписку по идентификатору
+// @Description Помечает подписку удаленной; ее можно восстановить, пока она не очищена по сроку хранения. Параметр permanent удаляет подписку сразу (только администратор).
 // @Tags подписки
 // @Produce json
 // @Param id query string true "ID подписки"
+// @Param permanent query bool false "Удалить безвозвратно" default(false)
 // @Success 204 "Подписка успешно удалена"
 // @Failure 400 {string} string "ID обязателен"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Безвозвратное удаление доступно только администратору"
 // @Failure 404 {string} string "Подписка не найдена"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions [delete]
 func (h *SubscriptionHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
@@ -142,9 +185,38 @@ func (h *SubscriptionHandler) DeleteSubscription(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if err := h.service.DeleteSubscription(id); err != nil {
+	permanent, _ := strconv.ParseBool(r.URL.Query().Get("permanent"))
+
+	if err := h.service.DeleteSubscription(r.Context(), id, permanent); err != nil {
 		log.Printf("Error deleting subscription: %v", err)
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreSubscription godoc
+// @Summary Восстановить удаленную подписку
+// @Description Снимает пометку об удалении с подписки, которая еще не очищена по сроку хранения
+// @Tags подписки
+// @Param id path string true "ID подписки"
+// @Success 204 "Подписка восстановлена"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 404 {string} string "Подписка не найдена"
+// @Failure 409 {string} string "Подписка не удалена"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/restore [post]
+func (h *SubscriptionHandler) RestoreSubscription(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling RestoreSubscription request for ID: %s", id)
+
+	if err := h.service.RestoreSubscription(r.Context(), id); err != nil {
+		log.Printf("Error restoring subscription: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -153,13 +225,19 @@ func (h *SubscriptionHandler) DeleteSubscription(w http.ResponseWriter, r *http.
 
 // ListSubscriptions godoc
 // @Summary Список подписок
-// @Description Возвращает пагинированный список всех подписок
+// @Description Возвращает пагинированный список подписок с необязательным отбором по категории и тегу, включая общие подписки, где пользователь участник
 // @Tags подписки
 // @Produce json
 // @Param limit query int false "Лимит (по умолчанию: 10, максимум: 100)" default(10)
 // @Param offset query int false "Смещение (по умолчанию: 0)" default(0)
+// @Param category query string false "Категория"
+// @Param tag query string false "Тег"
 // @Success 200 {array} model.Subscription "Список подписок"
+// @Failure 401 {string} string "Требуется аутентификация"
 // @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions/list [get]
 func (h *SubscriptionHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Handling ListSubscriptions request")
@@ -182,7 +260,12 @@ func (h *SubscriptionHandler) ListSubscriptions(w http.ResponseWriter, r *http.R
 		}
 	}
 
-	subscriptions, err := h.service.ListSubscriptions(limit, offset)
+	filter := model.SubscriptionFilter{
+		Category: r.URL.Query().Get("category"),
+		Tag:      r.URL.Query().Get("tag"),
+	}
+
+	subscriptions, err := h.service.ListSubscriptions(r.Context(), filter, limit, offset)
 	if err != nil {
 		log.Printf("Error listing subscriptions: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -197,29 +280,34 @@ func (h *SubscriptionHandler) ListSubscriptions(w http.ResponseWriter, r *http.R
 
 // CalculateTotalCost godoc
 // @Summary Расчет общей стоимости
-// @Description Рассчитывает общую стоимость подписок за указанный период с фильтрацией по пользователю и сервису
+// @Description Рассчитывает общую стоимость подписок за указанный период с фильтрацией по пользователю и сервису. Пробный период учитывается по trial_price, после него — обычная цена. Каждый месяц подписки учитывается в периоде, в котором начался, за вычетом действующих скидок; месяцы, начавшиеся во время приостановки или после отмены, не учитываются. С group_by сервис можно не указывать, а сумма дополнительно разбивается по категориям, тегам или месяцам со скидками каждой группы. perspective=member считает долю пользователя во всех подписках, где он владелец или участник, вместо полной стоимости оплачиваемых им подписок.
 // @Tags стоимость
 // @Accept json
 // @Produce json
 // @Param request body model.CalculateCostRequest true "Данные для расчета стоимости"
 // @Success 200 {object} model.CalculateCostResponse "Результат расчета стоимости"
 // @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к подпискам другого пользователя"
 // @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /subscriptions/total-cost [post]
 func (h *SubscriptionHandler) CalculateTotalCost(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Handling CalculateTotalCost request")
 
 	var req model.CalculateCostRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("Error decoding request: %v", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
 		return
 	}
 
-	result, err := h.service.CalculateTotalCost(&req)
+	result, err := h.service.CalculateTotalCost(r.Context(), &req)
 	if err != nil {
 		log.Printf("Error calculating total cost: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -229,12 +317,215 @@ func (h *SubscriptionHandler) CalculateTotalCost(w http.ResponseWriter, r *http.
 	}
 }
 
+// GetSubscriptionHistory godoc
+// @Summary История изменений подписки
+// @Description Возвращает журнал создания, изменений и удаления подписки с состоянием до и после каждого изменения
+// @Tags подписки
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {array} model.SubscriptionAuditEntry "Записи журнала в хронологическом порядке"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 404 {string} string "Подписка не найдена"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/history [get]
+func (h *SubscriptionHandler) GetSubscriptionHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling GetSubscriptionHistory request for ID: %s", id)
+
+	history, err := h.service.GetSubscriptionHistory(r.Context(), id)
+	if err != nil {
+		log.Printf("Error getting subscription history: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// AddDiscount godoc
+// @Summary Добавить скидку к подписке
+// @Description Добавляет скидку на оплачиваемые месяцы подписки: процент от цены, сумму или цену на время скидки. Скидка действует с start_period до end_period или первые cycles месяцев; месяцы считаются в часовом поясе владельца подписки.
+// @Tags подписки
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body model.DiscountRequest true "Вид, размер и срок действия скидки"
+// @Success 201 {object} model.Discount "Добавленная скидка"
+// @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 404 {string} string "Подписка не найдена"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/discounts [post]
+func (h *SubscriptionHandler) AddDiscount(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling AddDiscount request for ID: %s", id)
+
+	var req model.DiscountRequest
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
+		return
+	}
+
+	discount, err := h.service.AddDiscount(r.Context(), id, &req)
+	if err != nil {
+		log.Printf("Error adding discount: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(discount); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// RemoveDiscount godoc
+// @Summary Удалить скидку подписки
+// @Description Удаляет скидку; расчет стоимости за прошедшие месяцы тоже перестает ее учитывать
+// @Tags подписки
+// @Param id path string true "ID подписки"
+// @Param discount_id path string true "ID скидки"
+// @Success 204 "Скидка удалена"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 404 {string} string "Подписка или скидка не найдена"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/discounts/{discount_id} [delete]
+func (h *SubscriptionHandler) RemoveDiscount(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling RemoveDiscount request for ID: %s", id)
+
+	if err := h.service.RemoveDiscount(r.Context(), id, r.PathValue("discount_id")); err != nil {
+		log.Printf("Error removing discount: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PauseSubscription godoc
+// @Summary Приостановить подписку
+// @Description Приостанавливает активную подписку. Месяцы, начавшиеся во время приостановки, не оплачиваются. Подписку с назначенной отменой приостановить нельзя.
+// @Tags подписки
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} model.Subscription "Подписка после перехода"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 404 {string} string "Подписка не найдена"
+// @Failure 409 {string} string "Переход из текущего состояния недопустим"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/pause [post]
+func (h *SubscriptionHandler) PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling PauseSubscription request for ID: %s", id)
+
+	subscription, err := h.service.PauseSubscription(r.Context(), id)
+	if err != nil {
+		log.Printf("Error pausing subscription: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(subscription); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// ResumeSubscription godoc
+// @Summary Возобновить подписку
+// @Description Возобновляет приостановленную подписку; оплата продолжается с ближайшего расчетного месяца.
+// @Tags подписки
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} model.Subscription "Подписка после перехода"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 404 {string} string "Подписка не найдена"
+// @Failure 409 {string} string "Переход из текущего состояния недопустим"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/resume [post]
+func (h *SubscriptionHandler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling ResumeSubscription request for ID: %s", id)
+
+	subscription, err := h.service.ResumeSubscription(r.Context(), id)
+	if err != nil {
+		log.Printf("Error resuming subscription: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(subscription); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// CancelSubscription godoc
+// @Summary Отменить подписку
+// @Description Отменяет активную или приостановленную подписку сразу либо, с at_period_end, в конце текущего расчетного периода. Месяцы, начавшиеся после отмены, не оплачиваются.
+// @Tags подписки
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param at_period_end query bool false "Отменить в конце текущего расчетного периода" default(false)
+// @Success 200 {object} model.Subscription "Подписка после перехода"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 404 {string} string "Подписка не найдена"
+// @Failure 409 {string} string "Переход из текущего состояния недопустим"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling CancelSubscription request for ID: %s", id)
+
+	atPeriodEnd, _ := strconv.ParseBool(r.URL.Query().Get("at_period_end"))
+
+	subscription, err := h.service.CancelSubscription(r.Context(), id, atPeriodEnd)
+	if err != nil {
+		log.Printf("Error cancelling subscription: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(subscription); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
 func (h *SubscriptionHandler) SetupRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("POST /subscriptions", h.CreateSubscription)
-	mux.HandleFunc("GET /subscriptions", h.GetSubscription)
-	mux.HandleFunc("PUT /subscriptions", h.UpdateSubscription)
-	mux.HandleFunc("DELETE /subscriptions", h.DeleteSubscription)
-	mux.HandleFunc("GET /subscriptions/list", h.ListSubscriptions)
-	mux.HandleFunc("POST /subscriptions/total-cost", h.CalculateTotalCost)
+	h.guard.handle(mux, "POST /subscriptions", h.CreateSubscription, auth.RequireScope(auth.ScopeSubscriptionsWrite))
+	h.guard.handle(mux, "GET /subscriptions", h.GetSubscription, auth.RequireScope(auth.ScopeSubscriptionsRead))
+	h.guard.handle(mux, "PUT /subscriptions", h.UpdateSubscription, auth.RequireScope(auth.ScopeSubscriptionsWrite))
+	h.guard.handle(mux, "DELETE /subscriptions", h.DeleteSubscription, auth.RequireScope(auth.ScopeSubscriptionsWrite))
+	h.guard.handle(mux, "GET /subscriptions/list", h.ListSubscriptions, auth.RequireScope(auth.ScopeSubscriptionsRead))
+	h.guard.handle(mux, "POST /subscriptions/total-cost", h.CalculateTotalCost, auth.RequireScope(auth.ScopeReportsRead))
+	h.guard.handle(mux, "GET /subscriptions/{id}/history", h.GetSubscriptionHistory, auth.RequireScope(auth.ScopeSubscriptionsRead))
+	h.guard.handle(mux, "POST /subscriptions/{id}/restore", h.RestoreSubscription, auth.RequireScope(auth.ScopeSubscriptionsWrite))
+	h.guard.handle(mux, "POST /subscriptions/{id}/pause", h.PauseSubscription, auth.RequireScope(auth.ScopeSubscriptionsWrite))
+	h.guard.handle(mux, "POST /subscriptions/{id}/resume", h.ResumeSubscription, auth.RequireScope(auth.ScopeSubscriptionsWrite))
+	h.guard.handle(mux, "POST /subscriptions/{id}/cancel", h.CancelSubscription, auth.RequireScope(auth.ScopeSubscriptionsWrite))
+	h.guard.handle(mux, "POST /subscriptions/{id}/discounts", h.AddDiscount, auth.RequireScope(auth.ScopeSubscriptionsWrite))
+	h.guard.handle(mux, "DELETE /subscriptions/{id}/discounts/{discount_id}", h.RemoveDiscount, auth.RequireScope(auth.ScopeSubscriptionsWrite))
 	mux.Handle("/swagger/", httpSwagger.WrapHandler)
 }
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/service"
+)
+
+// HealthHandler отвечает на проверки оркестратора. Маршруты не требуют
+// аутентификации и не ограничиваются по частоте.
+type HealthHandler struct {
+	service service.HealthService
+}
+
+func NewHealthHandler(service service.HealthService) *HealthHandler {
+	return &HealthHandler{service: service}
+}
+
+// Live godoc
+// @Summary Проверка жизнеспособности
+// @Description Отвечает 200, пока процесс обрабатывает запросы
+// @Tags состояние
+// @Produce plain
+// @Success 200 {string} string "ok"
+// @Router /health/live [get]
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok"))
+}
+
+// Ready godoc
+// @Summary Проверка готовности
+// @Description Проверяет доступность базы данных и что версия схемы не ниже ожидаемой
+// @Tags состояние
+// @Produce json
+// @Success 200 {object} model.Readiness "Сервис готов"
+// @Failure 503 {object} model.Readiness "Сервис не готов"
+// @Router /health/ready [get]
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	writeReadiness(w, h.service.Readiness(r.Context()))
+}
+
+func writeReadiness(w http.ResponseWriter, readiness *model.Readiness) {
+	w.Header().Set("Content-Type", "application/json")
+	if readiness.Status != service.StatusReady {
+		log.Printf("Readiness check failed: %s", readiness.Error)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(readiness); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *HealthHandler) SetupRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /health/live", h.Live)
+	mux.HandleFunc("GET /health/ready", h.Ready)
+}
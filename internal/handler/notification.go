@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/service"
+)
+
+type NotificationHandler struct {
+	service service.NotificationService
+	guard   Guard
+}
+
+func NewNotificationHandler(service service.NotificationService, guard Guard) *NotificationHandler {
+	return &NotificationHandler{service: service, guard: guard}
+}
+
+func writePreferences(w http.ResponseWriter, prefs *model.NotificationPreferences) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(prefs); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// GetPreferences godoc
+// @Summary Получить настройки уведомлений
+// @Description Возвращает настройки напоминаний о продлении подписок. Пока пользователь их не менял, напоминания приходят на email профиля за срок по умолчанию.
+// @Tags уведомления
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} model.NotificationPreferences "Настройки уведомлений"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к профилю другого пользователя"
+// @Failure 404 {string} string "Пользователь не найден"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/notifications [get]
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling GetPreferences request for user: %s", id)
+
+	prefs, err := h.service.GetPreferences(r.Context(), id)
+	if err != nil {
+		log.Printf("Error getting notification preferences: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	writePreferences(w, prefs)
+}
+
+// UpdatePreferences godoc
+// @Summary Изменить настройки уведомлений
+// @Description Изменяет переданные настройки напоминаний. lead_days от 1 до 30 задает свой срок напоминания, 0 возвращает срок по умолчанию; пустой webhook_url отключает webhook.
+// @Tags уведомления
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param request body model.UpdateNotificationPreferencesRequest true "Изменяемые настройки"
+// @Success 200 {object} model.NotificationPreferences "Измененные настройки"
+// @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к профилю другого пользователя"
+// @Failure 404 {string} string "Пользователь не найден"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/notifications [put]
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling UpdatePreferences request for user: %s", id)
+
+	var req model.UpdateNotificationPreferencesRequest
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
+		return
+	}
+
+	prefs, err := h.service.UpdatePreferences(r.Context(), id, &req)
+	if err != nil {
+		log.Printf("Error updating notification preferences: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	writePreferences(w, prefs)
+}
+
+// ListDeliveries godoc
+// @Summary Отправленные напоминания
+// @Description Возвращает записи об отправке напоминаний пользователю, начиная с последних. Неудачная отправка повторяется, пока не исчерпано число попыток.
+// @Tags уведомления
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param limit query int false "Лимит (по умолчанию: 10, максимум: 100)" default(10)
+// @Param offset query int false "Смещение (по умолчанию: 0)" default(0)
+// @Success 200 {array} model.NotificationDelivery "Записи об отправке"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к профилю другого пользователя"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/notifications/deliveries [get]
+func (h *NotificationHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling ListDeliveries request for user: %s", id)
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), id, limit, offset)
+	if err != nil {
+		log.Printf("Error listing notification deliveries: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// SetupRoutes регистрирует маршруты настроек уведомлений. Как и с профилем,
+// пользователь с JWT работает только со своими настройками.
+func (h *NotificationHandler) SetupRoutes(mux *http.ServeMux) {
+	h.guard.handle(mux, "GET /users/{id}/notifications", h.GetPreferences, auth.RequireScope(auth.ScopeUsersRead))
+	h.guard.handle(mux, "PUT /users/{id}/notifications", h.UpdatePreferences, auth.RequireScope(auth.ScopeUsersWrite))
+	h.guard.handle(mux, "GET /users/{id}/notifications/deliveries", h.ListDeliveries, auth.RequireScope(auth.ScopeUsersRead))
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/service"
+)
+
+type UserHandler struct {
+	service service.UserService
+	guard   Guard
+}
+
+func NewUserHandler(service service.UserService, guard Guard) *UserHandler {
+	return &UserHandler{service: service, guard: guard}
+}
+
+func writeUser(w http.ResponseWriter, status int, user *model.User) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// CreateUser godoc
+// @Summary Создать пользователя
+// @Description Создает профиль пользователя. Подписки можно создавать только существующим пользователям. Часовой пояс по умолчанию — UTC, валюта — RUB.
+// @Tags пользователи
+// @Accept json
+// @Produce json
+// @Param request body model.CreateUserRequest true "ID, имя, email, часовой пояс и валюта"
+// @Success 201 {object} model.User "Созданный пользователь"
+// @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к профилю другого пользователя"
+// @Failure 409 {string} string "Пользователь уже существует"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users [post]
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Handling CreateUser request")
+
+	var req model.CreateUserRequest
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
+		return
+	}
+
+	user, err := h.service.CreateUser(r.Context(), &req)
+	if err != nil {
+		log.Printf("Error creating user: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	writeUser(w, http.StatusCreated, user)
+}
+
+// ListUsers godoc
+// @Summary Список пользователей
+// @Description Возвращает пагинированный список профилей пользователей
+// @Tags пользователи
+// @Produce json
+// @Param limit query int false "Лимит (по умолчанию: 10, максимум: 100)" default(10)
+// @Param offset query int false "Смещение (по умолчанию: 0)" default(0)
+// @Success 200 {array} model.User "Список пользователей"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Security BearerAuth
+// @Router /users [get]
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Handling ListUsers request")
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	users, err := h.service.ListUsers(r.Context(), limit, offset)
+	if err != nil {
+		log.Printf("Error listing users: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(users); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// GetUser godoc
+// @Summary Получить пользователя
+// @Description Возвращает профиль пользователя
+// @Tags пользователи
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} model.User "Пользователь"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к профилю другого пользователя"
+// @Failure 404 {string} string "Пользователь не найден"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling GetUser request for ID: %s", id)
+
+	user, err := h.service.GetUser(r.Context(), id)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	writeUser(w, http.StatusOK, user)
+}
+
+// UpdateUser godoc
+// @Summary Изменить пользователя
+// @Description Изменяет переданные поля профиля. Новый часовой пояс применяется к датам начала, заданным после изменения; сохраненные подписки не сдвигаются.
+// @Tags пользователи
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param request body model.UpdateUserRequest true "Изменяемые поля профиля"
+// @Success 200 {object} model.User "Измененный пользователь"
+// @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к профилю другого пользователя"
+// @Failure 404 {string} string "Пользователь не найден"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id} [put]
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling UpdateUser request for ID: %s", id)
+
+	var req model.UpdateUserRequest
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
+		return
+	}
+
+	user, err := h.service.UpdateUser(r.Context(), id, &req)
+	if err != nil {
+		log.Printf("Error updating user: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	writeUser(w, http.StatusOK, user)
+}
+
+// DeleteUser godoc
+// @Summary Удалить пользователя
+// @Description Удаляет профиль пользователя. По политике USERS_DELETE_POLICY=restrict пользователя с подписками, включая удаленные, удалить нельзя; при cascade его подписки удаляются окончательно.
+// @Tags пользователи
+// @Param id path string true "ID пользователя"
+// @Success 204 "Пользователь удален"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Нет доступа к профилю другого пользователя"
+// @Failure 404 {string} string "Пользователь не найден"
+// @Failure 409 {string} string "У пользователя есть подписки"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id} [delete]
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling DeleteUser request for ID: %s", id)
+
+	if err := h.service.DeleteUser(r.Context(), id); err != nil {
+		log.Printf("Error deleting user: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetupRoutes регистрирует маршруты пользователей. Пользователь с JWT работает
+// только со своим профилем, список всех пользователей доступен администратору.
+func (h *UserHandler) SetupRoutes(mux *http.ServeMux) {
+	h.guard.handle(mux, "POST /users", h.CreateUser, auth.RequireScope(auth.ScopeUsersWrite))
+	h.guard.handle(mux, "GET /users", h.ListUsers, auth.RequireAdmin())
+	h.guard.handle(mux, "GET /users/{id}", h.GetUser, auth.RequireScope(auth.ScopeUsersRead))
+	h.guard.handle(mux, "PUT /users/{id}", h.UpdateUser, auth.RequireScope(auth.ScopeUsersWrite))
+	h.guard.handle(mux, "DELETE /users/{id}", h.DeleteUser, auth.RequireScope(auth.ScopeUsersWrite))
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/service"
+)
+
+type WebhookHandler struct {
+	service service.WebhookService
+	guard   Guard
+}
+
+func NewWebhookHandler(service service.WebhookService, guard Guard) *WebhookHandler {
+	return &WebhookHandler{service: service, guard: guard}
+}
+
+// CreateWebhook godoc
+// @Summary Создать webhook
+// @Description Подписывает внешнюю систему на события подписок subscription.created, subscription.updated и subscription.deleted. Каждый запрос подписан заголовком X-Webhook-Signature: sha256=HMAC-SHA256 секрета от строки "{X-Webhook-Timestamp}.{тело}". Секрет возвращается только в этом ответе.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param request body model.CreateWebhookRequest true "URL, секрет и типы событий"
+// @Success 201 {object} model.WebhookWithSecret "Созданный webhook"
+// @Failure 400 {string} string "Неверное тело запроса"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 413 {string} string "Тело запроса слишком большое"
+// @Failure 415 {string} string "Тело запроса должно быть в формате JSON"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Router /admin/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Handling CreateWebhook request")
+
+	var req model.CreateWebhookRequest
+	if !decodeJSON(w, r, &req, h.guard.MaxBodyBytes) {
+		return
+	}
+
+	hook, err := h.service.CreateWebhook(r.Context(), &req)
+	if err != nil {
+		log.Printf("Error creating webhook: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(hook); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// ListWebhooks godoc
+// @Summary Список webhook
+// @Description Возвращает все webhook без секретов
+// @Tags webhook
+// @Produce json
+// @Success 200 {array} model.Webhook "Список webhook"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Security BearerAuth
+// @Router /admin/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Handling ListWebhooks request")
+
+	hooks, err := h.service.ListWebhooks(r.Context())
+	if err != nil {
+		log.Printf("Error listing webhooks: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(hooks); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// DeleteWebhook godoc
+// @Summary Удалить webhook
+// @Description Удаляет webhook вместе с журналом доставок; недоставленные события больше не отправляются
+// @Tags webhook
+// @Param id path string true "ID webhook"
+// @Success 204 "Webhook удален"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 404 {string} string "Webhook не найден"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Router /admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling DeleteWebhook request for ID: %s", id)
+
+	if err := h.service.DeleteWebhook(r.Context(), id); err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary Журнал доставок webhook
+// @Description Возвращает доставки событий на webhook, начиная с последних: статус, число попыток, код ответа, ошибку и время следующей попытки
+// @Tags webhook
+// @Produce json
+// @Param id path string true "ID webhook"
+// @Param limit query int false "Лимит (по умолчанию: 10, максимум: 100)" default(10)
+// @Param offset query int false "Смещение (по умолчанию: 0)" default(0)
+// @Success 200 {array} model.WebhookDelivery "Доставки событий"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Требуется аутентификация"
+// @Failure 403 {string} string "Требуется роль администратора"
+// @Failure 404 {string} string "Webhook не найден"
+// @Failure 429 {string} string "Слишком много запросов"
+// @Security BearerAuth
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	log.Printf("Handling ListWebhookDeliveries request for ID: %s", id)
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), id, limit, offset)
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v", err)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// SetupRoutes регистрирует маршруты управления webhook; они доступны только
+// администратору и закрыты, если аутентификация отключена
+func (h *WebhookHandler) SetupRoutes(mux *http.ServeMux) {
+	h.guard.handleAdmin(mux, "POST /admin/webhooks", h.CreateWebhook)
+	h.guard.handleAdmin(mux, "GET /admin/webhooks", h.ListWebhooks)
+	h.guard.handleAdmin(mux, "DELETE /admin/webhooks/{id}", h.DeleteWebhook)
+	h.guard.handleAdmin(mux, "GET /admin/webhooks/{id}/deliveries", h.ListWebhookDeliveries)
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,37 +13,293 @@ import (
 type Subscription struct {
 	ID          int       `json:"id" example:"1"`
 	ServiceName string    `json:"service_name" example:"Yandex Plus"`
+	ServiceID   *int      `json:"service_id,omitempty" example:"1"`
 	Price       int       `json:"price" example:"1500"`
 	UserID      uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
 	StartDate   time.Time `json:"start_date" example:"01-2025"`
 	EndDate     time.Time `json:"end_date" example:"02-2025"`
+	// Category берется из каталога, если не задана у самой подписки (CustomCategory)
+	Category       string   `json:"category,omitempty" example:"music"`
+	CustomCategory bool     `json:"custom_category,omitempty"`
+	Tags           []string `json:"tags,omitempty" example:"family,work"`
+	// Пробный период длиной TrialMonths месяцев от StartDate до TrialEndsAt стоит
+	// TrialPrice за весь период; после него подписка оплачивается по Price
+	TrialMonths int         `json:"trial_months,omitempty" example:"1"`
+	TrialPrice  int         `json:"trial_price,omitempty" example:"0"`
+	TrialEndsAt *time.Time  `json:"trial_ends_at,omitempty"`
+	Discounts   []*Discount `json:"discounts,omitempty"`
+	// Members — пользователи, с которыми разделена стоимость; платит владелец UserID
+	Members []SubscriptionMember `json:"members,omitempty"`
+	// AutoRenew продлевает подписку на месяц, когда наступает EndDate
+	AutoRenew bool `json:"auto_renew"`
+	// Status — состояние подписки, CancelAt — момент, с которого она отменена
+	// (в будущем, если отмена назначена на конец расчетного периода)
+	Status      string              `json:"status" example:"active" enums:"active,paused,cancelled,expired"`
+	CancelAt    *time.Time          `json:"cancel_at,omitempty"`
+	Transitions []*StatusTransition `json:"transitions,omitempty"`
+	DeletedAt   *time.Time          `json:"deleted_at,omitempty"`
+}
+
+const (
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusCancelled = "cancelled"
+	// StatusExpired не хранится: так называется подписка без автопродления, чей end_date прошел
+	StatusExpired = "expired"
+)
+
+// StatusTransition представляет смену состояния подписки
+// @Description Переход между состояниями подписки. effective_at — момент, с которого действует новое состояние; для отмены в конце периода он позже created_at.
+type StatusTransition struct {
+	ID          int       `json:"id" example:"1"`
+	From        string    `json:"from" example:"active"`
+	To          string    `json:"to" example:"paused"`
+	EffectiveAt time.Time `json:"effective_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StatusAt возвращает состояние подписки в момент t. Назначенная отмена
+// вступает в силу в CancelAt, до этого подписка активна. Подписка с AutoRenew
+// после EndDate не истекает: ее продлит фоновая задача.
+func (s *Subscription) StatusAt(t time.Time) string {
+	status := s.Status
+	if status == StatusCancelled && s.CancelAt != nil && t.Before(*s.CancelAt) {
+		status = StatusActive
+	}
+	if status == StatusCancelled {
+		return StatusCancelled
+	}
+	if !t.Before(s.EndDate) && !s.AutoRenew {
+		return StatusExpired
+	}
+	if status == "" {
+		return StatusActive
+	}
+	return status
+}
+
+// PeriodEnd возвращает конец расчетного периода, идущего в момент t: конец пробного
+// периода или ближайшую после t месячную дату оплаты, но не позже EndDate.
+// Месяцы отсчитываются в часовом поясе loc (nil — UTC).
+func (s *Subscription) PeriodEnd(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if t.Before(s.StartDate) {
+		return s.StartDate
+	}
+
+	paidStart := s.StartDate
+	if s.TrialEndsAt != nil {
+		if t.Before(*s.TrialEndsAt) {
+			return *s.TrialEndsAt
+		}
+		paidStart = *s.TrialEndsAt
+	}
+
+	local := paidStart.In(loc)
+	for i := 1; ; i++ {
+		end := local.AddDate(0, i, 0).UTC()
+		if !end.Before(s.EndDate) {
+			return s.EndDate
+		}
+		if end.After(t) {
+			return end
+		}
+	}
+}
+
+// SubscriptionMember представляет участника общей подписки
+// @Description Участник платит фиксированную сумму amount за каждый месяц или долю остатка пропорционально weight (по умолчанию 1). Владелец подписки участвует с весом 1, если не указан среди участников явно.
+type SubscriptionMember struct {
+	UserID uuid.UUID `json:"user_id" example:"0b9b2c55-7c7a-4c61-9d8c-1f6e2a3b4c5d"`
+	Weight int       `json:"weight,omitempty" example:"1"`
+	Amount *int      `json:"amount,omitempty" example:"100"`
+}
+
+const (
+	DiscountPercent = "percent"
+	DiscountAmount  = "amount"
+	DiscountPrice   = "price"
+)
+
+// Discount представляет скидку на подписку
+// @Description Скидка на оплачиваемые расчетные периоды (месяцы) подписки, начавшиеся с starts_at: до ends_at или первые cycles периодов. percent — процент от цены, amount — сумма, на которую снижается цена, price — цена на время скидки.
+type Discount struct {
+	ID          int        `json:"id" example:"1"`
+	Kind        string     `json:"kind" example:"price" enums:"percent,amount,price"`
+	Value       int        `json:"value" example:"99"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	Cycles      int        `json:"cycles,omitempty" example:"3"`
+	Description string     `json:"description,omitempty" example:"Первые 3 месяца за 99 ₽"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Apply возвращает размер скидки для расчетного периода с ценой price
+func (d *Discount) Apply(price int) int {
+	var discount int
+	switch d.Kind {
+	case DiscountPercent:
+		discount = price * d.Value / 100
+	case DiscountAmount:
+		discount = d.Value
+	case DiscountPrice:
+		discount = price - d.Value
+	}
+	return min(max(discount, 0), price)
+}
+
+// DiscountRequest представляет запрос на добавление скидки
+// @Description Тело запроса для добавления скидки. Без start_period скидка действует с первого оплачиваемого месяца; end_period и cycles взаимоисключающие, без них скидка бессрочная.
+type DiscountRequest struct {
+	Kind        string `json:"kind" example:"price" enums:"percent,amount,price" binding:"required"`
+	Value       int    `json:"value" example:"99"`
+	StartPeriod string `json:"start_period,omitempty" example:"01-2025"`
+	EndPeriod   string `json:"end_period,omitempty" example:"03-2025"`
+	Cycles      int    `json:"cycles,omitempty" example:"3"`
+	Description string `json:"description,omitempty" example:"Первые 3 месяца за 99 ₽"`
+}
+
+// MonthLayout — формат месяца в start_date, start_period и end_period
+const MonthLayout = "01-2006"
+
+// MonthRange возвращает начало месяца value и начало следующего месяца в часовом
+// поясе loc (nil — UTC), приведенные к UTC
+func MonthRange(value string, loc *time.Location) (time.Time, time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	start, err := time.ParseInLocation(MonthLayout, value, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("must be in MM-YYYY format: %w", err)
+	}
+	return start.UTC(), start.AddDate(0, 1, 0).UTC(), nil
+}
+
+// SubscriptionDates возвращает конец пробного периода (nil без него) и дату
+// окончания подписки, начавшейся в start: trialMonths месяцев пробного периода
+// и месяц по обычной цене. Месяцы отсчитываются в часовом поясе loc (nil — UTC).
+func SubscriptionDates(start time.Time, trialMonths int, loc *time.Location) (*time.Time, time.Time) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := start.In(loc)
+	if trialMonths <= 0 {
+		return nil, local.AddDate(0, 1, 0).UTC()
+	}
+	trialEndsAt := local.AddDate(0, trialMonths, 0).UTC()
+	return &trialEndsAt, local.AddDate(0, trialMonths+1, 0).UTC()
+}
+
+// RenewedEndDate возвращает дату окончания подписки, продленной после t: ближайшую
+// месячную дату оплаты позже t и EndDate. Месяцы отсчитываются от начала оплачиваемого
+// периода в часовом поясе loc (nil — UTC), поэтому день оплаты не смещается.
+func (s *Subscription) RenewedEndDate(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	paidStart := s.StartDate
+	if s.TrialEndsAt != nil {
+		paidStart = *s.TrialEndsAt
+	}
+
+	local := paidStart.In(loc)
+	for i := 1; ; i++ {
+		end := local.AddDate(0, i, 0).UTC()
+		if end.After(t) && end.After(s.EndDate) {
+			return end
+		}
+	}
+}
+
+// SubscriptionFilter ограничивает список подписок категорией и тегом;
+// пустое поле не ограничивает
+type SubscriptionFilter struct {
+	Category string
+	Tag      string
 }
 
 // CreateSubscriptionRequest представляет запрос на создание подписки
 // @Description Тело запроса для создания новой подписки
+// Вместо service_name можно передать service_id сервиса из каталога. Если цена
+// не указана, берется цена тарифа plan (по умолчанию — первого тарифа сервиса).
 type CreateSubscriptionRequest struct {
-	ServiceName string `json:"service_name" example:"Yandex Plus" binding:"required"`
-	Price       int    `json:"price" example:"1500" binding:"required,gt=0"`
+	ServiceName string `json:"service_name" example:"Yandex Plus"`
+	ServiceID   *int   `json:"service_id,omitempty" example:"1"`
+	Plan        string `json:"plan,omitempty" example:"Семейный"`
+	Price       int    `json:"price" example:"1500"`
 	UserID      string `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba" binding:"required"`
 	StartDate   string `json:"start_date" example:"01-2025" binding:"required"`
+	// Category заменяет категорию сервиса из каталога
+	Category string   `json:"category,omitempty" example:"streaming"`
+	Tags     []string `json:"tags,omitempty" example:"family,work"`
+	// TrialMonths — длина пробного периода в месяцах, TrialPrice — его стоимость
+	TrialMonths int `json:"trial_months,omitempty" example:"1"`
+	TrialPrice  int `json:"trial_price,omitempty" example:"0"`
+	// AutoRenew включает ежемесячное продление подписки
+	AutoRenew bool `json:"auto_renew,omitempty" example:"true"`
+	// Members разделяет стоимость подписки с другими пользователями
+	Members []SubscriptionMember `json:"members,omitempty"`
 }
 
 // UpdateSubscriptionRequest представляет запрос на обновление подписки
 // @Description Тело запроса для обновления существующей подписки
 type UpdateSubscriptionRequest struct {
 	ServiceName *string `json:"service_name,omitempty" example:"Yandex Plus Premium"`
+	ServiceID   *int    `json:"service_id,omitempty" example:"1"`
 	Price       *int    `json:"price,omitempty" example:"2000"`
 	UserID      *string `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
 	StartDate   *string `json:"start_date,omitempty" example:"02-2025"`
+	// Category задает свою категорию; пустая строка возвращает категорию из каталога
+	Category *string `json:"category,omitempty" example:"streaming"`
+	// Tags заменяет теги целиком; пустой список удаляет все теги
+	Tags []string `json:"tags,omitempty" example:"family"`
+	// TrialMonths меняет длину пробного периода, 0 убирает его
+	TrialMonths *int  `json:"trial_months,omitempty" example:"2"`
+	TrialPrice  *int  `json:"trial_price,omitempty" example:"99"`
+	AutoRenew   *bool `json:"auto_renew,omitempty" example:"false"`
+	// Members заменяет участников целиком; пустой список делает подписку личной
+	Members []SubscriptionMember `json:"members,omitempty"`
+	// Location — часовой пояс владельца подписки для границ месяца start_date;
+	// заполняется сервисом из профиля пользователя
+	Location *time.Location `json:"-"`
 }
 
 // CalculateCostRequest представляет запрос на расчет стоимости
 // @Description Тело запроса для расчета общей стоимости подписок
+// Сервис задается названием, псевдонимом из каталога или service_id. С group_by
+// сервис можно не указывать: итог разбивается по категориям или тегам всех подписок.
 type CalculateCostRequest struct {
 	UserID      string `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba" binding:"required"`
-	ServiceName string `json:"service_name" example:"Yandex Plus" binding:"required"`
+	ServiceName string `json:"service_name" example:"Yandex Plus"`
+	ServiceID   *int   `json:"service_id,omitempty" example:"1"`
 	StartPeriod string `json:"start_period" example:"01-2025" binding:"required"`
 	EndPeriod   string `json:"end_period" example:"02-2025" binding:"required"`
+	GroupBy     string `json:"group_by,omitempty" example:"category" enums:"category,tag,month"`
+	// Perspective payer считает полную стоимость подписок, которые оплачивает
+	// пользователь; member — его долю во всех подписках, где он участвует
+	Perspective string `json:"perspective,omitempty" example:"payer" enums:"payer,member"`
+	// Location — часовой пояс пользователя для границ периода; заполняется сервисом
+	Location *time.Location `json:"-"`
+}
+
+const (
+	GroupByCategory = "category"
+	GroupByTag      = "tag"
+	GroupByMonth    = "month"
+
+	PerspectivePayer  = "payer"
+	PerspectiveMember = "member"
+)
+
+// CostGroup представляет стоимость подписок одной категории, с одним тегом или за один месяц
+// @Description Итог по группе; пустой ключ — подписки без категории или без тегов. discount — сумма скидок, уже вычтенная из total_cost.
+type CostGroup struct {
+	Key       string `json:"key" example:"music"`
+	TotalCost int    `json:"total_cost" example:"1200"`
+	Discount  int    `json:"discount,omitempty" example:"200"`
 }
 
 // CalculateCostResponse представляет ответ с расчетом стоимости
@@ -49,7 +307,238 @@ type CalculateCostRequest struct {
 type CalculateCostResponse struct {
 	TotalCost   int       `json:"total_cost" example:"18000"`
 	UserID      uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	ServiceName string    `json:"service_name" example:"Yandex Plus"`
+	ServiceName string    `json:"service_name,omitempty" example:"Yandex Plus"`
+	ServiceID   *int      `json:"service_id,omitempty" example:"1"`
 	StartPeriod string    `json:"start_period" example:"01-2025"`
 	EndPeriod   string    `json:"end_period" example:"02-2025"`
+	GroupBy     string    `json:"group_by,omitempty" example:"category"`
+	Perspective string    `json:"perspective" example:"payer"`
+	// Currency — валюта по умолчанию из профиля пользователя
+	Currency string `json:"currency,omitempty" example:"RUB"`
+	// Подписка с несколькими тегами входит в каждую из их групп
+	Groups []*CostGroup `json:"groups,omitempty"`
+}
+
+// Service представляет сервис из каталога
+// @Description Сервис каталога: каноническое название, псевдонимы и тарифы
+type Service struct {
+	ID        int           `json:"id" example:"1"`
+	Name      string        `json:"name" example:"Yandex Plus"`
+	Aliases   []string      `json:"aliases" example:"Яндекс Плюс,yandex+"`
+	Category  string        `json:"category,omitempty" example:"music"`
+	Plans     []ServicePlan `json:"plans"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// ServicePlan представляет тариф сервиса с ценой по умолчанию
+type ServicePlan struct {
+	Name  string `json:"name" example:"Семейный"`
+	Price int    `json:"price" example:"649"`
+}
+
+// ServiceRequest представляет запрос на создание или замену сервиса в каталоге
+// @Description Тело запроса для создания или изменения сервиса каталога
+type ServiceRequest struct {
+	Name     string        `json:"name" example:"Yandex Plus" binding:"required"`
+	Aliases  []string      `json:"aliases" example:"Яндекс Плюс,yandex+"`
+	Category string        `json:"category,omitempty" example:"music"`
+	Plans    []ServicePlan `json:"plans"`
+}
+
+// User представляет профиль пользователя, которому принадлежат подписки
+// @Description Профиль пользователя с часовым поясом и валютой по умолчанию
+type User struct {
+	ID    uuid.UUID `json:"id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Name  string    `json:"name,omitempty" example:"Иван Петров"`
+	Email string    `json:"email,omitempty" example:"ivan@example.com"`
+	// Timezone — часовой пояс IANA, в котором считаются границы месяцев подписок
+	Timezone  string    `json:"timezone" example:"Europe/Moscow"`
+	Currency  string    `json:"currency" example:"RUB"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateUserRequest представляет запрос на создание пользователя
+// @Description Тело запроса для создания профиля. Без id пользователь с JWT создает свой профиль.
+type CreateUserRequest struct {
+	ID       string `json:"id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Name     string `json:"name,omitempty" example:"Иван Петров"`
+	Email    string `json:"email,omitempty" example:"ivan@example.com"`
+	Timezone string `json:"timezone,omitempty" example:"Europe/Moscow"`
+	Currency string `json:"currency,omitempty" example:"RUB"`
+}
+
+// UpdateUserRequest представляет запрос на изменение профиля
+// @Description Тело запроса для изменения профиля; изменяются только переданные поля
+type UpdateUserRequest struct {
+	Name     *string `json:"name,omitempty" example:"Иван Петров"`
+	Email    *string `json:"email,omitempty" example:"ivan@example.com"`
+	Timezone *string `json:"timezone,omitempty" example:"Asia/Yekaterinburg"`
+	Currency *string `json:"currency,omitempty" example:"USD"`
+}
+
+// NotificationPreferences представляет настройки уведомлений пользователя
+// @Description Настройки напоминаний о продлении подписок с auto_renew. Напоминание приходит за lead_days дней до продления (0 — срок по умолчанию) на email профиля и/или POST-запросом на webhook_url.
+type NotificationPreferences struct {
+	UserID           uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	RenewalReminders bool      `json:"renewal_reminders" example:"true"`
+	Email            bool      `json:"email" example:"true"`
+	WebhookURL       string    `json:"webhook_url,omitempty" example:"https://hooks.example.com/renewals"`
+	LeadDays         int       `json:"lead_days" example:"3"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// UpdateNotificationPreferencesRequest представляет запрос на изменение настроек уведомлений
+// @Description Тело запроса для изменения настроек уведомлений; изменяются только переданные поля. Пустой webhook_url отключает webhook.
+type UpdateNotificationPreferencesRequest struct {
+	RenewalReminders *bool   `json:"renewal_reminders,omitempty" example:"true"`
+	Email            *bool   `json:"email,omitempty" example:"false"`
+	WebhookURL       *string `json:"webhook_url,omitempty" example:"https://hooks.example.com/renewals"`
+	LeadDays         *int    `json:"lead_days,omitempty" example:"3"`
+}
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// NotificationDelivery представляет отправку напоминания по одному каналу
+// @Description Запись об отправке напоминания. Для каждой подписки, канала и даты продления создается одна запись, поэтому напоминание не повторяется.
+type NotificationDelivery struct {
+	ID             int64      `json:"id" example:"1"`
+	SubscriptionID int        `json:"subscription_id" example:"1"`
+	UserID         uuid.UUID  `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Channel        string     `json:"channel" example:"email" enums:"email,webhook"`
+	RenewsAt       time.Time  `json:"renews_at"`
+	Status         string     `json:"status" example:"sent" enums:"pending,sent,failed"`
+	Attempts       int        `json:"attempts" example:"1"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ClaimedAt      time.Time  `json:"claimed_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+}
+
+// RenewalReminder — содержимое напоминания о предстоящем продлении подписки
+type RenewalReminder struct {
+	SubscriptionID int       `json:"subscription_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	ServiceName    string    `json:"service_name"`
+	Price          int       `json:"price"`
+	Currency       string    `json:"currency"`
+	RenewsAt       time.Time `json:"renews_at"`
+}
+
+// APIKey представляет ключ доступа для межсервисных вызовов
+// @Description Информация о ключе API (без секрета)
+type APIKey struct {
+	ID         int        `json:"id" example:"1"`
+	Name       string     `json:"name" example:"billing-backend"`
+	Prefix     string     `json:"prefix" example:"sk_3f9a1c2e"`
+	Scopes     []string   `json:"scopes" example:"subscriptions:read,reports:read"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyRequest представляет запрос на создание ключа API
+// @Description Тело запроса для создания ключа API
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" example:"billing-backend" binding:"required"`
+	Scopes []string `json:"scopes" example:"subscriptions:read,reports:read" binding:"required"`
+}
+
+// APIKeyWithSecret представляет ключ API вместе с секретом
+// @Description Ответ при создании или ротации ключа; секрет показывается только один раз
+type APIKeyWithSecret struct {
+	APIKey
+	Key string `json:"key" example:"sk_3f9a1c2e5b7d4a6f8e0c1b3d5f7a9c2e4b6d8f0a1c3e5b7d"`
+}
+
+const (
+	EventSubscriptionCreated = "subscription.created"
+	EventSubscriptionUpdated = "subscription.updated"
+	EventSubscriptionDeleted = "subscription.deleted"
+)
+
+// WebhookEvents — типы событий, на которые можно подписать webhook
+var WebhookEvents = []string{EventSubscriptionCreated, EventSubscriptionUpdated, EventSubscriptionDeleted}
+
+// Webhook представляет подписку внешней системы на события подписок
+// @Description Webhook, на который отправляются события подписок (без секрета)
+type Webhook struct {
+	ID     int      `json:"id" example:"1"`
+	URL    string   `json:"url" example:"https://billing.example.com/hooks/subscriptions"`
+	Events []string `json:"events" example:"subscription.created,subscription.deleted"`
+	// Secret — ключ подписи HMAC-SHA256; показывается только при создании
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateWebhookRequest представляет запрос на создание webhook
+// @Description Тело запроса для создания webhook. Без events webhook получает все события, без secret секрет генерируется.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" example:"https://billing.example.com/hooks/subscriptions" binding:"required"`
+	Secret string   `json:"secret,omitempty" example:"3f9a1c2e5b7d4a6f8e0c1b3d"`
+	Events []string `json:"events,omitempty" example:"subscription.created,subscription.deleted"`
+}
+
+// WebhookWithSecret представляет webhook вместе с секретом подписи
+// @Description Ответ при создании webhook; секрет показывается только один раз
+type WebhookWithSecret struct {
+	Webhook
+	Secret string `json:"secret" example:"whsec_3f9a1c2e5b7d4a6f8e0c1b3d5f7a9c2e4b6d8f0a1c3e5b7d"`
+}
+
+// WebhookEvent — тело запроса, которым событие доставляется на webhook
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	// Subscription — подписка после изменения; для удаления — до него
+	Subscription *Subscription `json:"subscription"`
+}
+
+// WebhookDelivery представляет доставку события на webhook
+// @Description Запись журнала доставок. Неудачная доставка повторяется в next_attempt_at с удваивающейся задержкой; после последней попытки получает статус failed.
+type WebhookDelivery struct {
+	ID             int64           `json:"id" example:"1"`
+	WebhookID      int             `json:"webhook_id" example:"1"`
+	EventID        string          `json:"event_id" example:"2f1c6a0e-8d4b-4f4e-9a57-1b2c3d4e5f60"`
+	Event          string          `json:"event" example:"subscription.created"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status" example:"sent" enums:"pending,sent,failed"`
+	Attempts       int             `json:"attempts" example:"1"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty" example:"200"`
+	Error          string          `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// SubscriptionAuditEntry представляет запись журнала изменений подписки
+// @Description Запись истории изменений подписки
+type SubscriptionAuditEntry struct {
+	ID             int64           `json:"id" example:"1"`
+	SubscriptionID int             `json:"subscription_id" example:"1"`
+	Operation      string          `json:"operation" example:"update" enums:"create,update,delete,restore,renew"`
+	Actor          string          `json:"actor" example:"user:60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ChangedAt      time.Time       `json:"changed_at"`
+	Before         json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After          json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+}
+
+// Readiness описывает готовность сервиса принимать запросы
+type Readiness struct {
+	Status                string `json:"status" example:"ready"`
+	Storage               string `json:"storage" example:"ok"`
+	SchemaVersion         *uint  `json:"schema_version,omitempty" example:"4"`
+	ExpectedSchemaVersion *uint  `json:"expected_schema_version,omitempty" example:"4"`
+	SchemaDirty           bool   `json:"schema_dirty,omitempty"`
+	Error                 string `json:"error,omitempty"`
 }
@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress — адрес webhook ведет во внутреннюю сеть сервиса
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// reservedPrefixes — непубличные подсети, для которых в netip нет проверок:
+// адреса "этой сети", разделяемое пространство операторов (CGNAT) и подсеть
+// для тестирования сетевого оборудования
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// publicAddr сообщает, можно ли отправлять запросы на адрес: loopback, частные,
+// link-local, multicast, неуказанные адреса и reservedPrefixes запрещены
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHost проверяет хост из URL webhook: имя localhost и непубличные IP-адреса
+// отклоняются. Адреса, в которые разрешается имя, проверяются при отправке.
+func CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// newHTTPClient возвращает клиент для webhook, который соединяется только с
+// публичными адресами. Проверяется уже разрешенный адрес, поэтому имя, указывающее
+// во внутреннюю сеть, и перенаправление туда тоже отклоняются. Прокси из окружения
+// не используется: иначе проверялся бы адрес прокси, а не получателя.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return newGuardedClient(timeout, publicAddr)
+}
+
+// newGuardedClient возвращает клиент, который соединяется только с адресами,
+// разрешенными allowed
+func newGuardedClient(timeout time.Duration, allowed func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !allowed(addr) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
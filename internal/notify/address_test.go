@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "100.63.255.255", want: true},
+		{addr: "100.128.0.0", want: true},
+		{addr: "198.20.0.1", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "fd00::1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "224.0.0.1"},
+		{addr: "ff02::1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "0.1.2.3"},
+		{addr: "100.64.0.1"},
+		{addr: "100.127.255.254"},
+		{addr: "198.18.0.1"},
+		{addr: "198.19.255.254"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "::ffff:100.64.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := publicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("publicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		wantErr bool
+	}{
+		{host: "billing.example.com"},
+		{host: "93.184.216.34"},
+		{host: "localhost", wantErr: true},
+		{host: "LOCALHOST.", wantErr: true},
+		{host: "api.localhost", wantErr: true},
+		{host: "127.0.0.1", wantErr: true},
+		{host: "::1", wantErr: true},
+		{host: "10.0.0.5", wantErr: true},
+		{host: "169.254.169.254", wantErr: true},
+		{host: "100.64.1.1", wantErr: true},
+		{host: "198.18.0.1", wantErr: true},
+		{host: "0.0.0.0", wantErr: true},
+		// Имена проверяются при соединении, когда известен адрес
+		{host: "internal.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			err := CheckHost(tt.host)
+			if tt.wantErr && !errors.Is(err, ErrForbiddenAddress) {
+				t.Fatalf("expected ErrForbiddenAddress, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPClientRejectsPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must not reach a loopback address")
+	}))
+	defer server.Close()
+
+	client := newHTTPClient(time.Second)
+	for _, url := range []string{server.URL, "http://localhost:" + port(t, server.URL)} {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("GET %s: expected ErrForbiddenAddress, got %v", url, err)
+		}
+	}
+}
+
+func TestHTTPClientRejectsRedirectToForbiddenAddress(t *testing.T) {
+	// Второй адрес loopback изображает внутреннюю сеть, первый — разрешенного получателя
+	listener, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skipf("127.0.0.2 is not available: %v", err)
+	}
+	internal := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect must not reach a forbidden address")
+	}))
+	internal.Listener = listener
+	internal.Start()
+	defer internal.Close()
+
+	public := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer public.Close()
+
+	allowed := netip.MustParseAddr("127.0.0.1")
+	client := newGuardedClient(time.Second, func(addr netip.Addr) bool { return addr == allowed })
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, public.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected ErrForbiddenAddress on redirect, got %v", err)
+	}
+}
+
+func port(t *testing.T, rawURL string) string {
+	t.Helper()
+	_, p, err := net.SplitHostPort(rawURL[len("http://"):])
+	if err != nil {
+		t.Fatalf("failed to parse server address: %v", err)
+	}
+	return p
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	// Подпись посчитана независимо: HMAC-SHA256("secret", "1700000000.{\"id\":1}")
+	want := "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"
+
+	if got := Sign("secret", 1700000000, body); got != want {
+		t.Fatalf("Sign = %s, want %s", got, want)
+	}
+	if Sign("other", 1700000000, body) == want {
+		t.Error("signature must depend on the secret")
+	}
+	if Sign("secret", 1700000001, body) == want {
+		t.Error("signature must depend on the timestamp")
+	}
+	if Sign("secret", 1700000000, []byte(`{"id":2}`)) == want {
+		t.Error("signature must depend on the body")
+	}
+}
+
+func TestEventPosterPost(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	payload := []byte(`{"id":1}`)
+
+	tests := []struct {
+		name       string
+		status     int
+		wantErr    bool
+		wantStatus int
+	}{
+		{name: "delivered", status: http.StatusNoContent, wantStatus: http.StatusNoContent},
+		{name: "rejected", status: http.StatusInternalServerError, wantErr: true, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+				if err != nil || timestamp != now.Unix() {
+					t.Errorf("unexpected timestamp header %q", r.Header.Get(HeaderTimestamp))
+				}
+				if !hmac.Equal([]byte(r.Header.Get(HeaderSignature)), []byte(Sign("secret", timestamp, body))) {
+					t.Errorf("signature %q does not match body", r.Header.Get(HeaderSignature))
+				}
+				if r.Header.Get(HeaderEventID) != "event-1" || r.Header.Get(HeaderEventType) != model.EventSubscriptionCreated {
+					t.Errorf("unexpected event headers: %v", r.Header)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			// Тестовый сервер слушает loopback, поэтому проверка адресов здесь отключена
+			poster := &EventPoster{client: newGuardedClient(time.Second, func(netip.Addr) bool { return true })}
+			status, err := poster.Post(context.Background(),
+				&model.Webhook{URL: server.URL, Secret: "secret"},
+				&model.WebhookDelivery{EventID: "event-1", Event: model.EventSubscriptionCreated, Payload: payload},
+				now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Post error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Package notify доставляет напоминания о продлении подписок по почте и webhook
+// и подписанные события подписок на webhook внешних систем.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/config"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+// Sender доставляет напоминание получателю to: адресу почты или URL webhook
+type Sender interface {
+	Send(ctx context.Context, to string, reminder *model.RenewalReminder) error
+}
+
+// NewSenders возвращает отправителей по каналам. Без SMTP-сервера или с LogOnly
+// напоминания только пишутся в журнал.
+func NewSenders(cfg config.NotificationsConfig) map[string]Sender {
+	if cfg.LogOnly {
+		log.Println("Notifications are log-only: reminders are written to the log")
+		return map[string]Sender{model.ChannelEmail: LogSender{}, model.ChannelWebhook: LogSender{}}
+	}
+
+	senders := map[string]Sender{model.ChannelWebhook: NewWebhookSender(cfg.WebhookTimeout)}
+	if cfg.SMTP.Host != "" {
+		senders[model.ChannelEmail] = NewSMTPSender(cfg.SMTP)
+	} else {
+		log.Println("WARNING: SMTP is not configured, email reminders are written to the log")
+		senders[model.ChannelEmail] = LogSender{}
+	}
+	return senders
+}
+
+// LogSender пишет напоминание в журнал вместо отправки
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, to string, reminder *model.RenewalReminder) error {
+	log.Printf("Renewal reminder for %s: %s", to, reminderText(reminder))
+	return nil
+}
+
+// reminderText описывает напоминание одной строкой
+func reminderText(reminder *model.RenewalReminder) string {
+	return fmt.Sprintf("подписка %s (#%d) продлится %s по цене %d %s в месяц",
+		reminder.ServiceName, reminder.SubscriptionID, reminder.RenewsAt.Format(time.DateOnly),
+		reminder.Price, reminder.Currency)
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/config"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+// SMTPSender отправляет напоминания письмами через SMTP-сервер
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPSender создает отправителя писем. Без имени пользователя письма
+// отправляются без аутентификации.
+func NewSMTPSender(cfg config.SMTPConfig) *SMTPSender {
+	sender := &SMTPSender{addr: net.JoinHostPort(cfg.Host, cfg.Port), from: cfg.From}
+	if cfg.Username != "" {
+		sender.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return sender
+}
+
+func (s *SMTPSender) Send(ctx context.Context, to string, reminder *model.RenewalReminder) error {
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient address %q", to)
+	}
+
+	subject := mime.QEncoding.Encode("utf-8", "Скоро продление подписки "+reminder.ServiceName)
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&msg, "Напоминаем: %s.\r\n", reminderText(reminder))
+
+	// net/smtp не принимает контекст, поэтому письмо отправляется в отдельной горутине
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, s.auth, s.from, []string{to}, []byte(msg.String()))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+// ReminderEvent — тип события в теле запроса webhook с напоминанием
+const ReminderEvent = "subscription.renewal_reminder"
+
+// WebhookSender отправляет напоминание POST-запросом с JSON на URL пользователя.
+// Адреса внутренней сети отклоняются.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender(timeout time.Duration) *WebhookSender {
+	return &WebhookSender{client: newHTTPClient(timeout)}
+}
+
+type webhookPayload struct {
+	Event    string                 `json:"event"`
+	Reminder *model.RenewalReminder `json:"reminder"`
+}
+
+func (s *WebhookSender) Send(ctx context.Context, to string, reminder *model.RenewalReminder) error {
+	body, err := json.Marshal(webhookPayload{Event: ReminderEvent, Reminder: reminder})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, to, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
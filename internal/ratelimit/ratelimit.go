@@ -0,0 +1,229 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/config"
+	"github.com/google/uuid"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   config.RateLimit
+}
+
+// Limiter ограничивает частоту запросов алгоритмом корзины токенов.
+// Корзина заводится на каждую пару маршрут + клиент.
+type Limiter struct {
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	defaults   config.RateLimit
+	routes     map[string]config.RateLimit
+	perIP      config.RateLimit
+	trustProxy bool
+	proxies    config.Prefixes
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+func NewLimiter(cfg config.RateLimitConfig) *Limiter {
+	return &Limiter{
+		buckets:    make(map[string]*bucket),
+		defaults:   cfg.Default,
+		routes:     cfg.Routes,
+		perIP:      cfg.PerIP,
+		trustProxy: cfg.TrustProxy,
+		proxies:    cfg.TrustedProxies,
+		now:        time.Now,
+	}
+}
+
+func (l *Limiter) limitFor(route string) config.RateLimit {
+	if limit, ok := l.routes[route]; ok {
+		return limit
+	}
+	return l.defaults
+}
+
+// refill пополняет корзину на время, прошедшее с последнего обращения
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+	b.updated = now
+}
+
+type decision struct {
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+func (l *Limiter) take(key string, limit config.RateLimit) decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now, limit: limit}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+
+	d := decision{allowed: b.tokens >= 1}
+	if d.allowed {
+		b.tokens--
+	} else {
+		d.retryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	d.remaining = int(b.tokens)
+	d.reset = secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return d
+}
+
+// sweep удаляет корзины, которые уже успели заполниться: они неотличимы от новых
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// clientKey определяет клиента: ключ API, пользователь из JWT или IP-адрес
+func (l *Limiter) clientKey(r *http.Request) string {
+	if p, ok := auth.PrincipalFromContext(r.Context()); ok {
+		if p.APIKeyID != 0 {
+			return fmt.Sprintf("key:%d", p.APIKeyID)
+		}
+		if p.UserID != uuid.Nil {
+			return "user:" + p.UserID.String()
+		}
+		if p.Subject != "" {
+			return "sub:" + p.Subject
+		}
+	}
+	return l.ipKey(r)
+}
+
+// ipKey определяет клиента по IP-адресу
+func (l *Limiter) ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if l.trustProxy {
+		if client, ok := l.forwardedClient(r); ok {
+			return "ip:" + client.String()
+		}
+	}
+	return "ip:" + host
+}
+
+// forwardedClient возвращает адрес клиента из X-Forwarded-For. Левые элементы
+// заголовка присылает сам клиент и может подделать, поэтому список читается
+// справа налево: доверенные прокси пропускаются, первый прочий адрес и есть
+// клиент.
+func (l *Limiter) forwardedClient(r *http.Request) (netip.Addr, bool) {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Испорченная цепочка: доверять ее левой части нельзя
+			return client, client.IsValid()
+		}
+		client = addr.Unmap()
+		if !l.proxies.Contains(client) {
+			return client, true
+		}
+	}
+	// Все адреса принадлежат доверенным прокси: берется самый левый из них
+	return client, client.IsValid()
+}
+
+// Middleware ограничивает частоту запросов к маршруту route (шаблон из ServeMux).
+// Лимит с нулевой скоростью отключает ограничение для маршрута.
+func (l *Limiter) Middleware(route string) func(http.Handler) http.Handler {
+	limit := l.limitFor(route)
+
+	return func(next http.Handler) http.Handler {
+		if limit.Rate <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := l.clientKey(r)
+			d := l.take(route+"|"+key, limit)
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(d.reset.Seconds()))))
+
+			if !d.allowed {
+				log.Printf("Rate limit exceeded for %s on %s", key, route)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.retryAfter.Seconds()))))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IPMiddleware ограничивает частоту запросов с одного IP-адреса ко всем маршрутам
+// вместе. Располагается до аутентификации, чтобы перебор ключей и токенов
+// ограничивался раньше, чем дойдет до базы. Лимит с нулевой скоростью отключает
+// ограничение.
+func (l *Limiter) IPMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if l.perIP.Rate <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := l.ipKey(r)
+			d := l.take("*|"+key, l.perIP)
+			if !d.allowed {
+				log.Printf("Rate limit exceeded for %s before authentication", key)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.retryAfter.Seconds()))))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/config"
+	"github.com/google/uuid"
+)
+
+// fakeClock подменяет время лимитера, чтобы пополнение корзин не зависело от скорости тестов
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestLimiter(cfg config.RateLimitConfig) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewLimiter(cfg)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func request(remoteAddr string, principal *auth.Principal) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/subscriptions/list", nil)
+	r.RemoteAddr = remoteAddr
+	if principal != nil {
+		r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+	}
+	return r
+}
+
+func serve(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestTakeBurstAndRefill(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{})
+	limit := config.RateLimit{Rate: 2, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if d := limiter.take("client", limit); !d.allowed || d.remaining != 2-i {
+			t.Fatalf("request %d within burst: %+v", i, d)
+		}
+	}
+
+	d := limiter.take("client", limit)
+	if d.allowed {
+		t.Fatal("request over burst must be rejected")
+	}
+	if d.retryAfter != 500*time.Millisecond {
+		t.Errorf("retryAfter = %s, want 500ms", d.retryAfter)
+	}
+	if d.reset != 1500*time.Millisecond {
+		t.Errorf("reset = %s, want 1.5s", d.reset)
+	}
+
+	// За полсекунды при скорости 2 запроса в секунду появляется один токен
+	clock.Advance(500 * time.Millisecond)
+	if d := limiter.take("client", limit); !d.allowed || d.remaining != 0 {
+		t.Fatalf("request after refill: %+v", d)
+	}
+	if d := limiter.take("client", limit); d.allowed {
+		t.Fatal("refill must add a single token")
+	}
+
+	// Корзина не наполняется выше емкости
+	clock.Advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		if d := limiter.take("client", limit); !d.allowed {
+			t.Fatalf("request %d after long idle: %+v", i, d)
+		}
+	}
+	if d := limiter.take("client", limit); d.allowed {
+		t.Fatal("idle bucket must not exceed burst")
+	}
+
+	if d := limiter.take("other", limit); !d.allowed {
+		t.Fatal("clients must have separate buckets")
+	}
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{Default: config.RateLimit{Rate: 1, Burst: 2}})
+	handler := limiter.Middleware("GET /subscriptions/list")(okHandler())
+
+	tests := []struct {
+		wantStatus     int
+		wantRemaining  string
+		wantReset      string
+		wantRetryAfter string
+	}{
+		{wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "1"},
+		{wantStatus: http.StatusOK, wantRemaining: "0", wantReset: "2"},
+		{wantStatus: http.StatusTooManyRequests, wantRemaining: "0", wantReset: "2", wantRetryAfter: "1"},
+	}
+
+	for i, tt := range tests {
+		w := serve(handler, request("192.0.2.1:1234", nil))
+		if w.Code != tt.wantStatus {
+			t.Fatalf("request %d: status = %d, want %d", i, w.Code, tt.wantStatus)
+		}
+		headers := map[string]string{
+			"RateLimit-Limit":     "2",
+			"RateLimit-Remaining": tt.wantRemaining,
+			"RateLimit-Reset":     tt.wantReset,
+			"Retry-After":         tt.wantRetryAfter,
+		}
+		for name, want := range headers {
+			if got := w.Header().Get(name); got != want {
+				t.Errorf("request %d: %s = %q, want %q", i, name, got, want)
+			}
+		}
+	}
+
+	clock.Advance(time.Second)
+	if w := serve(handler, request("192.0.2.1:1234", nil)); w.Code != http.StatusOK {
+		t.Fatalf("after Retry-After: status = %d, want 200", w.Code)
+	}
+}
+
+func TestMiddlewareRouteLimits(t *testing.T) {
+	limiter, _ := newTestLimiter(config.RateLimitConfig{
+		Default: config.RateLimit{Rate: 1, Burst: 3},
+		Routes: config.RouteLimits{
+			"POST /subscriptions/total-cost": {Rate: 1, Burst: 1},
+			"GET /health":                    {Rate: 0, Burst: 1},
+		},
+	})
+
+	tests := []struct {
+		route   string
+		allowed int
+		limit   string
+	}{
+		{route: "GET /subscriptions/list", allowed: 3, limit: "3"},
+		{route: "POST /subscriptions/total-cost", allowed: 1, limit: "1"},
+		{route: "GET /health", allowed: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			handler := limiter.Middleware(tt.route)(okHandler())
+			for i := 0; i < tt.allowed; i++ {
+				w := serve(handler, request("192.0.2.1:1234", nil))
+				if w.Code != http.StatusOK {
+					t.Fatalf("request %d: status = %d, want 200", i, w.Code)
+				}
+				if got := w.Header().Get("RateLimit-Limit"); got != tt.limit {
+					t.Errorf("RateLimit-Limit = %q, want %q", got, tt.limit)
+				}
+			}
+			if tt.limit == "" {
+				return
+			}
+			if w := serve(handler, request("192.0.2.1:1234", nil)); w.Code != http.StatusTooManyRequests {
+				t.Fatalf("request over limit: status = %d, want 429", w.Code)
+			}
+		})
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	limiter, _ := newTestLimiter(config.RateLimitConfig{})
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		want      string
+	}{
+		{name: "API key wins over user", principal: &auth.Principal{APIKeyID: 7, UserID: userID, Subject: "svc"}, want: "key:7"},
+		{name: "user wins over subject", principal: &auth.Principal{UserID: userID, Subject: "alice"}, want: "user:" + userID.String()},
+		{name: "subject without user", principal: &auth.Principal{Subject: "admin", Admin: true}, want: "sub:admin"},
+		{name: "anonymous falls back to IP", want: "ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limiter.clientKey(request("192.0.2.1:1234", tt.principal)); got != tt.want {
+				t.Errorf("clientKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPKey(t *testing.T) {
+	var proxies config.Prefixes
+	if err := proxies.UnmarshalText([]byte("10.0.0.0/8, 192.0.2.10")); err != nil {
+		t.Fatalf("UnmarshalText: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		trustProxy bool
+		proxies    config.Prefixes
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "remote address", remoteAddr: "198.51.100.7:5000", want: "ip:198.51.100.7"},
+		{name: "forwarded ignored without trust", remoteAddr: "10.0.0.1:5000", forwarded: []string{"203.0.113.5"}, want: "ip:10.0.0.1"},
+		{name: "single proxy", trustProxy: true, remoteAddr: "10.0.0.1:5000", forwarded: []string{"203.0.113.5"}, want: "ip:203.0.113.5"},
+		{
+			name:       "spoofed left entry ignored",
+			trustProxy: true,
+			remoteAddr: "10.0.0.1:5000",
+			forwarded:  []string{"1.2.3.4, 203.0.113.5"},
+			want:       "ip:203.0.113.5",
+		},
+		{
+			name:       "trusted proxies skipped",
+			trustProxy: true,
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:5000",
+			forwarded:  []string{"1.2.3.4, 203.0.113.5, 10.1.1.1", "192.0.2.10"},
+			want:       "ip:203.0.113.5",
+		},
+		{
+			name:       "all entries trusted",
+			trustProxy: true,
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:5000",
+			forwarded:  []string{"10.2.2.2, 10.1.1.1"},
+			want:       "ip:10.2.2.2",
+		},
+		{
+			name:       "garbage entry stops the walk",
+			trustProxy: true,
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:5000",
+			forwarded:  []string{"203.0.113.5, not-an-ip, 10.1.1.1"},
+			want:       "ip:10.1.1.1",
+		},
+		{name: "invalid header falls back", trustProxy: true, remoteAddr: "10.0.0.1:5000", forwarded: []string{"unknown"}, want: "ip:10.0.0.1"},
+		{name: "empty header falls back", trustProxy: true, remoteAddr: "10.0.0.1:5000", want: "ip:10.0.0.1"},
+		{name: "IPv6 client", trustProxy: true, remoteAddr: "[::1]:5000", forwarded: []string{"2001:db8::1"}, want: "ip:2001:db8::1"},
+		{name: "IPv4-mapped client", trustProxy: true, remoteAddr: "10.0.0.1:5000", forwarded: []string{"::ffff:203.0.113.5"}, want: "ip:203.0.113.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, _ := newTestLimiter(config.RateLimitConfig{TrustProxy: tt.trustProxy, TrustedProxies: tt.proxies})
+			r := request(tt.remoteAddr, nil)
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if got := limiter.ipKey(r); got != tt.want {
+				t.Errorf("ipKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPMiddleware(t *testing.T) {
+	limiter, _ := newTestLimiter(config.RateLimitConfig{PerIP: config.RateLimit{Rate: 1, Burst: 2}})
+	handler := limiter.IPMiddleware()(okHandler())
+
+	for i := 0; i < 2; i++ {
+		if w := serve(handler, request("192.0.2.1:1234", nil)); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, w.Code)
+		}
+	}
+	w := serve(handler, request("192.0.2.1:1234", nil))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("over per-IP limit: status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := serve(handler, request("192.0.2.2:1234", nil)); w.Code != http.StatusOK {
+		t.Fatalf("another IP: status = %d, want 200", w.Code)
+	}
+
+	disabled, _ := newTestLimiter(config.RateLimitConfig{})
+	handler = disabled.IPMiddleware()(okHandler())
+	for i := 0; i < 10; i++ {
+		if w := serve(handler, request("192.0.2.1:1234", nil)); w.Code != http.StatusOK {
+			t.Fatalf("disabled per-IP limit: status = %d", w.Code)
+		}
+	}
+}
+
+func TestSweepIdleBuckets(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{})
+	fast := config.RateLimit{Rate: 1, Burst: 2}
+	// Корзина с медленным пополнением не успевает заполниться за sweepInterval
+	slow := config.RateLimit{Rate: 0.001, Burst: 2}
+
+	limiter.take("idle", fast)
+	limiter.take("busy", slow)
+
+	// Первая очистка проходит сразу, следующая — не раньше чем через sweepInterval
+	clock.Advance(time.Second)
+	limiter.take("other", fast)
+	if len(limiter.buckets) != 3 {
+		t.Fatalf("sweep must wait for sweepInterval, got %d buckets", len(limiter.buckets))
+	}
+
+	clock.Advance(sweepInterval)
+	limiter.sweep(clock.Now())
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Error("refilled bucket must be swept")
+	}
+	if _, ok := limiter.buckets["other"]; ok {
+		t.Error("refilled bucket must be swept")
+	}
+	if _, ok := limiter.buckets["busy"]; !ok {
+		t.Error("partially used bucket must be kept")
+	}
+}
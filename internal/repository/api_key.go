@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *model.APIKey, hash string) (*model.APIKey, error)
+	GetActiveByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	List(ctx context.Context) ([]*model.APIKey, error)
+	Rotate(ctx context.Context, id string, prefix, hash string) (*model.APIKey, error)
+	Revoke(ctx context.Context, id string) error
+	TouchLastUsed(ctx context.Context, id int) error
+}
+
+type apiKeyRepo struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+const apiKeyColumns = `id, name, prefix, scopes, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*model.APIKey, error) {
+	var key model.APIKey
+	var scopes string
+	var lastUsedAt, revokedAt sql.NullTime
+
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &scopes, &key.CreatedAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+
+	key.Scopes = strings.Fields(scopes)
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *model.APIKey, hash string) (*model.APIKey, error) {
+	query := `INSERT INTO api_keys (name, prefix, key_hash, scopes, created_at)
+	VALUES ($1, $2, $3, $4, $5) RETURNING ` + apiKeyColumns
+
+	created, err := scanAPIKey(r.db.QueryRowContext(ctx, query,
+		key.Name, key.Prefix, hash, strings.Join(key.Scopes, " "), time.Now().UTC()))
+	if err != nil {
+		log.Printf("Error creating API key: %v", err)
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	log.Printf("API key created: %d (%s)", created.ID, created.Name)
+	return created, nil
+}
+
+func (r *apiKeyRepo) GetActiveByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys
+	WHERE key_hash = $1 AND revoked_at IS NULL`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting API key: %v", err)
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepo) List(ctx context.Context) ([]*model.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Error listing API keys: %v", err)
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *apiKeyRepo) Rotate(ctx context.Context, id string, prefix, hash string) (*model.APIKey, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	query := `UPDATE api_keys SET prefix = $1, key_hash = $2, last_used_at = NULL
+	WHERE id = $3 AND revoked_at IS NULL RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, prefix, hash, idInt))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		log.Printf("Error rotating API key: %v", err)
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	log.Printf("API key rotated: %s", id)
+	return key, nil
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, id string) error {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC(), idInt)
+	if err != nil {
+		log.Printf("Error revoking API key: %v", err)
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	log.Printf("API key revoked: %s", id)
+	return nil
+}
+
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id int) error {
+	query := `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("failed to update API key usage: %w", err)
+	}
+	return nil
+}
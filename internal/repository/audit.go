@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+const (
+	auditCreate  = "create"
+	auditUpdate  = "update"
+	auditDelete  = "delete"
+	auditRestore = "restore"
+	auditRenew   = "renew"
+)
+
+// writeAudit записывает изменение подписки в журнал в рамках транзакции изменения.
+// Запись относится к владельцу подписки после изменения (до него — для удаления).
+func writeAudit(ctx context.Context, tx querier, operation string, before, after *model.Subscription) error {
+	query := `INSERT INTO subscription_audit
+    (subscription_id, user_id, operation, actor, changed_at, before_data, after_data)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	owner := after
+	if owner == nil {
+		owner = before
+	}
+
+	beforeData, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterData, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, owner.ID, owner.UserID, operation, auth.Actor(ctx),
+		time.Now().UTC(), beforeData, afterData)
+	if err != nil {
+		log.Printf("Error writing subscription audit: %v", err)
+		return fmt.Errorf("failed to write subscription audit: %w", err)
+	}
+	return nil
+}
+
+func auditSnapshot(sub *model.Subscription) (interface{}, error) {
+	if sub == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	return string(data), nil
+}
+
+func (r *subscriptionRepo) History(ctx context.Context, id string) ([]*model.SubscriptionAuditEntry, error) {
+	query := `SELECT id, subscription_id, operation, actor, changed_at, before_data, after_data
+    FROM subscription_audit WHERE subscription_id = $1`
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	query, args := scopeCondition(ctx, query, []interface{}{idInt})
+	rows, err := r.conn().QueryContext(ctx, query+" ORDER BY changed_at, id", args...)
+	if err != nil {
+		log.Printf("Error reading subscription history: %v", err)
+		return nil, fmt.Errorf("failed to read subscription history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.SubscriptionAuditEntry
+	for rows.Next() {
+		var entry model.SubscriptionAuditEntry
+		var before, after sql.NullString
+
+		if err := rows.Scan(&entry.ID, &entry.SubscriptionID, &entry.Operation, &entry.Actor,
+			&entry.ChangedAt, &before, &after); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if before.Valid {
+			entry.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			entry.After = json.RawMessage(after.String)
+		}
+
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subscription history: %w", err)
+	}
+
+	// Подписки, созданные до появления журнала, существуют без истории
+	if len(entries) == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		entries = []*model.SubscriptionAuditEntry{}
+	}
+
+	log.Printf("Subscription history retrieved: %s (%d entries)", id, len(entries))
+	return entries, nil
+}
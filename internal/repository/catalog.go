@@ -0,0 +1,356 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+var (
+	ErrServiceNotFound = errors.New("service not found")
+	ErrServiceConflict = errors.New("service name or alias is already used by another service")
+	ErrServiceInUse    = errors.New("service is referenced by subscriptions")
+)
+
+// ServiceCatalogRepository хранит каталог сервисов. Название и псевдонимы сервиса
+// уникальны в пределах каталога без учета регистра и лишних пробелов.
+type ServiceCatalogRepository interface {
+	// Create добавляет сервис и привязывает к нему подписки без сервиса,
+	// чье название совпадает с названием или псевдонимом.
+	Create(ctx context.Context, svc *model.Service) (*model.Service, error)
+	GetByID(ctx context.Context, id string) (*model.Service, error)
+	// Resolve находит сервис по названию или псевдониму
+	Resolve(ctx context.Context, name string) (*model.Service, error)
+	List(ctx context.Context) ([]*model.Service, error)
+	// Update заменяет сервис целиком. Новое название переносится в привязанные
+	// подписки, подписки без сервиса привязываются, как при Create.
+	Update(ctx context.Context, id string, svc *model.Service) (*model.Service, error)
+	// Delete удаляет сервис; сервис, на который ссылаются подписки, удалить нельзя
+	Delete(ctx context.Context, id string) error
+}
+
+// NormalizeServiceName приводит название к виду, по которому сравниваются
+// названия и псевдонимы: нижний регистр, одиночные пробелы.
+func NormalizeServiceName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// serviceLookups возвращает нормализованные название и псевдонимы без повторов
+func serviceLookups(svc *model.Service) []string {
+	seen := make(map[string]bool)
+	var lookups []string
+	for _, name := range append([]string{svc.Name}, svc.Aliases...) {
+		lookup := NormalizeServiceName(name)
+		if lookup != "" && !seen[lookup] {
+			seen[lookup] = true
+			lookups = append(lookups, lookup)
+		}
+	}
+	return lookups
+}
+
+type serviceCatalogRepo struct {
+	db *sql.DB
+}
+
+// NewServiceCatalogRepository создает каталог поверх PostgreSQL или SQLite
+func NewServiceCatalogRepository(db *sql.DB) ServiceCatalogRepository {
+	return &serviceCatalogRepo{db: db}
+}
+
+const serviceColumns = `id, name, aliases, category, plans, created_at`
+
+func scanService(row interface{ Scan(...interface{}) error }) (*model.Service, error) {
+	var svc model.Service
+	var aliases, plans string
+	if err := row.Scan(&svc.ID, &svc.Name, &aliases, &svc.Category, &plans, &svc.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(aliases), &svc.Aliases); err != nil {
+		return nil, fmt.Errorf("failed to decode service aliases: %w", err)
+	}
+	if err := json.Unmarshal([]byte(plans), &svc.Plans); err != nil {
+		return nil, fmt.Errorf("failed to decode service plans: %w", err)
+	}
+	return &svc, nil
+}
+
+// encodeService возвращает псевдонимы и тарифы сервиса в виде JSON для хранения
+func encodeService(svc *model.Service) (aliases, plans string, err error) {
+	aliasData, err := json.Marshal(append([]string{}, svc.Aliases...))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode service aliases: %w", err)
+	}
+	planData, err := json.Marshal(append([]model.ServicePlan{}, svc.Plans...))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode service plans: %w", err)
+	}
+	return string(aliasData), string(planData), nil
+}
+
+func (r *serviceCatalogRepo) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// storeLookups проверяет, что название и псевдонимы не заняты другим сервисом,
+// и записывает их для сервиса serviceID
+func storeLookups(ctx context.Context, tx querier, serviceID int, lookups []string) error {
+	for _, lookup := range lookups {
+		var owner int
+		err := tx.QueryRowContext(ctx, `SELECT service_id FROM service_lookup WHERE lookup = $1`, lookup).Scan(&owner)
+		if err == nil && owner != serviceID {
+			return fmt.Errorf("%w: %q", ErrServiceConflict, lookup)
+		}
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check service name: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM service_lookup WHERE service_id = $1`, serviceID); err != nil {
+		return fmt.Errorf("failed to update service names: %w", err)
+	}
+	for _, lookup := range lookups {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO service_lookup (lookup, service_id) VALUES ($1, $2)`, lookup, serviceID); err != nil {
+			return fmt.Errorf("failed to update service names: %w", err)
+		}
+	}
+	return nil
+}
+
+// linkSubscriptions привязывает к сервису подписки без сервиса, чье название
+// совпадает с одним из lookups, и приводит их название к каноническому.
+// Сравнение выполняется в Go: lower() в SQLite не поддерживает кириллицу.
+func linkSubscriptions(ctx context.Context, tx querier, svc *model.Service, lookups []string) error {
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT service_name FROM subscriptions WHERE service_id IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to find unlinked subscriptions: %w", err)
+	}
+
+	known := make(map[string]bool, len(lookups))
+	for _, lookup := range lookups {
+		known[lookup] = true
+	}
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan subscription name: %w", err)
+		}
+		if known[NormalizeServiceName(name)] {
+			names = append(names, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to find unlinked subscriptions: %w", err)
+	}
+
+	var linked int64
+	for _, name := range names {
+		result, err := tx.ExecContext(ctx, `UPDATE subscriptions SET service_id = $1, service_name = $2,
+        category = CASE WHEN custom_category THEN category ELSE $3 END
+        WHERE service_id IS NULL AND service_name = $4`, svc.ID, svc.Name, svc.Category, name)
+		if err != nil {
+			return fmt.Errorf("failed to link subscriptions: %w", err)
+		}
+		count, _ := result.RowsAffected()
+		linked += count
+	}
+
+	if linked > 0 {
+		log.Printf("Linked %d subscriptions to service %d (%s)", linked, svc.ID, svc.Name)
+	}
+	return nil
+}
+
+func (r *serviceCatalogRepo) Create(ctx context.Context, svc *model.Service) (*model.Service, error) {
+	query := `INSERT INTO services (name, aliases, category, plans, created_at)
+    VALUES ($1, $2, $3, $4, $5) RETURNING ` + serviceColumns
+
+	aliases, plans, err := encodeService(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *model.Service
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		created, err = scanService(tx.QueryRowContext(ctx, query, svc.Name, aliases, svc.Category, plans, time.Now().UTC()))
+		if err != nil {
+			log.Printf("Error creating service: %v", err)
+			return fmt.Errorf("failed to create service: %w", err)
+		}
+
+		lookups := serviceLookups(created)
+		if err := storeLookups(ctx, tx, created.ID, lookups); err != nil {
+			return err
+		}
+		return linkSubscriptions(ctx, tx, created, lookups)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Service created: %d (%s)", created.ID, created.Name)
+	return created, nil
+}
+
+func (r *serviceCatalogRepo) GetByID(ctx context.Context, id string) (*model.Service, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	svc, err := scanService(r.db.QueryRowContext(ctx, `SELECT `+serviceColumns+` FROM services WHERE id = $1`, idInt))
+	if err == sql.ErrNoRows {
+		return nil, ErrServiceNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting service: %v", err)
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	return svc, nil
+}
+
+func (r *serviceCatalogRepo) Resolve(ctx context.Context, name string) (*model.Service, error) {
+	query := `SELECT ` + serviceColumns + ` FROM services
+    WHERE id = (SELECT service_id FROM service_lookup WHERE lookup = $1)`
+
+	svc, err := scanService(r.db.QueryRowContext(ctx, query, NormalizeServiceName(name)))
+	if err == sql.ErrNoRows {
+		return nil, ErrServiceNotFound
+	}
+	if err != nil {
+		log.Printf("Error resolving service: %v", err)
+		return nil, fmt.Errorf("failed to resolve service: %w", err)
+	}
+	return svc, nil
+}
+
+func (r *serviceCatalogRepo) List(ctx context.Context) ([]*model.Service, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+serviceColumns+` FROM services ORDER BY id`)
+	if err != nil {
+		log.Printf("Error listing services: %v", err)
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	defer rows.Close()
+
+	services := []*model.Service{}
+	for rows.Next() {
+		svc, err := scanService(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan service: %w", err)
+		}
+		services = append(services, svc)
+	}
+
+	return services, rows.Err()
+}
+
+func (r *serviceCatalogRepo) Update(ctx context.Context, id string, svc *model.Service) (*model.Service, error) {
+	query := `UPDATE services SET name = $1, aliases = $2, category = $3, plans = $4
+    WHERE id = $5 RETURNING ` + serviceColumns
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	aliases, plans, err := encodeService(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *model.Service
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		updated, err = scanService(tx.QueryRowContext(ctx, query, svc.Name, aliases, svc.Category, plans, idInt))
+		if err == sql.ErrNoRows {
+			return ErrServiceNotFound
+		}
+		if err != nil {
+			log.Printf("Error updating service: %v", err)
+			return fmt.Errorf("failed to update service: %w", err)
+		}
+
+		lookups := serviceLookups(updated)
+		if err := storeLookups(ctx, tx, updated.ID, lookups); err != nil {
+			return err
+		}
+
+		// Переименование и смена категории сервиса не меняют сами подписки
+		// и поэтому не попадают в их журнал
+		if _, err := tx.ExecContext(ctx, `UPDATE subscriptions SET service_name = $1,
+        category = CASE WHEN custom_category THEN category ELSE $2 END WHERE service_id = $3`,
+			updated.Name, updated.Category, updated.ID); err != nil {
+			return fmt.Errorf("failed to rename subscriptions: %w", err)
+		}
+		return linkSubscriptions(ctx, tx, updated, lookups)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Service updated: %s", id)
+	return updated, nil
+}
+
+func (r *serviceCatalogRepo) Delete(ctx context.Context, id string) error {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		var inUse bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM subscriptions WHERE service_id = $1)`, idInt).Scan(&inUse)
+		if err != nil {
+			return fmt.Errorf("failed to check service usage: %w", err)
+		}
+		if inUse {
+			return ErrServiceInUse
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM service_lookup WHERE service_id = $1`, idInt); err != nil {
+			return fmt.Errorf("failed to delete service: %w", err)
+		}
+		result, err := tx.ExecContext(ctx, `DELETE FROM services WHERE id = $1`, idInt)
+		if err != nil {
+			log.Printf("Error deleting service: %v", err)
+			return fmt.Errorf("failed to delete service: %w", err)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return ErrServiceNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Service deleted: %s", id)
+	return nil
+}
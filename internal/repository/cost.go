@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// billedCycle — оплачиваемый период подписки: пробный период целиком или месяц
+// по обычной цене. Стоимость периода учитывается в месяце, в котором он начался.
+type billedCycle struct {
+	sub      *model.Subscription
+	start    time.Time
+	cost     int
+	discount int
+}
+
+// costPeriod возвращает границы расчета [start, end) от начала start_period
+// до конца end_period в часовом поясе пользователя
+func costPeriod(req *model.CalculateCostRequest) (time.Time, time.Time, error) {
+	start, _, err := model.MonthRange(req.StartPeriod, req.Location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start_period format: %w", err)
+	}
+
+	_, end, err := model.MonthRange(req.EndPeriod, req.Location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end_period format: %w", err)
+	}
+	return start, end, nil
+}
+
+// pauseInterval — интервал [start, end) приостановки подписки; end нулевой, пока она не возобновлена
+type pauseInterval struct {
+	start, end time.Time
+}
+
+// pauseIntervals восстанавливает интервалы приостановки по переходам состояний
+func pauseIntervals(sub *model.Subscription) []pauseInterval {
+	var intervals []pauseInterval
+	paused := false
+	for _, transition := range sub.Transitions {
+		switch {
+		case transition.To == model.StatusPaused:
+			intervals = append(intervals, pauseInterval{start: transition.EffectiveAt})
+			paused = true
+		case transition.From == model.StatusPaused && paused:
+			intervals[len(intervals)-1].end = transition.EffectiveAt
+			paused = false
+		}
+	}
+	return intervals
+}
+
+// billable сообщает, выставляется ли счет за период, начавшийся в start:
+// период не должен начаться во время приостановки или после отмены подписки
+func billable(sub *model.Subscription, pauses []pauseInterval, start time.Time) bool {
+	if sub.CancelAt != nil && !start.Before(*sub.CancelAt) {
+		return false
+	}
+	for _, pause := range pauses {
+		if !start.Before(pause.start) && (pause.end.IsZero() || start.Before(pause.end)) {
+			return false
+		}
+	}
+	return true
+}
+
+// subscriptionCycles возвращает расчетные периоды подписки, начавшиеся в [from, to).
+// Пробный период оплачивается по TrialPrice без скидок, затем каждый месяц до EndDate —
+// по Price за вычетом действующих в нем скидок. Скидка с Cycles считает периоды
+// с начала своего действия, в том числе начавшиеся до from. Периоды, начавшиеся
+// во время приостановки или после отмены, не оплачиваются и не расходуют скидки.
+func subscriptionCycles(sub *model.Subscription, from, to time.Time, loc *time.Location) []billedCycle {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var cycles []billedCycle
+	pauses := pauseIntervals(sub)
+	paidStart := sub.StartDate
+	if sub.TrialEndsAt != nil {
+		if !sub.StartDate.Before(from) && sub.StartDate.Before(to) && billable(sub, pauses, sub.StartDate) {
+			cycles = append(cycles, billedCycle{sub: sub, start: sub.StartDate, cost: sub.TrialPrice})
+		}
+		paidStart = *sub.TrialEndsAt
+	}
+
+	applied := make([]int, len(sub.Discounts))
+	local := paidStart.In(loc)
+	for i := 0; ; i++ {
+		start := local.AddDate(0, i, 0).UTC()
+		if !start.Before(sub.EndDate) || !start.Before(to) {
+			break
+		}
+		if !billable(sub, pauses, start) {
+			continue
+		}
+
+		discount := 0
+		for j, d := range sub.Discounts {
+			if start.Before(d.StartsAt) || (d.EndsAt != nil && !start.Before(*d.EndsAt)) {
+				continue
+			}
+			if d.Cycles > 0 && applied[j] >= d.Cycles {
+				continue
+			}
+			applied[j]++
+			discount += d.Apply(sub.Price)
+		}
+		discount = min(discount, sub.Price)
+
+		if !start.Before(from) {
+			cycles = append(cycles, billedCycle{sub: sub, start: start, cost: sub.Price - discount, discount: discount})
+		}
+	}
+	return cycles
+}
+
+// billedCycles возвращает расчетные периоды подписок, начавшиеся в периоде req.
+// С точки зрения участника стоимость и скидка каждого периода заменяются его долей.
+func billedCycles(subs []*model.Subscription, req *model.CalculateCostRequest) ([]billedCycle, error) {
+	from, to, err := costPeriod(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID uuid.UUID
+	if req.Perspective == model.PerspectiveMember {
+		if userID, err = uuid.Parse(req.UserID); err != nil {
+			return nil, fmt.Errorf("invalid user_id format: %w", err)
+		}
+	}
+
+	var cycles []billedCycle
+	for _, sub := range subs {
+		for _, cycle := range subscriptionCycles(sub, from, to, req.Location) {
+			if req.Perspective == model.PerspectiveMember {
+				share := costShares(sub, cycle.cost)[userID]
+				full := costShares(sub, cycle.cost+cycle.discount)[userID]
+				cycle.cost, cycle.discount = share, max(full-share, 0)
+			}
+			cycles = append(cycles, cycle)
+		}
+	}
+	return cycles, nil
+}
+
+// costShares делит стоимость периода между владельцем и участниками подписки.
+// Сначала вычитаются фиксированные суммы участников в порядке списка, каждая
+// не больше остатка; остаток делится пропорционально весам с округлением вниз.
+// Владелец участвует с весом 1, если не указан среди участников, и получает
+// остаток от округления, а при нулевой сумме весов — весь остаток.
+func costShares(sub *model.Subscription, cost int) map[uuid.UUID]int {
+	shares := map[uuid.UUID]int{sub.UserID: 0}
+	remaining := cost
+	ownerWeight := 1
+	for _, member := range sub.Members {
+		if member.UserID == sub.UserID {
+			ownerWeight = 0
+		}
+		if member.Amount != nil {
+			amount := min(max(*member.Amount, 0), remaining)
+			shares[member.UserID] += amount
+			remaining -= amount
+		}
+	}
+
+	totalWeight := ownerWeight
+	for _, member := range sub.Members {
+		if member.Amount == nil {
+			totalWeight += member.Weight
+		}
+	}
+	if totalWeight <= 0 {
+		shares[sub.UserID] += remaining
+		return shares
+	}
+
+	distributed := 0
+	for _, member := range sub.Members {
+		if member.Amount != nil || member.Weight <= 0 {
+			continue
+		}
+		share := remaining * member.Weight / totalWeight
+		shares[member.UserID] += share
+		distributed += share
+	}
+	shares[sub.UserID] += remaining - distributed
+	return shares
+}
+
+func sumCycles(cycles []billedCycle) int {
+	total := 0
+	for _, cycle := range cycles {
+		total += cycle.cost
+	}
+	return total
+}
+
+// groupCycles суммирует стоимость и скидки по категориям, тегам или месяцам
+// начала периодов. Подписка с несколькими тегами входит в группу каждого из них.
+func groupCycles(cycles []billedCycle, groupBy string, loc *time.Location) ([]*model.CostGroup, error) {
+	if groupBy != model.GroupByCategory && groupBy != model.GroupByTag && groupBy != model.GroupByMonth {
+		return nil, fmt.Errorf("unknown group_by %q", groupBy)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	groups := make(map[string]*model.CostGroup)
+	for _, cycle := range cycles {
+		var keys []string
+		switch groupBy {
+		case model.GroupByCategory:
+			keys = []string{cycle.sub.Category}
+		case model.GroupByTag:
+			keys = cycle.sub.Tags
+			if len(keys) == 0 {
+				keys = []string{""}
+			}
+		case model.GroupByMonth:
+			keys = []string{cycle.start.In(loc).Format(model.MonthLayout)}
+		}
+
+		for _, key := range keys {
+			group, ok := groups[key]
+			if !ok {
+				group = &model.CostGroup{Key: key}
+				groups[key] = group
+			}
+			group.TotalCost += cycle.cost
+			group.Discount += cycle.discount
+		}
+	}
+
+	keys := slices.Sorted(maps.Keys(groups))
+	if groupBy == model.GroupByMonth {
+		// MM-YYYY не сортируется как строка, поэтому месяцы упорядочиваются по дате
+		slices.SortFunc(keys, func(a, b string) int {
+			return monthKeyTime(a).Compare(monthKeyTime(b))
+		})
+	}
+
+	result := make([]*model.CostGroup, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, groups[key])
+	}
+	return result, nil
+}
+
+func monthKeyTime(key string) time.Time {
+	parsed, _ := time.Parse(model.MonthLayout, key)
+	return parsed
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+var ErrDiscountNotFound = errors.New("discount not found")
+
+const discountColumns = `id, subscription_id, kind, value, starts_at, ends_at, cycles, description, created_at`
+
+// loadDetails заполняет теги, скидки, участников и переходы состояний подписок
+func loadDetails(ctx context.Context, q querier, subs ...*model.Subscription) error {
+	if err := loadTags(ctx, q, subs...); err != nil {
+		return err
+	}
+	if err := loadDiscounts(ctx, q, subs...); err != nil {
+		return err
+	}
+	if err := loadMembers(ctx, q, subs...); err != nil {
+		return err
+	}
+	return loadTransitions(ctx, q, subs...)
+}
+
+// loadDiscounts заполняет скидки подписок одним запросом в порядке добавления
+func loadDiscounts(ctx context.Context, q querier, subs ...*model.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*model.Subscription, len(subs))
+	placeholders := make([]string, 0, len(subs))
+	args := make([]interface{}, 0, len(subs))
+	for _, sub := range subs {
+		sub.Discounts = nil
+		byID[sub.ID] = sub
+		args = append(args, sub.ID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	query := `SELECT ` + discountColumns + ` FROM subscription_discounts
+    WHERE subscription_id IN (` + strings.Join(placeholders, ", ") + `) ORDER BY id`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to load subscription discounts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subscriptionID int
+		discount, err := scanDiscount(rows, &subscriptionID)
+		if err != nil {
+			return fmt.Errorf("failed to scan subscription discount: %w", err)
+		}
+		if sub, ok := byID[subscriptionID]; ok {
+			sub.Discounts = append(sub.Discounts, discount)
+		}
+	}
+	return rows.Err()
+}
+
+func scanDiscount(row interface{ Scan(...interface{}) error }, subscriptionID *int) (*model.Discount, error) {
+	var discount model.Discount
+	var endsAt sql.NullTime
+	if err := row.Scan(&discount.ID, subscriptionID, &discount.Kind, &discount.Value, &discount.StartsAt, &endsAt,
+		&discount.Cycles, &discount.Description, &discount.CreatedAt); err != nil {
+		return nil, err
+	}
+	if endsAt.Valid {
+		discount.EndsAt = &endsAt.Time
+	}
+	return &discount, nil
+}
+
+// AddDiscount добавляет скидку к подписке и записывает изменение в журнал
+func (r *subscriptionRepo) AddDiscount(ctx context.Context, id string, discount *model.Discount) (*model.Discount, error) {
+	query := `INSERT INTO subscription_discounts (subscription_id, kind, value, starts_at, ends_at, cycles, description, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING ` + discountColumns
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	var created *model.Discount
+	err = r.inTx(ctx, func(tx *subscriptionRepo) error {
+		before, err := tx.lockSubscription(ctx, idInt, false)
+		if err != nil {
+			return err
+		}
+
+		var subscriptionID int
+		created, err = scanDiscount(tx.conn().QueryRowContext(ctx, query, idInt, discount.Kind, discount.Value,
+			discount.StartsAt, discount.EndsAt, discount.Cycles, discount.Description, time.Now().UTC()), &subscriptionID)
+		if err != nil {
+			log.Printf("Error adding discount: %v", err)
+			return fmt.Errorf("failed to add discount: %w", err)
+		}
+
+		after := *before
+		after.Discounts = append(append([]*model.Discount(nil), before.Discounts...), created)
+		return writeAudit(ctx, tx.conn(), auditUpdate, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Discount %d added to subscription %s", created.ID, id)
+	return created, nil
+}
+
+// RemoveDiscount удаляет скидку подписки и записывает изменение в журнал
+func (r *subscriptionRepo) RemoveDiscount(ctx context.Context, id string, discountID int) error {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	err = r.inTx(ctx, func(tx *subscriptionRepo) error {
+		before, err := tx.lockSubscription(ctx, idInt, false)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.conn().ExecContext(ctx, `DELETE FROM subscription_discounts WHERE id = $1 AND subscription_id = $2`,
+			discountID, idInt)
+		if err != nil {
+			log.Printf("Error removing discount: %v", err)
+			return fmt.Errorf("failed to remove discount: %w", err)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return ErrDiscountNotFound
+		}
+
+		after := *before
+		after.Discounts = nil
+		for _, discount := range before.Discounts {
+			if discount.ID != discountID {
+				after.Discounts = append(after.Discounts, discount)
+			}
+		}
+		return writeAudit(ctx, tx.conn(), auditUpdate, before, &after)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Discount %d removed from subscription %s", discountID, id)
+	return nil
+}
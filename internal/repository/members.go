@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// loadMembers заполняет участников подписок одним запросом
+func loadMembers(ctx context.Context, q querier, subs ...*model.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*model.Subscription, len(subs))
+	placeholders := make([]string, 0, len(subs))
+	args := make([]interface{}, 0, len(subs))
+	for _, sub := range subs {
+		sub.Members = nil
+		byID[sub.ID] = sub
+		args = append(args, sub.ID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	query := `SELECT subscription_id, user_id, weight, amount FROM subscription_members
+    WHERE subscription_id IN (` + strings.Join(placeholders, ", ") + `) ORDER BY user_id`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to load subscription members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var member model.SubscriptionMember
+		var amount sql.NullInt64
+		if err := rows.Scan(&id, &member.UserID, &member.Weight, &amount); err != nil {
+			return fmt.Errorf("failed to scan subscription member: %w", err)
+		}
+		if amount.Valid {
+			value := int(amount.Int64)
+			member.Amount = &value
+		}
+		if sub, ok := byID[id]; ok {
+			sub.Members = append(sub.Members, member)
+		}
+	}
+	return rows.Err()
+}
+
+// replaceMembers заменяет участников подписки; каждый участник должен существовать
+func replaceMembers(ctx context.Context, q querier, id int, members []model.SubscriptionMember) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM subscription_members WHERE subscription_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update subscription members: %w", err)
+	}
+	for _, member := range members {
+		if err := checkUserExists(ctx, q, member.UserID); err != nil {
+			return fmt.Errorf("failed to update subscription members: %w", err)
+		}
+		_, err := q.ExecContext(ctx, `INSERT INTO subscription_members (subscription_id, user_id, weight, amount)
+        VALUES ($1, $2, $3, $4)`, id, member.UserID, member.Weight, member.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to update subscription members: %w", err)
+		}
+	}
+	return nil
+}
+
+// visibleCondition дополняет запрос условием видимости для ограниченного вызывающего:
+// ему доступны свои подписки и общие подписки, в которых он участвует.
+// Изменять подписку может только владелец (scopeCondition).
+func visibleCondition(ctx context.Context, query string, args []interface{}) (string, []interface{}) {
+	userID, scoped := auth.UserScope(ctx)
+	if !scoped {
+		return query, args
+	}
+	args = append(args, userID)
+	return query + fmt.Sprintf(` AND (user_id = $%d OR id IN (SELECT subscription_id FROM subscription_members
+    WHERE user_id = $%d))`, len(args), len(args)), args
+}
+
+// isMember сообщает, участвует ли пользователь в подписке как владелец или участник
+func isMember(sub *model.Subscription, userID uuid.UUID) bool {
+	if sub.UserID == userID {
+		return true
+	}
+	for _, member := range sub.Members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
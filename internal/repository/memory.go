@@ -0,0 +1,788 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/google/uuid"
+)
+
+type memoryAuditEntry struct {
+	entry  model.SubscriptionAuditEntry
+	userID uuid.UUID
+}
+
+// memoryState — данные хранилища. Сохраненные подписки и сервисы не изменяются на месте,
+// а заменяются копиями, поэтому для отката транзакции достаточно поверхностной копии.
+type memoryState struct {
+	subscriptions map[int]*model.Subscription
+	audit         []memoryAuditEntry
+	nextID        int
+	nextAuditID   int64
+	// nextDiscountID — общий счетчик скидок; скидки хранятся в самих подписках
+	nextDiscountID int
+	// nextTransitionID — общий счетчик переходов состояний, хранящихся в подписках
+	nextTransitionID int
+
+	services      map[int]*model.Service
+	serviceLookup map[string]int
+	nextServiceID int
+
+	users map[uuid.UUID]*model.User
+
+	preferences    map[uuid.UUID]*model.NotificationPreferences
+	deliveries     map[int64]*model.NotificationDelivery
+	nextDeliveryID int64
+
+	webhooks              map[int]*model.Webhook
+	webhookDeliveries     map[int64]*model.WebhookDelivery
+	nextWebhookID         int
+	nextWebhookDeliveryID int64
+}
+
+func (s *memoryState) snapshot() memoryState {
+	clone := *s
+	clone.subscriptions = maps.Clone(s.subscriptions)
+	clone.services = maps.Clone(s.services)
+	clone.serviceLookup = maps.Clone(s.serviceLookup)
+	clone.users = maps.Clone(s.users)
+	clone.preferences = maps.Clone(s.preferences)
+	clone.deliveries = maps.Clone(s.deliveries)
+	clone.webhooks = maps.Clone(s.webhooks)
+	clone.webhookDeliveries = maps.Clone(s.webhookDeliveries)
+	return clone
+}
+
+// memorySubscriptionRepo хранит подписки в памяти процесса. Поведение совпадает
+// с subscriptionRepo, включая ограничение по пользователю, мягкое удаление и журнал.
+type memorySubscriptionRepo struct {
+	mu    *sync.RWMutex
+	state *memoryState
+	// inTx выставлен у хранилища, переданного в fn из WithTx: блокировка уже взята
+	inTx bool
+}
+
+func NewMemorySubscriptionRepository() SubscriptionRepository {
+	return &memorySubscriptionRepo{
+		mu: &sync.RWMutex{},
+		state: &memoryState{
+			subscriptions:    make(map[int]*model.Subscription),
+			nextID:           1,
+			nextAuditID:      1,
+			nextDiscountID:   1,
+			nextTransitionID: 1,
+			services:         make(map[int]*model.Service),
+			serviceLookup:    make(map[string]int),
+			nextServiceID:    1,
+			users:            make(map[uuid.UUID]*model.User),
+			preferences:      make(map[uuid.UUID]*model.NotificationPreferences),
+			deliveries:       make(map[int64]*model.NotificationDelivery),
+			nextDeliveryID:   1,
+
+			webhooks:              make(map[int]*model.Webhook),
+			webhookDeliveries:     make(map[int64]*model.WebhookDelivery),
+			nextWebhookID:         1,
+			nextWebhookDeliveryID: 1,
+		},
+	}
+}
+
+// lock берет блокировку на запись и возвращает функцию ее снятия.
+// Внутри WithTx блокировка уже удерживается транзакцией.
+func (r *memorySubscriptionRepo) lock() func() {
+	if r.inTx {
+		return func() {}
+	}
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+func (r *memorySubscriptionRepo) rlock() func() {
+	if r.inTx {
+		return func() {}
+	}
+	r.mu.RLock()
+	return r.mu.RUnlock
+}
+
+// WithTx удерживает блокировку на запись все время выполнения fn,
+// а при ошибке восстанавливает состояние, сохраненное до начала.
+func (r *memorySubscriptionRepo) WithTx(ctx context.Context, fn func(repo SubscriptionRepository) error) error {
+	if r.inTx {
+		return fn(r)
+	}
+
+	defer r.lock()()
+
+	saved := r.state.snapshot()
+	if err := fn(&memorySubscriptionRepo{mu: r.mu, state: r.state, inTx: true}); err != nil {
+		*r.state = saved
+		return err
+	}
+	return nil
+}
+
+func (r *memorySubscriptionRepo) GetForUpdate(ctx context.Context, id string) (*model.Subscription, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memorySubscriptionRepo) OwnerLocation(ctx context.Context, userID uuid.UUID) (*time.Location, error) {
+	defer r.rlock()()
+
+	owner, ok := r.state.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	return time.LoadLocation(owner.Timezone)
+}
+
+func cloneSubscription(sub *model.Subscription) *model.Subscription {
+	clone := *sub
+	if sub.ServiceID != nil {
+		serviceID := *sub.ServiceID
+		clone.ServiceID = &serviceID
+	}
+	if sub.Tags != nil {
+		clone.Tags = append([]string(nil), sub.Tags...)
+	}
+	// Скидки не изменяются на месте, поэтому достаточно копии среза
+	if sub.Discounts != nil {
+		clone.Discounts = append([]*model.Discount(nil), sub.Discounts...)
+	}
+	if sub.Members != nil {
+		clone.Members = slices.Clone(sub.Members)
+	}
+	if sub.Transitions != nil {
+		clone.Transitions = slices.Clone(sub.Transitions)
+	}
+	if sub.CancelAt != nil {
+		cancelAt := *sub.CancelAt
+		clone.CancelAt = &cancelAt
+	}
+	if sub.TrialEndsAt != nil {
+		trialEndsAt := *sub.TrialEndsAt
+		clone.TrialEndsAt = &trialEndsAt
+	}
+	if sub.DeletedAt != nil {
+		deletedAt := *sub.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	return &clone
+}
+
+func inScope(ctx context.Context, userID uuid.UUID) bool {
+	scopeID, scoped := auth.UserScope(ctx)
+	return !scoped || scopeID == userID
+}
+
+// readable сообщает, может ли вызывающий читать подписку: владельцу и участникам
+// общая подписка доступна, изменять ее может только владелец (inScope)
+func readable(ctx context.Context, sub *model.Subscription) bool {
+	scopeID, scoped := auth.UserScope(ctx)
+	return !scoped || isMember(sub, scopeID)
+}
+
+// checkMembers проверяет, что участники подписки существуют; вызывается под блокировкой
+func (r *memorySubscriptionRepo) checkMembers(members []model.SubscriptionMember) error {
+	for _, member := range members {
+		if _, ok := r.state.users[member.UserID]; !ok {
+			return fmt.Errorf("failed to update subscription members: %w", ErrUserNotFound)
+		}
+	}
+	return nil
+}
+
+// refreshCategory подставляет подписке без своей категории категорию ее сервиса;
+// вызывается под блокировкой
+func (r *memorySubscriptionRepo) refreshCategory(sub *model.Subscription) {
+	if sub.CustomCategory {
+		return
+	}
+	sub.Category = ""
+	if sub.ServiceID != nil {
+		if svc, ok := r.state.services[*sub.ServiceID]; ok {
+			sub.Category = svc.Category
+		}
+	}
+}
+
+// lookup возвращает подписку, видимую вызывающему; вызывается под блокировкой
+func (r *memorySubscriptionRepo) lookup(ctx context.Context, id int, includeDeleted bool) (*model.Subscription, error) {
+	sub, ok := r.state.subscriptions[id]
+	if !ok || !inScope(ctx, sub.UserID) || (sub.DeletedAt != nil && !includeDeleted) {
+		return nil, ErrNotFound
+	}
+	return sub, nil
+}
+
+// writeAudit добавляет запись в журнал; вызывается под блокировкой
+func (r *memorySubscriptionRepo) writeAudit(ctx context.Context, operation string, before, after *model.Subscription) error {
+	owner := after
+	if owner == nil {
+		owner = before
+	}
+
+	entry := model.SubscriptionAuditEntry{
+		ID:             r.state.nextAuditID,
+		SubscriptionID: owner.ID,
+		Operation:      operation,
+		Actor:          auth.Actor(ctx),
+		ChangedAt:      time.Now().UTC(),
+	}
+	for _, snapshot := range []struct {
+		sub    *model.Subscription
+		target *json.RawMessage
+	}{{before, &entry.Before}, {after, &entry.After}} {
+		if snapshot.sub == nil {
+			continue
+		}
+		data, err := json.Marshal(snapshot.sub)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit snapshot: %w", err)
+		}
+		*snapshot.target = data
+	}
+
+	r.state.nextAuditID++
+	r.state.audit = append(r.state.audit, memoryAuditEntry{entry: entry, userID: owner.UserID})
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Create(ctx context.Context, sub *model.Subscription) (*model.Subscription, error) {
+	defer r.lock()()
+
+	created := cloneSubscription(sub)
+	created.ID = r.state.nextID
+	created.DeletedAt = nil
+	created.CustomCategory = created.Category != ""
+	created.Status = model.StatusActive
+	created.CancelAt = nil
+	created.Transitions = nil
+
+	if _, ok := r.state.users[created.UserID]; !ok {
+		return nil, fmt.Errorf("failed to create subscription: %w", ErrUserNotFound)
+	}
+	if created.ServiceID != nil {
+		if _, ok := r.state.services[*created.ServiceID]; !ok {
+			return nil, fmt.Errorf("failed to create subscription: %w", ErrServiceNotFound)
+		}
+	}
+	if err := r.checkMembers(created.Members); err != nil {
+		return nil, err
+	}
+	r.refreshCategory(created)
+
+	if err := r.writeAudit(ctx, auditCreate, nil, created); err != nil {
+		return nil, err
+	}
+
+	r.state.nextID++
+	r.state.subscriptions[created.ID] = created
+
+	sub.ID = created.ID
+	sub.Category = created.Category
+	sub.CustomCategory = created.CustomCategory
+	sub.Status = created.Status
+	log.Printf("Subscription created successfully: %d", sub.ID)
+	return sub, nil
+}
+
+func (r *memorySubscriptionRepo) GetByID(ctx context.Context, id string) (*model.Subscription, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	defer r.rlock()()
+
+	sub, ok := r.state.subscriptions[idInt]
+	if !ok || sub.DeletedAt != nil || !readable(ctx, sub) {
+		return nil, ErrNotFound
+	}
+
+	log.Printf("Subscription retrieved: %s", id)
+	return cloneSubscription(sub), nil
+}
+
+func (r *memorySubscriptionRepo) Update(ctx context.Context, id string, req *model.UpdateSubscriptionRequest) error {
+	var startDate time.Time
+	if req.StartDate != nil && *req.StartDate != "" {
+		var err error
+		startDate, _, err = model.MonthRange(*req.StartDate, req.Location)
+		if err != nil {
+			return fmt.Errorf("invalid start_date format: %w", err)
+		}
+	}
+
+	var userID *uuid.UUID
+	if req.UserID != nil && *req.UserID != "" {
+		parsed, err := uuid.Parse(*req.UserID)
+		if err != nil {
+			return fmt.Errorf("invalid user_id format")
+		}
+		userID = &parsed
+	}
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	defer r.lock()()
+
+	current, err := r.lookup(ctx, idInt, false)
+	if err != nil {
+		return fmt.Errorf("failed to get current subscription: %w", err)
+	}
+
+	updated := cloneSubscription(current)
+	if req.ServiceName != nil {
+		updated.ServiceName = *req.ServiceName
+		updated.ServiceID = nil
+		if req.ServiceID != nil {
+			if _, ok := r.state.services[*req.ServiceID]; !ok {
+				return fmt.Errorf("failed to update subscription: %w", ErrServiceNotFound)
+			}
+			serviceID := *req.ServiceID
+			updated.ServiceID = &serviceID
+		}
+	}
+	if req.Price != nil {
+		updated.Price = *req.Price
+	}
+	if req.TrialPrice != nil {
+		updated.TrialPrice = *req.TrialPrice
+	}
+	if userID != nil {
+		if _, ok := r.state.users[*userID]; !ok {
+			return fmt.Errorf("failed to update subscription: %w", ErrUserNotFound)
+		}
+		updated.UserID = *userID
+	}
+	if !startDate.IsZero() {
+		updated.StartDate = startDate
+	}
+	if req.TrialMonths != nil {
+		updated.TrialMonths = *req.TrialMonths
+	}
+	if req.AutoRenew != nil {
+		updated.AutoRenew = *req.AutoRenew
+	}
+	if !startDate.IsZero() || req.TrialMonths != nil {
+		updated.TrialEndsAt, updated.EndDate = model.SubscriptionDates(updated.StartDate, updated.TrialMonths, req.Location)
+	}
+	if req.Category != nil {
+		updated.Category = *req.Category
+		updated.CustomCategory = *req.Category != ""
+	}
+	r.refreshCategory(updated)
+	if req.Tags != nil {
+		updated.Tags = append([]string(nil), req.Tags...)
+	}
+	if req.Members != nil {
+		if err := r.checkMembers(req.Members); err != nil {
+			return err
+		}
+		updated.Members = slices.Clone(req.Members)
+	}
+
+	if err := r.writeAudit(ctx, auditUpdate, current, updated); err != nil {
+		return err
+	}
+	r.state.subscriptions[idInt] = updated
+
+	log.Printf("Subscription updated successfully: %s", id)
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Delete(ctx context.Context, id string, permanent bool) error {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	defer r.lock()()
+
+	before, err := r.lookup(ctx, idInt, true)
+	if err != nil {
+		return err
+	}
+
+	var after *model.Subscription
+	if !permanent {
+		if before.DeletedAt != nil {
+			return ErrNotFound
+		}
+		after = cloneSubscription(before)
+		deletedAt := time.Now().UTC()
+		after.DeletedAt = &deletedAt
+	}
+
+	if err := r.writeAudit(ctx, auditDelete, before, after); err != nil {
+		return err
+	}
+
+	if permanent {
+		delete(r.state.subscriptions, idInt)
+		r.state.deleteDeliveries(func(d *model.NotificationDelivery) bool { return d.SubscriptionID == idInt })
+	} else {
+		r.state.subscriptions[idInt] = after
+	}
+
+	log.Printf("Subscription deleted (permanent=%t): %s", permanent, id)
+	return nil
+}
+
+func (r *memorySubscriptionRepo) Restore(ctx context.Context, id string) error {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	defer r.lock()()
+
+	before, err := r.lookup(ctx, idInt, true)
+	if err != nil {
+		return err
+	}
+	if before.DeletedAt == nil {
+		return ErrNotDeleted
+	}
+
+	after := cloneSubscription(before)
+	after.DeletedAt = nil
+
+	if err := r.writeAudit(ctx, auditRestore, before, after); err != nil {
+		return err
+	}
+	r.state.subscriptions[idInt] = after
+
+	log.Printf("Subscription restored: %s", id)
+	return nil
+}
+
+func (r *memorySubscriptionRepo) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	defer r.lock()()
+
+	var purged int64
+	for id, sub := range r.state.subscriptions {
+		if sub.DeletedAt != nil && sub.DeletedAt.Before(deletedBefore) {
+			delete(r.state.subscriptions, id)
+			r.state.deleteDeliveries(func(d *model.NotificationDelivery) bool { return d.SubscriptionID == id })
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *memorySubscriptionRepo) List(ctx context.Context, filter model.SubscriptionFilter, limit, offset int) ([]*model.Subscription, error) {
+	defer r.rlock()()
+
+	var visible []*model.Subscription
+	for _, sub := range r.state.subscriptions {
+		if sub.DeletedAt != nil || !readable(ctx, sub) {
+			continue
+		}
+		if filter.Category != "" && sub.Category != filter.Category {
+			continue
+		}
+		if filter.Tag != "" && !slices.Contains(sub.Tags, filter.Tag) {
+			continue
+		}
+		visible = append(visible, sub)
+	}
+	sort.Slice(visible, func(i, j int) bool { return visible[i].ID < visible[j].ID })
+
+	var subscriptions []*model.Subscription
+	for i := offset; i < len(visible) && len(subscriptions) < limit; i++ {
+		subscriptions = append(subscriptions, cloneSubscription(visible[i]))
+	}
+
+	log.Printf("Listed %d subscriptions", len(subscriptions))
+	return subscriptions, nil
+}
+
+// costSubscriptions возвращает подписки, которые могли выставить счет в периоде
+// расчета; отбор совпадает с subscriptionRepo.costSubscriptions. Вызывается под блокировкой.
+func (r *memorySubscriptionRepo) costSubscriptions(ctx context.Context, req *model.CalculateCostRequest) ([]*model.Subscription, error) {
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user_id format: %w", err)
+	}
+
+	from, to, err := costPeriod(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*model.Subscription
+	for _, sub := range r.state.subscriptions {
+		if req.Perspective == model.PerspectiveMember {
+			if sub.DeletedAt != nil || !readable(ctx, sub) || !isMember(sub, userID) {
+				continue
+			}
+		} else if sub.DeletedAt != nil || !inScope(ctx, sub.UserID) || sub.UserID != userID {
+			continue
+		}
+		switch {
+		case req.ServiceID != nil:
+			if sub.ServiceID == nil || *sub.ServiceID != *req.ServiceID {
+				continue
+			}
+		case req.ServiceName != "":
+			if sub.ServiceName != req.ServiceName {
+				continue
+			}
+		}
+		if !sub.StartDate.Before(to) || !sub.EndDate.After(from) {
+			continue
+		}
+		matched = append(matched, sub)
+	}
+	return matched, nil
+}
+
+func (r *memorySubscriptionRepo) CalculateTotalCost(ctx context.Context, req *model.CalculateCostRequest) (int, error) {
+	defer r.rlock()()
+
+	matched, err := r.costSubscriptions(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	cycles, err := billedCycles(matched, req)
+	if err != nil {
+		return 0, err
+	}
+	totalCost := sumCycles(cycles)
+
+	log.Printf("Total cost calculated: %d for period %s to %s", totalCost, req.StartPeriod, req.EndPeriod)
+	return totalCost, nil
+}
+
+func (r *memorySubscriptionRepo) CostBreakdown(ctx context.Context, req *model.CalculateCostRequest) ([]*model.CostGroup, error) {
+	defer r.rlock()()
+
+	matched, err := r.costSubscriptions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	cycles, err := billedCycles(matched, req)
+	if err != nil {
+		return nil, err
+	}
+	return groupCycles(cycles, req.GroupBy, req.Location)
+}
+
+func (r *memorySubscriptionRepo) AddDiscount(ctx context.Context, id string, discount *model.Discount) (*model.Discount, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	defer r.lock()()
+
+	current, err := r.lookup(ctx, idInt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	created := *discount
+	created.ID = r.state.nextDiscountID
+	created.CreatedAt = time.Now().UTC()
+
+	updated := cloneSubscription(current)
+	updated.Discounts = append(updated.Discounts, &created)
+	if err := r.writeAudit(ctx, auditUpdate, current, updated); err != nil {
+		return nil, err
+	}
+	r.state.nextDiscountID++
+	r.state.subscriptions[idInt] = updated
+
+	log.Printf("Discount %d added to subscription %s", created.ID, id)
+	result := created
+	return &result, nil
+}
+
+func (r *memorySubscriptionRepo) RemoveDiscount(ctx context.Context, id string, discountID int) error {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	defer r.lock()()
+
+	current, err := r.lookup(ctx, idInt, false)
+	if err != nil {
+		return err
+	}
+
+	updated := cloneSubscription(current)
+	updated.Discounts = slices.DeleteFunc(updated.Discounts, func(d *model.Discount) bool { return d.ID == discountID })
+	if len(updated.Discounts) == len(current.Discounts) {
+		return ErrDiscountNotFound
+	}
+	if len(updated.Discounts) == 0 {
+		updated.Discounts = nil
+	}
+
+	if err := r.writeAudit(ctx, auditUpdate, current, updated); err != nil {
+		return err
+	}
+	r.state.subscriptions[idInt] = updated
+
+	log.Printf("Discount %d removed from subscription %s", discountID, id)
+	return nil
+}
+
+func (r *memorySubscriptionRepo) ChangeStatus(ctx context.Context, id string, transition *model.StatusTransition) (*model.StatusTransition, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	defer r.lock()()
+
+	current, err := r.lookup(ctx, idInt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	created := *transition
+	created.ID = r.state.nextTransitionID
+	created.CreatedAt = time.Now().UTC()
+
+	updated := cloneSubscription(current)
+	updated.Status = created.To
+	updated.CancelAt = nil
+	if created.To == model.StatusCancelled {
+		cancelAt := created.EffectiveAt
+		updated.CancelAt = &cancelAt
+	}
+	updated.Transitions = append(updated.Transitions, &created)
+	if err := r.writeAudit(ctx, auditUpdate, current, updated); err != nil {
+		return nil, err
+	}
+	r.state.nextTransitionID++
+	r.state.subscriptions[idInt] = updated
+
+	log.Printf("Subscription %s status changed: %s -> %s", id, created.From, created.To)
+	result := created
+	return &result, nil
+}
+
+// DueForRenewal выбирает подписки под блокировкой хранилища; внутри WithTx
+// другие задачи продления ждут ее снятия
+func (r *memorySubscriptionRepo) DueForRenewal(ctx context.Context, now time.Time, limit int) ([]*model.Subscription, error) {
+	defer r.rlock()()
+
+	var due []*model.Subscription
+	for _, sub := range r.state.subscriptions {
+		if renewable(sub, now) {
+			due = append(due, sub)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].EndDate.Equal(due[j].EndDate) {
+			return due[i].EndDate.Before(due[j].EndDate)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	var subs []*model.Subscription
+	for i := 0; i < len(due) && i < limit; i++ {
+		subs = append(subs, cloneSubscription(due[i]))
+	}
+	return subs, nil
+}
+
+func (r *memorySubscriptionRepo) RenewingBetween(ctx context.Context, from, to time.Time) ([]*model.Subscription, error) {
+	defer r.rlock()()
+
+	var subs []*model.Subscription
+	for _, sub := range r.state.subscriptions {
+		if sub.AutoRenew && sub.DeletedAt == nil && sub.Status != model.StatusCancelled &&
+			sub.EndDate.After(from) && !sub.EndDate.After(to) {
+			subs = append(subs, cloneSubscription(sub))
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if !subs[i].EndDate.Equal(subs[j].EndDate) {
+			return subs[i].EndDate.Before(subs[j].EndDate)
+		}
+		return subs[i].ID < subs[j].ID
+	})
+	return subs, nil
+}
+
+func (r *memorySubscriptionRepo) Renew(ctx context.Context, id string, now time.Time) (bool, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return false, ErrInvalidID
+	}
+
+	defer r.lock()()
+
+	current, err := r.lookup(ctx, idInt, false)
+	if err != nil {
+		return false, err
+	}
+	if !renewable(current, now) {
+		return false, nil
+	}
+
+	loc := time.UTC
+	if owner, ok := r.state.users[current.UserID]; ok {
+		if loc, err = time.LoadLocation(owner.Timezone); err != nil {
+			return false, fmt.Errorf("failed to get timezone of user %s: %w", current.UserID, err)
+		}
+	}
+
+	updated := cloneSubscription(current)
+	updated.EndDate = current.RenewedEndDate(now, loc)
+	if err := r.writeAudit(ctx, auditRenew, current, updated); err != nil {
+		return false, err
+	}
+	r.state.subscriptions[idInt] = updated
+
+	log.Printf("Subscription %s renewed until %s", id, updated.EndDate.Format(time.RFC3339))
+	return true, nil
+}
+
+func (r *memorySubscriptionRepo) History(ctx context.Context, id string) ([]*model.SubscriptionAuditEntry, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	defer r.rlock()()
+
+	entries := []*model.SubscriptionAuditEntry{}
+	for i := range r.state.audit {
+		record := r.state.audit[i]
+		if record.entry.SubscriptionID == idInt && inScope(ctx, record.userID) {
+			entry := record.entry
+			entries = append(entries, &entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		if _, err := r.lookup(ctx, idInt, false); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("Subscription history retrieved: %s (%d entries)", id, len(entries))
+	return entries, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+type memoryAPIKey struct {
+	key  model.APIKey
+	hash string
+}
+
+type memoryAPIKeyRepo struct {
+	mu     sync.RWMutex
+	keys   map[int]*memoryAPIKey
+	nextID int
+}
+
+func NewMemoryAPIKeyRepository() APIKeyRepository {
+	return &memoryAPIKeyRepo{keys: make(map[int]*memoryAPIKey), nextID: 1}
+}
+
+func cloneAPIKey(key model.APIKey) *model.APIKey {
+	key.Scopes = append([]string(nil), key.Scopes...)
+	return &key
+}
+
+func (r *memoryAPIKeyRepo) Create(ctx context.Context, key *model.APIKey, hash string) (*model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := cloneAPIKey(*key)
+	created.ID = r.nextID
+	created.CreatedAt = time.Now().UTC()
+	created.LastUsedAt = nil
+	created.RevokedAt = nil
+
+	r.nextID++
+	r.keys[created.ID] = &memoryAPIKey{key: *created, hash: hash}
+
+	log.Printf("API key created: %d (%s)", created.ID, created.Name)
+	return cloneAPIKey(*created), nil
+}
+
+func (r *memoryAPIKeyRepo) GetActiveByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.keys {
+		if stored.hash == hash && stored.key.RevokedAt == nil {
+			return cloneAPIKey(stored.key), nil
+		}
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+func (r *memoryAPIKeyRepo) List(ctx context.Context) ([]*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []*model.APIKey
+	for _, stored := range r.keys {
+		keys = append(keys, cloneAPIKey(stored.key))
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+// active возвращает неотозванный ключ; вызывается под блокировкой
+func (r *memoryAPIKeyRepo) active(id string) (*memoryAPIKey, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	stored, ok := r.keys[idInt]
+	if !ok || stored.key.RevokedAt != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	return stored, nil
+}
+
+func (r *memoryAPIKeyRepo) Rotate(ctx context.Context, id string, prefix, hash string) (*model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.active(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stored.key.Prefix = prefix
+	stored.key.LastUsedAt = nil
+	stored.hash = hash
+
+	log.Printf("API key rotated: %s", id)
+	return cloneAPIKey(stored.key), nil
+}
+
+func (r *memoryAPIKeyRepo) Revoke(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.active(id)
+	if err != nil {
+		return err
+	}
+
+	revokedAt := time.Now().UTC()
+	stored.key.RevokedAt = &revokedAt
+
+	log.Printf("API key revoked: %s", id)
+	return nil
+}
+
+func (r *memoryAPIKeyRepo) TouchLastUsed(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.keys[id]; ok {
+		lastUsedAt := time.Now().UTC()
+		stored.key.LastUsedAt = &lastUsedAt
+	}
+	return nil
+}
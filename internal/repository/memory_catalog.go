@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+// memoryServiceCatalogRepo хранит каталог в том же состоянии, что и подписки:
+// так ссылки подписок на сервисы проверяются, как внешний ключ в базе.
+type memoryServiceCatalogRepo struct {
+	mu    *sync.RWMutex
+	state *memoryState
+}
+
+// NewMemoryServiceCatalogRepository создает каталог в памяти процесса. subscriptions
+// должно быть создано NewMemorySubscriptionRepository: каталог разделяет с ним данные.
+func NewMemoryServiceCatalogRepository(subscriptions SubscriptionRepository) ServiceCatalogRepository {
+	shared := subscriptions.(*memorySubscriptionRepo)
+	return &memoryServiceCatalogRepo{mu: shared.mu, state: shared.state}
+}
+
+func cloneService(svc *model.Service) *model.Service {
+	clone := *svc
+	clone.Aliases = append([]string{}, svc.Aliases...)
+	clone.Plans = append([]model.ServicePlan{}, svc.Plans...)
+	return &clone
+}
+
+// checkLookups проверяет, что lookups не заняты другим сервисом; вызывается под блокировкой
+func (r *memoryServiceCatalogRepo) checkLookups(serviceID int, lookups []string) error {
+	for _, lookup := range lookups {
+		if owner, ok := r.state.serviceLookup[lookup]; ok && owner != serviceID {
+			return fmt.Errorf("%w: %q", ErrServiceConflict, lookup)
+		}
+	}
+	return nil
+}
+
+// store сохраняет сервис с его названиями и привязывает подписки без сервиса;
+// вызывается под блокировкой после checkLookups
+func (r *memoryServiceCatalogRepo) store(svc *model.Service, lookups []string) {
+	for lookup, owner := range r.state.serviceLookup {
+		if owner == svc.ID {
+			delete(r.state.serviceLookup, lookup)
+		}
+	}
+
+	known := make(map[string]bool, len(lookups))
+	for _, lookup := range lookups {
+		r.state.serviceLookup[lookup] = svc.ID
+		known[lookup] = true
+	}
+	r.state.services[svc.ID] = svc
+
+	for id, sub := range r.state.subscriptions {
+		linked := sub.ServiceID != nil && *sub.ServiceID == svc.ID
+		if !linked && (sub.ServiceID != nil || !known[NormalizeServiceName(sub.ServiceName)]) {
+			continue
+		}
+		updated := cloneSubscription(sub)
+		serviceID := svc.ID
+		updated.ServiceID = &serviceID
+		updated.ServiceName = svc.Name
+		if !updated.CustomCategory {
+			updated.Category = svc.Category
+		}
+		r.state.subscriptions[id] = updated
+	}
+}
+
+func (r *memoryServiceCatalogRepo) Create(ctx context.Context, svc *model.Service) (*model.Service, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := cloneService(svc)
+	created.ID = r.state.nextServiceID
+	created.CreatedAt = time.Now().UTC()
+
+	lookups := serviceLookups(created)
+	if err := r.checkLookups(created.ID, lookups); err != nil {
+		return nil, err
+	}
+
+	r.state.nextServiceID++
+	r.store(created, lookups)
+
+	log.Printf("Service created: %d (%s)", created.ID, created.Name)
+	return cloneService(created), nil
+}
+
+// lookupService возвращает сервис по id; вызывается под блокировкой
+func (r *memoryServiceCatalogRepo) lookupService(id string) (*model.Service, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	svc, ok := r.state.services[idInt]
+	if !ok {
+		return nil, ErrServiceNotFound
+	}
+	return svc, nil
+}
+
+func (r *memoryServiceCatalogRepo) GetByID(ctx context.Context, id string) (*model.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	svc, err := r.lookupService(id)
+	if err != nil {
+		return nil, err
+	}
+	return cloneService(svc), nil
+}
+
+func (r *memoryServiceCatalogRepo) Resolve(ctx context.Context, name string) (*model.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.state.serviceLookup[NormalizeServiceName(name)]
+	if !ok {
+		return nil, ErrServiceNotFound
+	}
+	return cloneService(r.state.services[id]), nil
+}
+
+func (r *memoryServiceCatalogRepo) List(ctx context.Context) ([]*model.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	services := []*model.Service{}
+	for _, svc := range r.state.services {
+		services = append(services, cloneService(svc))
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].ID < services[j].ID })
+	return services, nil
+}
+
+func (r *memoryServiceCatalogRepo) Update(ctx context.Context, id string, svc *model.Service) (*model.Service, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.lookupService(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := cloneService(svc)
+	updated.ID = current.ID
+	updated.CreatedAt = current.CreatedAt
+
+	lookups := serviceLookups(updated)
+	if err := r.checkLookups(updated.ID, lookups); err != nil {
+		return nil, err
+	}
+	r.store(updated, lookups)
+
+	log.Printf("Service updated: %s", id)
+	return cloneService(updated), nil
+}
+
+func (r *memoryServiceCatalogRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svc, err := r.lookupService(id)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range r.state.subscriptions {
+		if sub.ServiceID != nil && *sub.ServiceID == svc.ID {
+			return ErrServiceInUse
+		}
+	}
+
+	for lookup, owner := range r.state.serviceLookup {
+		if owner == svc.ID {
+			delete(r.state.serviceLookup, lookup)
+		}
+	}
+	delete(r.state.services, svc.ID)
+
+	log.Printf("Service deleted: %s", id)
+	return nil
+}
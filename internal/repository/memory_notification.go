@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+// memoryNotificationRepo хранит уведомления в общем состоянии с подписками,
+// чтобы удаление пользователя или подписки удаляло и связанные записи
+type memoryNotificationRepo struct {
+	mu    *sync.RWMutex
+	state *memoryState
+}
+
+// NewMemoryNotificationRepository создает хранилище уведомлений, разделяющее
+// данные с хранилищем подписок
+func NewMemoryNotificationRepository(subscriptions SubscriptionRepository) NotificationRepository {
+	shared := subscriptions.(*memorySubscriptionRepo)
+	return &memoryNotificationRepo{mu: shared.mu, state: shared.state}
+}
+
+func cloneDelivery(delivery *model.NotificationDelivery) *model.NotificationDelivery {
+	clone := *delivery
+	if delivery.SentAt != nil {
+		sentAt := *delivery.SentAt
+		clone.SentAt = &sentAt
+	}
+	return &clone
+}
+
+// deleteDeliveries удаляет записи об отправке, для которых match вернула true;
+// вызывается под блокировкой
+func (s *memoryState) deleteDeliveries(match func(d *model.NotificationDelivery) bool) {
+	for id, delivery := range s.deliveries {
+		if match(delivery) {
+			delete(s.deliveries, id)
+		}
+	}
+}
+
+func (r *memoryNotificationRepo) GetPreferences(ctx context.Context, userID string) (*model.NotificationPreferences, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs, ok := r.state.preferences[id]
+	if !ok {
+		return nil, ErrPreferencesNotFound
+	}
+	clone := *prefs
+	return &clone, nil
+}
+
+func (r *memoryNotificationRepo) SavePreferences(ctx context.Context, prefs *model.NotificationPreferences) (*model.NotificationPreferences, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.state.users[prefs.UserID]; !ok {
+		return nil, ErrUserNotFound
+	}
+
+	saved := *prefs
+	saved.UpdatedAt = time.Now().UTC()
+	r.state.preferences[saved.UserID] = &saved
+
+	log.Printf("Notification preferences saved for user %s", saved.UserID)
+	clone := saved
+	return &clone, nil
+}
+
+func (r *memoryNotificationRepo) ClaimDelivery(ctx context.Context, delivery *model.NotificationDelivery, maxAttempts int,
+	now time.Time, lease time.Duration) (*model.NotificationDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.state.deliveries {
+		if stored.SubscriptionID != delivery.SubscriptionID || stored.Channel != delivery.Channel ||
+			!stored.RenewsAt.Equal(delivery.RenewsAt) {
+			continue
+		}
+		abandoned := stored.Status == model.DeliveryPending && !stored.ClaimedAt.After(now.Add(-lease))
+		if (stored.Status != model.DeliveryFailed && !abandoned) || stored.Attempts >= maxAttempts {
+			return nil, nil
+		}
+
+		claimed := cloneDelivery(stored)
+		claimed.Status = model.DeliveryPending
+		claimed.Attempts++
+		claimed.Error = ""
+		claimed.ClaimedAt = now.UTC()
+		r.state.deliveries[claimed.ID] = claimed
+		return cloneDelivery(claimed), nil
+	}
+
+	claimed := &model.NotificationDelivery{
+		ID:             r.state.nextDeliveryID,
+		SubscriptionID: delivery.SubscriptionID,
+		UserID:         delivery.UserID,
+		Channel:        delivery.Channel,
+		RenewsAt:       delivery.RenewsAt.UTC(),
+		Status:         model.DeliveryPending,
+		Attempts:       1,
+		CreatedAt:      now.UTC(),
+		ClaimedAt:      now.UTC(),
+	}
+	r.state.nextDeliveryID++
+	r.state.deliveries[claimed.ID] = claimed
+	return cloneDelivery(claimed), nil
+}
+
+func (r *memoryNotificationRepo) CompleteDelivery(ctx context.Context, id int64, sendErr error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.state.deliveries[id]
+	if !ok {
+		return nil
+	}
+
+	completed := cloneDelivery(stored)
+	if sendErr != nil {
+		completed.Status = model.DeliveryFailed
+		completed.Error = sendErr.Error()
+	} else {
+		sentAt := time.Now().UTC()
+		completed.Status = model.DeliverySent
+		completed.SentAt = &sentAt
+	}
+	r.state.deliveries[id] = completed
+	return nil
+}
+
+func (r *memoryNotificationRepo) ListDeliveries(ctx context.Context, userID string, limit, offset int) ([]*model.NotificationDelivery, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []*model.NotificationDelivery
+	for _, delivery := range r.state.deliveries {
+		if delivery.UserID == id {
+			deliveries = append(deliveries, cloneDelivery(delivery))
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+
+	if offset >= len(deliveries) {
+		return nil, nil
+	}
+	deliveries = deliveries[offset:]
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,21 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository/repositorytest"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	runContracts(t, func(t *testing.T) repositorytest.Repositories {
+		subscriptions := repository.NewMemorySubscriptionRepository()
+		return repositorytest.Repositories{
+			Subscriptions: subscriptions,
+			Services:      repository.NewMemoryServiceCatalogRepository(subscriptions),
+			Users:         repository.NewMemoryUserRepository(subscriptions),
+			Notifications: repository.NewMemoryNotificationRepository(subscriptions),
+			Webhooks:      repository.NewMemoryWebhookRepository(subscriptions),
+		}
+	})
+}
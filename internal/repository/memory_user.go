@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+// memoryUserRepo хранит пользователей в том же состоянии, что и подписки:
+// так владелец подписки проверяется, как внешний ключ в базе.
+type memoryUserRepo struct {
+	mu    *sync.RWMutex
+	state *memoryState
+}
+
+// NewMemoryUserRepository создает хранилище пользователей в памяти процесса. subscriptions
+// должно быть создано NewMemorySubscriptionRepository: хранилища разделяют данные.
+func NewMemoryUserRepository(subscriptions SubscriptionRepository) UserRepository {
+	shared := subscriptions.(*memorySubscriptionRepo)
+	return &memoryUserRepo{mu: shared.mu, state: shared.state}
+}
+
+func cloneUser(user *model.User) *model.User {
+	clone := *user
+	return &clone
+}
+
+func (r *memoryUserRepo) Create(ctx context.Context, user *model.User) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.state.users[user.ID]; ok {
+		return nil, ErrUserExists
+	}
+
+	created := cloneUser(user)
+	created.CreatedAt = time.Now().UTC()
+	created.UpdatedAt = created.CreatedAt
+	r.state.users[created.ID] = created
+
+	log.Printf("User created: %s", created.ID)
+	return cloneUser(created), nil
+}
+
+func (r *memoryUserRepo) GetByID(ctx context.Context, id string) (*model.User, error) {
+	userID, err := parseUserID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.state.users[userID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return cloneUser(user), nil
+}
+
+func (r *memoryUserRepo) List(ctx context.Context, limit, offset int) ([]*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []*model.User{}
+	for _, user := range r.state.users {
+		users = append(users, cloneUser(user))
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].ID.String() < users[j].ID.String()
+	})
+
+	if offset >= len(users) {
+		return []*model.User{}, nil
+	}
+	return users[offset:min(offset+limit, len(users))], nil
+}
+
+func (r *memoryUserRepo) Update(ctx context.Context, user *model.User) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.state.users[user.ID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	updated := cloneUser(user)
+	updated.CreatedAt = current.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+	r.state.users[updated.ID] = updated
+
+	log.Printf("User updated: %s", user.ID)
+	return cloneUser(updated), nil
+}
+
+func (r *memoryUserRepo) Delete(ctx context.Context, id string, cascade bool) error {
+	userID, err := parseUserID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.state.users[userID]; !ok {
+		return ErrUserNotFound
+	}
+
+	var owned []*model.Subscription
+	for _, sub := range r.state.subscriptions {
+		if sub.UserID == userID {
+			owned = append(owned, sub)
+		}
+	}
+	if len(owned) > 0 && !cascade {
+		return ErrUserInUse
+	}
+
+	// Журнал пишется тем же кодом, что и у хранилища подписок; блокировка уже взята
+	subscriptions := &memorySubscriptionRepo{mu: r.mu, state: r.state, inTx: true}
+	sort.Slice(owned, func(i, j int) bool { return owned[i].ID < owned[j].ID })
+	for _, sub := range owned {
+		if err := subscriptions.writeAudit(ctx, auditDelete, sub, nil); err != nil {
+			return err
+		}
+		delete(r.state.subscriptions, sub.ID)
+	}
+	// Как и каскад в SQL, удаление пользователя убирает его из чужих общих подписок
+	for id, sub := range r.state.subscriptions {
+		if slices.ContainsFunc(sub.Members, func(m model.SubscriptionMember) bool { return m.UserID == userID }) {
+			updated := cloneSubscription(sub)
+			updated.Members = slices.DeleteFunc(updated.Members, func(m model.SubscriptionMember) bool { return m.UserID == userID })
+			if len(updated.Members) == 0 {
+				updated.Members = nil
+			}
+			r.state.subscriptions[id] = updated
+		}
+	}
+	delete(r.state.users, userID)
+	delete(r.state.preferences, userID)
+	r.state.deleteDeliveries(func(d *model.NotificationDelivery) bool {
+		_, ok := r.state.subscriptions[d.SubscriptionID]
+		return d.UserID == userID || !ok
+	})
+
+	log.Printf("Deleted %d subscriptions of user %s", len(owned), userID)
+	log.Printf("User deleted: %s", id)
+	return nil
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+// memoryWebhookRepo хранит webhook в общем состоянии с подписками, чтобы доставки
+// событий создавались и откатывались в одной транзакции с изменением подписки
+type memoryWebhookRepo struct {
+	mu    *sync.RWMutex
+	state *memoryState
+}
+
+// NewMemoryWebhookRepository создает хранилище webhook, разделяющее данные
+// с хранилищем подписок
+func NewMemoryWebhookRepository(subscriptions SubscriptionRepository) WebhookRepository {
+	shared := subscriptions.(*memorySubscriptionRepo)
+	return &memoryWebhookRepo{mu: shared.mu, state: shared.state}
+}
+
+func cloneWebhook(hook *model.Webhook) *model.Webhook {
+	clone := *hook
+	clone.Events = append([]string(nil), hook.Events...)
+	return &clone
+}
+
+func cloneWebhookDelivery(delivery *model.WebhookDelivery) *model.WebhookDelivery {
+	clone := *delivery
+	clone.Payload = append([]byte(nil), delivery.Payload...)
+	if delivery.NextAttemptAt != nil {
+		nextAttemptAt := *delivery.NextAttemptAt
+		clone.NextAttemptAt = &nextAttemptAt
+	}
+	if delivery.DeliveredAt != nil {
+		deliveredAt := *delivery.DeliveredAt
+		clone.DeliveredAt = &deliveredAt
+	}
+	return &clone
+}
+
+func (r *memoryWebhookRepo) Create(ctx context.Context, hook *model.Webhook) (*model.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := cloneWebhook(hook)
+	created.ID = r.state.nextWebhookID
+	created.CreatedAt = time.Now().UTC()
+	r.state.nextWebhookID++
+	r.state.webhooks[created.ID] = created
+
+	log.Printf("Webhook created: %d (%s)", created.ID, created.URL)
+	return cloneWebhook(created), nil
+}
+
+func (r *memoryWebhookRepo) GetByID(ctx context.Context, id string) (*model.Webhook, error) {
+	idInt, err := parseWebhookID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hook, ok := r.state.webhooks[idInt]
+	if !ok {
+		return nil, ErrWebhookNotFound
+	}
+	return cloneWebhook(hook), nil
+}
+
+func (r *memoryWebhookRepo) List(ctx context.Context) ([]*model.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var hooks []*model.Webhook
+	for _, hook := range r.state.webhooks {
+		hooks = append(hooks, cloneWebhook(hook))
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].ID < hooks[j].ID })
+	return hooks, nil
+}
+
+func (r *memoryWebhookRepo) Delete(ctx context.Context, id string) error {
+	idInt, err := parseWebhookID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.state.webhooks[idInt]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(r.state.webhooks, idInt)
+	for deliveryID, delivery := range r.state.webhookDeliveries {
+		if delivery.WebhookID == idInt {
+			delete(r.state.webhookDeliveries, deliveryID)
+		}
+	}
+
+	log.Printf("Webhook deleted: %s", id)
+	return nil
+}
+
+// enqueueWebhookDeliveries ставит событие в очередь доставки каждому webhook,
+// подписанному на event.Type; вызывается под блокировкой
+func (s *memoryState) enqueueWebhookDeliveries(event *model.WebhookEvent, payload []byte) int {
+	ids := make([]int, 0, len(s.webhooks))
+	for id, hook := range s.webhooks {
+		if slices.Contains(hook.Events, event.Type) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	now := time.Now().UTC()
+	for _, id := range ids {
+		nextAttemptAt := now
+		s.webhookDeliveries[s.nextWebhookDeliveryID] = &model.WebhookDelivery{
+			ID:            s.nextWebhookDeliveryID,
+			WebhookID:     id,
+			EventID:       event.ID,
+			Event:         event.Type,
+			Payload:       append([]byte(nil), payload...),
+			Status:        model.DeliveryPending,
+			NextAttemptAt: &nextAttemptAt,
+			CreatedAt:     now,
+		}
+		s.nextWebhookDeliveryID++
+	}
+	return len(ids)
+}
+
+func (r *memorySubscriptionRepo) EnqueueEvent(ctx context.Context, event *model.WebhookEvent, payload []byte) (int, error) {
+	defer r.lock()()
+	return r.state.enqueueWebhookDeliveries(event, payload), nil
+}
+
+func (r *memoryWebhookRepo) ClaimNext(ctx context.Context, now, leaseUntil time.Time) (*model.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var next *model.WebhookDelivery
+	for _, delivery := range r.state.webhookDeliveries {
+		if delivery.Status != model.DeliveryPending || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		if next == nil || delivery.NextAttemptAt.Before(*next.NextAttemptAt) ||
+			(delivery.NextAttemptAt.Equal(*next.NextAttemptAt) && delivery.ID < next.ID) {
+			next = delivery
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	claimed := cloneWebhookDelivery(next)
+	claimed.NextAttemptAt = &leaseUntil
+	r.state.webhookDeliveries[claimed.ID] = claimed
+	return cloneWebhookDelivery(claimed), nil
+}
+
+func (r *memoryWebhookRepo) SaveAttempt(ctx context.Context, delivery *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.state.webhookDeliveries[delivery.ID]
+	if !ok {
+		return nil
+	}
+
+	saved := cloneWebhookDelivery(stored)
+	saved.Status = delivery.Status
+	saved.Attempts = delivery.Attempts
+	saved.NextAttemptAt = delivery.NextAttemptAt
+	saved.ResponseStatus = delivery.ResponseStatus
+	saved.Error = delivery.Error
+	saved.DeliveredAt = delivery.DeliveredAt
+	r.state.webhookDeliveries[saved.ID] = cloneWebhookDelivery(saved)
+	return nil
+}
+
+func (r *memoryWebhookRepo) ListDeliveries(ctx context.Context, id string, limit, offset int) ([]*model.WebhookDelivery, error) {
+	idInt, err := parseWebhookID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.state.webhooks[idInt]; !ok {
+		return nil, ErrWebhookNotFound
+	}
+
+	var deliveries []*model.WebhookDelivery
+	for _, delivery := range r.state.webhookDeliveries {
+		if delivery.WebhookID == idInt {
+			deliveries = append(deliveries, cloneWebhookDelivery(delivery))
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+
+	if offset >= len(deliveries) {
+		return nil, nil
+	}
+	deliveries = deliveries[offset:]
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+var ErrPreferencesNotFound = errors.New("notification preferences not found")
+
+// NotificationRepository хранит настройки уведомлений пользователей и записи
+// об отправке напоминаний о продлении подписок
+type NotificationRepository interface {
+	// GetPreferences возвращает настройки пользователя userID
+	// (ErrPreferencesNotFound, если он их не задавал)
+	GetPreferences(ctx context.Context, userID string) (*model.NotificationPreferences, error)
+	// SavePreferences создает или заменяет настройки пользователя prefs.UserID
+	// (ErrUserNotFound, если пользователя нет)
+	SavePreferences(ctx context.Context, prefs *model.NotificationPreferences) (*model.NotificationPreferences, error)
+	// ClaimDelivery резервирует отправку напоминания о продлении подписки по каналу
+	// к моменту now и возвращает запись с учтенной попыткой. Возвращает nil, если
+	// напоминание уже отправлено или отправляется, а также если попыток было maxAttempts.
+	// Отправка, зарезервированная больше lease назад и не завершенная, считается
+	// прерванной и резервируется заново.
+	ClaimDelivery(ctx context.Context, delivery *model.NotificationDelivery, maxAttempts int, now time.Time, lease time.Duration) (*model.NotificationDelivery, error)
+	// CompleteDelivery отмечает отправку id выполненной или, если sendErr не nil, неудачной
+	CompleteDelivery(ctx context.Context, id int64, sendErr error) error
+	// ListDeliveries возвращает отправки напоминаний пользователю userID, начиная с последних
+	ListDeliveries(ctx context.Context, userID string, limit, offset int) ([]*model.NotificationDelivery, error)
+}
+
+type notificationRepo struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository создает хранилище уведомлений поверх PostgreSQL или SQLite
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepo{db: db}
+}
+
+const preferencesColumns = `user_id, renewal_reminders, email, webhook_url, lead_days, updated_at`
+
+const deliveryColumns = `id, subscription_id, user_id, channel, renews_at, status, attempts, error, created_at, claimed_at, sent_at`
+
+func scanPreferences(row interface{ Scan(...interface{}) error }) (*model.NotificationPreferences, error) {
+	var prefs model.NotificationPreferences
+	if err := row.Scan(&prefs.UserID, &prefs.RenewalReminders, &prefs.Email, &prefs.WebhookURL,
+		&prefs.LeadDays, &prefs.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+func scanDelivery(row interface{ Scan(...interface{}) error }) (*model.NotificationDelivery, error) {
+	var delivery model.NotificationDelivery
+	var sentAt sql.NullTime
+	if err := row.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.UserID, &delivery.Channel, &delivery.RenewsAt,
+		&delivery.Status, &delivery.Attempts, &delivery.Error, &delivery.CreatedAt, &delivery.ClaimedAt, &sentAt); err != nil {
+		return nil, err
+	}
+	if sentAt.Valid {
+		delivery.SentAt = &sentAt.Time
+	}
+	return &delivery, nil
+}
+
+func (r *notificationRepo) GetPreferences(ctx context.Context, userID string) (*model.NotificationPreferences, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := scanPreferences(r.db.QueryRowContext(ctx,
+		`SELECT `+preferencesColumns+` FROM notification_preferences WHERE user_id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrPreferencesNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting notification preferences: %v", err)
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+func (r *notificationRepo) SavePreferences(ctx context.Context, prefs *model.NotificationPreferences) (*model.NotificationPreferences, error) {
+	query := `INSERT INTO notification_preferences (` + preferencesColumns + `)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    ON CONFLICT (user_id) DO UPDATE SET renewal_reminders = excluded.renewal_reminders, email = excluded.email,
+        webhook_url = excluded.webhook_url, lead_days = excluded.lead_days, updated_at = excluded.updated_at
+    RETURNING ` + preferencesColumns
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkUserExists(ctx, tx, prefs.UserID); err != nil {
+		return nil, err
+	}
+
+	saved, err := scanPreferences(tx.QueryRowContext(ctx, query, prefs.UserID, prefs.RenewalReminders, prefs.Email,
+		prefs.WebhookURL, prefs.LeadDays, time.Now().UTC()))
+	if err != nil {
+		log.Printf("Error saving notification preferences: %v", err)
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Notification preferences saved for user %s", saved.UserID)
+	return saved, nil
+}
+
+// ClaimDelivery вставляет запись об отправке или, если прошлая попытка не удалась
+// или прервалась, забирает ее повторно. Уникальный ключ (subscription_id, channel, renews_at)
+// не дает двум задачам отправить одно напоминание: проигравшая получает пустой результат.
+func (r *notificationRepo) ClaimDelivery(ctx context.Context, delivery *model.NotificationDelivery, maxAttempts int,
+	now time.Time, lease time.Duration) (*model.NotificationDelivery, error) {
+	query := `INSERT INTO notification_deliveries (subscription_id, user_id, channel, renews_at, status, attempts, error, created_at, claimed_at)
+    VALUES ($1, $2, $3, $4, $5, 1, '', $6, $6)
+    ON CONFLICT (subscription_id, channel, renews_at) DO UPDATE
+        SET status = $5, attempts = notification_deliveries.attempts + 1, error = '', claimed_at = $6
+        WHERE (notification_deliveries.status = $7
+            OR (notification_deliveries.status = $5 AND notification_deliveries.claimed_at <= $8))
+            AND notification_deliveries.attempts < $9
+    RETURNING ` + deliveryColumns
+
+	now = now.UTC()
+	claimed, err := scanDelivery(r.db.QueryRowContext(ctx, query, delivery.SubscriptionID, delivery.UserID, delivery.Channel,
+		delivery.RenewsAt.UTC(), model.DeliveryPending, now, model.DeliveryFailed, now.Add(-lease), maxAttempts))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error claiming notification delivery: %v", err)
+		return nil, fmt.Errorf("failed to claim notification delivery: %w", err)
+	}
+	return claimed, nil
+}
+
+func (r *notificationRepo) CompleteDelivery(ctx context.Context, id int64, sendErr error) error {
+	var err error
+	if sendErr != nil {
+		_, err = r.db.ExecContext(ctx, `UPDATE notification_deliveries SET status = $1, error = $2 WHERE id = $3`,
+			model.DeliveryFailed, sendErr.Error(), id)
+	} else {
+		_, err = r.db.ExecContext(ctx, `UPDATE notification_deliveries SET status = $1, sent_at = $2 WHERE id = $3`,
+			model.DeliverySent, time.Now().UTC(), id)
+	}
+	if err != nil {
+		log.Printf("Error completing notification delivery: %v", err)
+		return fmt.Errorf("failed to complete notification delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepo) ListDeliveries(ctx context.Context, userID string, limit, offset int) ([]*model.NotificationDelivery, error) {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+deliveryColumns+` FROM notification_deliveries
+    WHERE user_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3`, id, limit, offset)
+	if err != nil {
+		log.Printf("Error listing notification deliveries: %v", err)
+		return nil, fmt.Errorf("failed to list notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*model.NotificationDelivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository/repositorytest"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/pkg/database"
+	"github.com/lib/pq"
+)
+
+// postgresDSNEnv задает базу PostgreSQL для проверок; ее таблицы очищаются
+// перед каждой проверкой, поэтому рабочую базу указывать нельзя
+const postgresDSNEnv = "TEST_POSTGRES_DSN"
+
+func TestPostgresRepositories(t *testing.T) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	if err := database.RunMigrations(dsn); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	db, err := database.NewPostgres(context.Background(), dsn, database.PoolOptions{})
+	if err != nil {
+		t.Fatalf("NewPostgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	runContracts(t, func(t *testing.T) repositorytest.Repositories {
+		truncateTables(t, db)
+		return dbRepositories(db.DB, repository.NewSubscriptionRepository(db.DB))
+	})
+}
+
+// truncateTables очищает все таблицы схемы, кроме версии миграций, и сбрасывает
+// последовательности, чтобы каждая проверка начиналась с пустой базы
+func truncateTables(t *testing.T, db *database.DB) {
+	t.Helper()
+	ctx := context.Background()
+
+	rows, err := db.QueryContext(ctx, `SELECT tablename FROM pg_tables
+    WHERE schemaname = current_schema() AND tablename <> 'schema_migrations'`)
+	if err != nil {
+		t.Fatalf("List tables: %v", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			t.Fatalf("Scan table: %v", err)
+		}
+		tables = append(tables, pq.QuoteIdentifier(table))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("List tables: %v", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `TRUNCATE TABLE `+strings.Join(tables, ", ")+` RESTART IDENTITY CASCADE`); err != nil {
+		t.Fatalf("Truncate tables: %v", err)
+	}
+}
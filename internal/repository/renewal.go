@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func (r *subscriptionRepo) DueForRenewal(ctx context.Context, now time.Time, limit int) ([]*model.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions
+    WHERE auto_renew AND deleted_at IS NULL AND status <> $1 AND end_date <= $2
+    ORDER BY end_date, id LIMIT $3`
+	// SKIP LOCKED отдает задаче каждой реплики свои подписки вместо ожидания чужих
+	if r.tx != nil && r.forUpdate != "" {
+		query += r.forUpdate + " SKIP LOCKED"
+	}
+
+	rows, err := r.conn().QueryContext(ctx, query, model.StatusCancelled, now, limit)
+	if err != nil {
+		log.Printf("Error getting subscriptions due for renewal: %v", err)
+		return nil, fmt.Errorf("failed to get subscriptions due for renewal: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*model.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions due for renewal: %w", err)
+	}
+	rows.Close()
+
+	if err := loadDetails(ctx, r.conn(), subs...); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (r *subscriptionRepo) RenewingBetween(ctx context.Context, from, to time.Time) ([]*model.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions
+    WHERE auto_renew AND deleted_at IS NULL AND status <> $1 AND end_date > $2 AND end_date <= $3
+    ORDER BY end_date, id`
+
+	rows, err := r.conn().QueryContext(ctx, query, model.StatusCancelled, from, to)
+	if err != nil {
+		log.Printf("Error getting renewing subscriptions: %v", err)
+		return nil, fmt.Errorf("failed to get renewing subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*model.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// ownerLocation возвращает часовой пояс владельца подписки, в котором отсчитываются месяцы
+func ownerLocation(ctx context.Context, q querier, userID uuid.UUID) (*time.Location, error) {
+	var timezone string
+	err := q.QueryRowContext(ctx, `SELECT timezone FROM users WHERE id = $1`, userID).Scan(&timezone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timezone of user %s: %w", userID, err)
+	}
+	return time.LoadLocation(timezone)
+}
+
+// renewable сообщает, нужно ли продлить подписку к моменту now
+func renewable(sub *model.Subscription, now time.Time) bool {
+	return sub.AutoRenew && sub.DeletedAt == nil && sub.Status != model.StatusCancelled && !sub.EndDate.After(now)
+}
+
+// Renew продлевает подписку, если ее end_date наступил к now, и записывает продление
+// в журнал. Условие проверяется под блокировкой строки, поэтому повторный вызов, в том
+// числе с другой реплики, ничего не меняет.
+func (r *subscriptionRepo) Renew(ctx context.Context, id string, now time.Time) (bool, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return false, ErrInvalidID
+	}
+
+	var after *model.Subscription
+	err = r.inTx(ctx, func(tx *subscriptionRepo) error {
+		before, err := tx.lockSubscription(ctx, idInt, false)
+		if err != nil {
+			return err
+		}
+		if !renewable(before, now) {
+			return nil
+		}
+
+		loc, err := ownerLocation(ctx, tx.conn(), before.UserID)
+		if err != nil {
+			return err
+		}
+		after, err = scanSubscription(tx.conn().QueryRowContext(ctx,
+			`UPDATE subscriptions SET end_date = $1 WHERE id = $2 RETURNING `+subscriptionColumns, before.RenewedEndDate(now, loc), idInt))
+		if err != nil {
+			log.Printf("Error renewing subscription: %v", err)
+			return fmt.Errorf("failed to renew subscription: %w", err)
+		}
+		after.Tags = before.Tags
+		after.Discounts = before.Discounts
+		after.Members = before.Members
+		after.Transitions = before.Transitions
+
+		return writeAudit(ctx, tx.conn(), auditRenew, before, after)
+	})
+	if err != nil || after == nil {
+		return false, err
+	}
+
+	log.Printf("Subscription %s renewed until %s", id, after.EndDate.Format(time.RFC3339))
+	return true, nil
+}
@@ -1,102 +1,319 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
 	"time"
 
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrNotFound   = errors.New("subscription not found")
+	ErrNotDeleted = errors.New("subscription is not deleted")
+	// ErrInvalidID возвращается, когда числовой идентификатор записи не разбирается
+	ErrInvalidID = errors.New("invalid id format: must be integer")
+)
+
 type SubscriptionRepository interface {
-	Create(sub *model.Subscription) (*model.Subscription, error)
-	GetByID(id string) (*model.Subscription, error)
-	Update(id string, req *model.UpdateSubscriptionRequest) error
-	Delete(id string) error
-	List(limit, offset int) ([]*model.Subscription, error)
-	CalculateTotalCost(req *model.CalculateCostRequest) (int, error)
+	Create(ctx context.Context, sub *model.Subscription) (*model.Subscription, error)
+	GetByID(ctx context.Context, id string) (*model.Subscription, error)
+	Update(ctx context.Context, id string, req *model.UpdateSubscriptionRequest) error
+	Delete(ctx context.Context, id string, permanent bool) error
+	Restore(ctx context.Context, id string) error
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+	List(ctx context.Context, filter model.SubscriptionFilter, limit, offset int) ([]*model.Subscription, error)
+	// CalculateTotalCost суммирует стоимость расчетных периодов подписок, начавшихся
+	// в периоде req, за вычетом скидок
+	CalculateTotalCost(ctx context.Context, req *model.CalculateCostRequest) (int, error)
+	// CostBreakdown разбивает стоимость подписок по категориям, тегам или месяцам (req.GroupBy)
+	CostBreakdown(ctx context.Context, req *model.CalculateCostRequest) ([]*model.CostGroup, error)
+	// AddDiscount добавляет скидку к подписке и возвращает ее с присвоенным ID
+	AddDiscount(ctx context.Context, id string, discount *model.Discount) (*model.Discount, error)
+	// RemoveDiscount удаляет скидку discountID подписки id (ErrDiscountNotFound, если ее нет)
+	RemoveDiscount(ctx context.Context, id string, discountID int) error
+	// ChangeStatus переводит подписку в состояние transition.To и записывает переход;
+	// для отмены CancelAt становится transition.EffectiveAt
+	ChangeStatus(ctx context.Context, id string, transition *model.StatusTransition) (*model.StatusTransition, error)
+	// DueForRenewal возвращает до limit неотмененных подписок с auto_renew, чей end_date
+	// наступил к now. Внутри WithTx строки блокируются до конца транзакции, а уже
+	// заблокированные другой транзакцией пропускаются.
+	DueForRenewal(ctx context.Context, now time.Time, limit int) ([]*model.Subscription, error)
+	// Renew продлевает подписку, срок которой наступил к now, до ближайшей месячной
+	// даты оплаты позже now в часовом поясе владельца. Возвращает false, если продлевать
+	// нечего, например подписку уже продлила другая задача.
+	Renew(ctx context.Context, id string, now time.Time) (bool, error)
+	// RenewingBetween возвращает неотмененные подписки с auto_renew, чей end_date
+	// наступит в (from, to]. Теги, скидки и участники не загружаются.
+	RenewingBetween(ctx context.Context, from, to time.Time) ([]*model.Subscription, error)
+	History(ctx context.Context, id string) ([]*model.SubscriptionAuditEntry, error)
+	// EnqueueEvent ставит событие о подписке в очередь доставки каждому webhook,
+	// подписанному на event.Type, и возвращает число созданных доставок. Внутри WithTx
+	// доставки создаются в той же транзакции, что и изменение подписки.
+	EnqueueEvent(ctx context.Context, event *model.WebhookEvent, payload []byte) (int, error)
+
+	// GetForUpdate читает подписку и блокирует ее от изменения другими транзакциями
+	// до завершения WithTx. Вне WithTx равносилен GetByID.
+	GetForUpdate(ctx context.Context, id string) (*model.Subscription, error)
+	// OwnerLocation возвращает часовой пояс пользователя userID (ErrUserNotFound, если
+	// профиля нет). Внутри WithTx профиль читается в той же транзакции.
+	OwnerLocation(ctx context.Context, userID uuid.UUID) (*time.Location, error)
+	// WithTx выполняет fn в одной транзакции: все операции переданного repo
+	// фиксируются вместе, если fn вернула nil, и откатываются при ошибке.
+	// Вложенный вызов WithTx выполняется в уже открытой транзакции.
+	WithTx(ctx context.Context, fn func(repo SubscriptionRepository) error) error
+}
+
+// querier — общие методы *sql.DB и *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 type subscriptionRepo struct {
 	db *sql.DB
+	// tx задан у хранилища, переданного в fn из WithTx; запросы выполняются в нем
+	tx *sql.Tx
+	// forUpdate дописывается к чтению строки, которую транзакция собирается изменить
+	forUpdate string
 }
 
 func NewSubscriptionRepository(db *sql.DB) SubscriptionRepository {
+	return &subscriptionRepo{db: db, forUpdate: " FOR UPDATE"}
+}
+
+// NewSQLiteSubscriptionRepository создает хранилище поверх SQLite. Запросы общие
+// с PostgreSQL; транзакции должны открываться как BEGIN IMMEDIATE (см. database.NewSQLite).
+func NewSQLiteSubscriptionRepository(db *sql.DB) SubscriptionRepository {
 	return &subscriptionRepo{db: db}
 }
 
-func (r *subscriptionRepo) Create(sub *model.Subscription) (*model.Subscription, error) {
-	query := `INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-    VALUES ($1, $2, $3, $4, $5) RETURNING id`
+// conn возвращает текущую транзакцию или пул соединений
+func (r *subscriptionRepo) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+func (r *subscriptionRepo) WithTx(ctx context.Context, fn func(repo SubscriptionRepository) error) error {
+	return r.inTx(ctx, func(tx *subscriptionRepo) error {
+		return fn(tx)
+	})
+}
 
-	var createdID int
-	err := r.db.QueryRow(query, sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate).Scan(&createdID)
+// inTx выполняет fn в транзакции, открывая ее, если хранилище еще не в транзакции
+func (r *subscriptionRepo) inTx(ctx context.Context, fn func(tx *subscriptionRepo) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("Error creating subscription: %v", err)
-		return nil, fmt.Errorf("failed to create subscription: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	sub.ID = createdID
-	log.Printf("Subscription created successfully: %s", sub.ID)
-	return sub, nil
+	if err := fn(&subscriptionRepo{db: r.db, tx: tx, forUpdate: r.forUpdate}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
-func (r *subscriptionRepo) GetByID(id string) (*model.Subscription, error) {
+// scopeCondition дополняет запрос условием на пользователя, если вызывающий
+// ограничен своими данными.
+func scopeCondition(ctx context.Context, query string, args []interface{}) (string, []interface{}) {
+	userID, scoped := auth.UserScope(ctx)
+	if !scoped {
+		return query, args
+	}
+	args = append(args, userID)
+	return query + fmt.Sprintf(" AND user_id = $%d", len(args)), args
+}
 
-	query := `SELECT id, service_name, price, user_id, start_date, end_date
-    FROM subscriptions WHERE id=$1`
+const subscriptionColumns = `id, service_name, service_id, price, user_id, start_date, end_date,
+    category, custom_category, trial_months, trial_price, trial_ends_at, auto_renew, status, cancel_at, deleted_at`
 
+// scanSubscription читает подписку без тегов, скидок, участников и переходов; их загружает loadDetails
+func scanSubscription(row interface{ Scan(...interface{}) error }) (*model.Subscription, error) {
 	var sub model.Subscription
+	var serviceID sql.NullInt64
+	var trialEndsAt, cancelAt, deletedAt sql.NullTime
+	if err := row.Scan(&sub.ID, &sub.ServiceName, &serviceID, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate,
+		&sub.Category, &sub.CustomCategory, &sub.TrialMonths, &sub.TrialPrice, &trialEndsAt, &sub.AutoRenew, &sub.Status, &cancelAt, &deletedAt); err != nil {
+		return nil, err
+	}
+	if serviceID.Valid {
+		id := int(serviceID.Int64)
+		sub.ServiceID = &id
+	}
+	if trialEndsAt.Valid {
+		sub.TrialEndsAt = &trialEndsAt.Time
+	}
+	if cancelAt.Valid {
+		sub.CancelAt = &cancelAt.Time
+	}
+	if deletedAt.Valid {
+		sub.DeletedAt = &deletedAt.Time
+	}
+	return &sub, nil
+}
+
+func (r *subscriptionRepo) Create(ctx context.Context, sub *model.Subscription) (*model.Subscription, error) {
+	query := `INSERT INTO subscriptions (service_name, service_id, price, user_id, start_date, end_date, category, custom_category,
+    trial_months, trial_price, trial_ends_at, auto_renew)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING ` + subscriptionColumns
+
+	var created *model.Subscription
+	err := r.inTx(ctx, func(tx *subscriptionRepo) error {
+		if err := checkUserExists(ctx, tx.conn(), sub.UserID); err != nil {
+			return fmt.Errorf("failed to create subscription: %w", err)
+		}
+
+		var err error
+		created, err = scanSubscription(tx.conn().QueryRowContext(ctx, query, sub.ServiceName, sub.ServiceID, sub.Price, sub.UserID,
+			sub.StartDate, sub.EndDate, sub.Category, sub.Category != "", sub.TrialMonths, sub.TrialPrice, sub.TrialEndsAt, sub.AutoRenew))
+		if err != nil {
+			log.Printf("Error creating subscription: %v", err)
+			return fmt.Errorf("failed to create subscription: %w", err)
+		}
+
+		if err := refreshCategory(ctx, tx.conn(), created); err != nil {
+			return err
+		}
+		if err := replaceTags(ctx, tx.conn(), created.ID, sub.Tags); err != nil {
+			return err
+		}
+		created.Tags = sub.Tags
+		if err := replaceMembers(ctx, tx.conn(), created.ID, sub.Members); err != nil {
+			return err
+		}
+		created.Members = sub.Members
+
+		return writeAudit(ctx, tx.conn(), auditCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sub.ID = created.ID
+	sub.Category = created.Category
+	sub.CustomCategory = created.CustomCategory
+	sub.Status = created.Status
+	log.Printf("Subscription created successfully: %d", sub.ID)
+	return sub, nil
+}
+
+func (r *subscriptionRepo) GetByID(ctx context.Context, id string) (*model.Subscription, error) {
+
+	query := `SELECT ` + subscriptionColumns + `
+    FROM subscriptions WHERE id=$1 AND deleted_at IS NULL`
 
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid id format: must be integer")
+		return nil, ErrInvalidID
 	}
 
-	err = r.db.QueryRow(query, idInt).Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate)
+	query, args := visibleCondition(ctx, query, []interface{}{idInt})
+	sub, err := scanSubscription(r.conn().QueryRowContext(ctx, query, args...))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("subscription not found")
+		return nil, ErrNotFound
 	}
 
 	if err != nil {
 		log.Printf("Error getting subscription by ID: %v", err)
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
+	if err := loadDetails(ctx, r.conn(), sub); err != nil {
+		return nil, err
+	}
 
 	log.Printf("Subscription retrieved: %s", id)
-	return &sub, nil
+	return sub, nil
 }
 
-func (r *subscriptionRepo) Update(id string, req *model.UpdateSubscriptionRequest) error {
-	currentSub, err := r.GetByID(id)
+func (r *subscriptionRepo) GetForUpdate(ctx context.Context, id string) (*model.Subscription, error) {
+	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		return fmt.Errorf("failed to get current subscription: %w", err)
+		return nil, ErrInvalidID
+	}
+
+	if r.tx == nil {
+		return r.GetByID(ctx, id)
+	}
+	return r.lockSubscription(ctx, idInt, false)
+}
+
+func (r *subscriptionRepo) OwnerLocation(ctx context.Context, userID uuid.UUID) (*time.Location, error) {
+	return ownerLocation(ctx, r.conn(), userID)
+}
+
+// lockSubscription читает подписку внутри транзакции и блокирует строку до ее завершения.
+// В SQLite блокировку обеспечивает сама транзакция (BEGIN IMMEDIATE).
+func (r *subscriptionRepo) lockSubscription(ctx context.Context, id int, includeDeleted bool) (*model.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + `
+    FROM subscriptions WHERE id=$1`
+	if !includeDeleted {
+		query += ` AND deleted_at IS NULL`
 	}
 
-	log.Printf("Current subscription: ID=%d, EndDate=%v", currentSub.ID, currentSub.EndDate)
+	query, args := scopeCondition(ctx, query, []interface{}{id})
+	sub, err := scanSubscription(r.conn().QueryRowContext(ctx, query+r.forUpdate, args...))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error locking subscription: %v", err)
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if err := loadDetails(ctx, r.conn(), sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
 
+// Update изменяет переданные поля. Вместе с service_name всегда заменяется и
+// service_id: название, не найденное в каталоге, отвязывает подписку от сервиса.
+// Подписка без своей категории получает категорию нового сервиса.
+func (r *subscriptionRepo) Update(ctx context.Context, id string, req *model.UpdateSubscriptionRequest) error {
 	query := `UPDATE subscriptions 
 	SET service_name = COALESCE($1, service_name),price = COALESCE($2, price),
     user_id = COALESCE($3, user_id),start_date = COALESCE($4, start_date),
-    end_date = COALESCE($5, end_date) WHERE id = $6`
+    end_date = COALESCE($5, end_date),
+    service_id = CASE WHEN $7 THEN $8 ELSE service_id END,
+    category = CASE WHEN $9 THEN $10 ELSE category END,
+    custom_category = CASE WHEN $9 THEN $11 ELSE custom_category END,
+    trial_months = COALESCE($12, trial_months), trial_price = COALESCE($13, trial_price),
+    trial_ends_at = CASE WHEN $14 THEN $15 ELSE trial_ends_at END,
+    auto_renew = COALESCE($16, auto_renew) WHERE id = $6 RETURNING ` + subscriptionColumns
+
+	var category string
+	if req.Category != nil {
+		category = *req.Category
+	}
 
 	var startDate, endDate interface{}
 
 	if req.StartDate != nil && *req.StartDate != "" {
-		parsedDate, err := time.Parse("01-2006", *req.StartDate)
+		start, _, err := model.MonthRange(*req.StartDate, req.Location)
 		if err != nil {
 			return fmt.Errorf("invalid start_date format: %w", err)
 		}
-		startDate = parsedDate
-		endDate = parsedDate.AddDate(0, 1, 0)
-		log.Printf("New dates - start: %v, end: %v", startDate, endDate)
+		startDate = start
+		log.Printf("New start date: %v", startDate)
 	} else {
 		startDate = nil
-		endDate = nil
 	}
 
 	var serviceName, price, userID interface{}
@@ -124,56 +341,200 @@ func (r *subscriptionRepo) Update(id string, req *model.UpdateSubscriptionReques
 
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		return fmt.Errorf("invalid id format: must be integer")
+		return ErrInvalidID
 	}
 
-	log.Printf("Executing update: service=%v, price=%v, user=%v, start=%v, end=%v",
-		serviceName, price, userID, startDate, endDate)
+	err = r.inTx(ctx, func(tx *subscriptionRepo) error {
+		currentSub, err := tx.lockSubscription(ctx, idInt, false)
+		if err != nil {
+			return fmt.Errorf("failed to get current subscription: %w", err)
+		}
+
+		log.Printf("Current subscription: ID=%d, EndDate=%v", currentSub.ID, currentSub.EndDate)
+
+		if userID != nil {
+			if err := checkUserExists(ctx, tx.conn(), userID); err != nil {
+				return fmt.Errorf("failed to update subscription: %w", err)
+			}
+		}
+
+		// Дата окончания и конец пробного периода пересчитываются от начала подписки
+		// и длины пробного периода, если изменилось одно из них
+		var trialEndsAt *time.Time
+		datesChanged := startDate != nil || req.TrialMonths != nil
+		if datesChanged {
+			start, trialMonths := currentSub.StartDate, currentSub.TrialMonths
+			if startDate != nil {
+				start = startDate.(time.Time)
+			}
+			if req.TrialMonths != nil {
+				trialMonths = *req.TrialMonths
+			}
+			var end time.Time
+			trialEndsAt, end = model.SubscriptionDates(start, trialMonths, req.Location)
+			startDate, endDate = start, end
+		}
 
-	result, err := r.db.Exec(query, serviceName, price, userID, startDate, endDate, idInt)
+		log.Printf("Executing update: service=%v, price=%v, user=%v, start=%v, end=%v",
+			serviceName, price, userID, startDate, endDate)
+
+		updatedSub, err := scanSubscription(tx.conn().QueryRowContext(ctx, query, serviceName, price, userID, startDate, endDate, idInt,
+			req.ServiceName != nil, req.ServiceID, req.Category != nil, category, category != "",
+			req.TrialMonths, req.TrialPrice, datesChanged, trialEndsAt, req.AutoRenew))
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			log.Printf("Error updating subscription: %v", err)
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+
+		if err := refreshCategory(ctx, tx.conn(), updatedSub); err != nil {
+			return err
+		}
+		updatedSub.Tags = currentSub.Tags
+		updatedSub.Discounts = currentSub.Discounts
+		updatedSub.Members = currentSub.Members
+		updatedSub.Transitions = currentSub.Transitions
+		if req.Tags != nil {
+			if err := replaceTags(ctx, tx.conn(), idInt, req.Tags); err != nil {
+				return err
+			}
+			updatedSub.Tags = req.Tags
+		}
+		if req.Members != nil {
+			if err := replaceMembers(ctx, tx.conn(), idInt, req.Members); err != nil {
+				return err
+			}
+			updatedSub.Members = req.Members
+		}
+
+		return writeAudit(ctx, tx.conn(), auditUpdate, currentSub, updatedSub)
+	})
 	if err != nil {
-		log.Printf("Error updating subscription: %v", err)
-		return fmt.Errorf("failed to update subscription: %w", err)
+		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("subscription not found")
+	log.Printf("Subscription updated successfully: %s", id)
+	return nil
+}
+
+// Delete помечает подписку удаленной; permanent удаляет строку сразу, в том числе
+// уже помеченную. Помеченные подписки исключаются из всех чтений и расчетов.
+func (r *subscriptionRepo) Delete(ctx context.Context, id string, permanent bool) error {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return ErrInvalidID
 	}
 
-	log.Printf("Subscription updated successfully: %s", id)
+	err = r.inTx(ctx, func(tx *subscriptionRepo) error {
+		before, err := tx.lockSubscription(ctx, idInt, true)
+		if err != nil {
+			return err
+		}
+
+		var after *model.Subscription
+		if permanent {
+			_, err = tx.conn().ExecContext(ctx, `DELETE FROM subscriptions WHERE id = $1`, idInt)
+		} else {
+			if before.DeletedAt != nil {
+				return ErrNotFound
+			}
+			query := `UPDATE subscriptions SET deleted_at = $1 WHERE id = $2 RETURNING ` + subscriptionColumns
+			after, err = scanSubscription(tx.conn().QueryRowContext(ctx, query, time.Now().UTC(), idInt))
+		}
+		if err != nil {
+			log.Printf("Error deleting subscription: %v", err)
+			return fmt.Errorf("failed to delete subscription: %w", err)
+		}
+		if after != nil {
+			after.Tags = before.Tags
+			after.Discounts = before.Discounts
+			after.Members = before.Members
+			after.Transitions = before.Transitions
+		}
+
+		return writeAudit(ctx, tx.conn(), auditDelete, before, after)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Subscription deleted (permanent=%t): %s", permanent, id)
 	return nil
 }
 
-func (r *subscriptionRepo) Delete(id string) error {
-	query := `DELETE FROM subscriptions WHERE id=$1`
+func (r *subscriptionRepo) Restore(ctx context.Context, id string) error {
+	query := `UPDATE subscriptions SET deleted_at = NULL
+    WHERE id = $1 AND deleted_at IS NOT NULL RETURNING ` + subscriptionColumns
 
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		return fmt.Errorf("invalid id format: must be integer")
+		return ErrInvalidID
 	}
 
-	result, err := r.db.Exec(query, idInt)
+	err = r.inTx(ctx, func(tx *subscriptionRepo) error {
+		before, err := tx.lockSubscription(ctx, idInt, true)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt == nil {
+			return ErrNotDeleted
+		}
+
+		after, err := scanSubscription(tx.conn().QueryRowContext(ctx, query, idInt))
+		if err != nil {
+			log.Printf("Error restoring subscription: %v", err)
+			return fmt.Errorf("failed to restore subscription: %w", err)
+		}
+		after.Tags = before.Tags
+		after.Discounts = before.Discounts
+		after.Members = before.Members
+		after.Transitions = before.Transitions
+
+		return writeAudit(ctx, tx.conn(), auditRestore, before, after)
+	})
 	if err != nil {
-		log.Printf("Error deleting subscription: %v", err)
-		return fmt.Errorf("failed to delete subscription: %w", err)
+		return err
 	}
 
-	rewsAffected, _ := result.RowsAffected()
+	log.Printf("Subscription restored: %s", id)
+	return nil
+}
 
-	if rewsAffected == 0 {
-		return fmt.Errorf("subscription not found")
+// PurgeDeleted окончательно удаляет подписки, помеченные удаленными раньше deletedBefore.
+// История изменений в журнале аудита сохраняется.
+func (r *subscriptionRepo) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := `DELETE FROM subscriptions WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := r.conn().ExecContext(ctx, query, deletedBefore)
+	if err != nil {
+		log.Printf("Error purging deleted subscriptions: %v", err)
+		return 0, fmt.Errorf("failed to purge deleted subscriptions: %w", err)
 	}
 
-	log.Printf("Subscription deleted: %s", id)
-	return nil
+	purged, _ := result.RowsAffected()
+	return purged, nil
 }
 
-func (r *subscriptionRepo) List(limit, offset int) ([]*model.Subscription, error) {
-	query := `SELECT id, service_name, price, user_id, start_date, end_date
-	FROM subscriptions LIMIT $1 OFFSET $2`
+func (r *subscriptionRepo) List(ctx context.Context, filter model.SubscriptionFilter, limit, offset int) ([]*model.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + `
+	FROM subscriptions WHERE deleted_at IS NULL`
 
-	rows, err := r.db.Query(query, limit, offset)
+	query, args := visibleCondition(ctx, query, nil)
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		query += fmt.Sprintf(" AND category = $%d", len(args))
+	}
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		query += fmt.Sprintf(` AND EXISTS (SELECT 1 FROM subscription_tags
+        WHERE subscription_tags.subscription_id = subscriptions.id AND tag = $%d)`, len(args))
+	}
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.conn().QueryContext(ctx, query, args...)
 
 	if err != nil {
 		log.Printf("Error listing subscriptions: %v", err)
@@ -185,50 +546,115 @@ func (r *subscriptionRepo) List(limit, offset int) ([]*model.Subscription, error
 	var subscriptions []*model.Subscription
 
 	for rows.Next() {
-		var sub model.Subscription
-
-		err := rows.Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate)
+		sub, err := scanSubscription(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan subscription: %w", err)
 		}
 
-		subscriptions = append(subscriptions, &sub)
+		subscriptions = append(subscriptions, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	rows.Close()
+
+	if err := loadDetails(ctx, r.conn(), subscriptions...); err != nil {
+		return nil, err
 	}
 
 	log.Printf("Listed %d subscriptions", len(subscriptions))
 	return subscriptions, nil
 }
 
-func (r *subscriptionRepo) CalculateTotalCost(req *model.CalculateCostRequest) (int, error) {
-	query := `SELECT COALESCE(SUM(price), 0) FROM subscriptions 
-    WHERE start_date <= $1 AND (end_date IS NULL OR end_date >= $2)
-    AND user_id = $3 AND service_name = $4`
-
+// costSubscriptions возвращает подписки пользователя на сервис из каталога (ServiceID)
+// или с точным названием, которые могли выставить счет в периоде расчета, вместе
+// с тегами, скидками и участниками. Без сервиса учитываются подписки на все сервисы.
+// С точки зрения участника в расчет входят и общие подписки, где он участвует.
+func (r *subscriptionRepo) costSubscriptions(ctx context.Context, req *model.CalculateCostRequest) ([]*model.Subscription, error) {
 	userID, err := uuid.Parse(req.UserID)
 	if err != nil {
-		return 0, fmt.Errorf("invalid user_id format: %w", err)
+		return nil, fmt.Errorf("invalid user_id format: %w", err)
 	}
 
-	startPeriod, err := time.Parse("01-2006", req.StartPeriod)
+	from, to, err := costPeriod(req)
 	if err != nil {
-		return 0, fmt.Errorf("invalid start_period format: %w", err)
+		return nil, err
 	}
 
-	endPeriod, err := time.Parse("01-2006", req.EndPeriod)
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions
+    WHERE start_date < $1 AND end_date > $2 AND user_id = $3 AND deleted_at IS NULL`
+	if req.Perspective == model.PerspectiveMember {
+		query = `SELECT ` + subscriptionColumns + ` FROM subscriptions
+    WHERE start_date < $1 AND end_date > $2 AND deleted_at IS NULL AND (user_id = $3
+    OR id IN (SELECT subscription_id FROM subscription_members WHERE user_id = $3))`
+	}
+	args := []interface{}{to, from, userID}
+
+	switch {
+	case req.ServiceID != nil:
+		args = append(args, *req.ServiceID)
+		query += fmt.Sprintf(" AND service_id = $%d", len(args))
+	case req.ServiceName != "":
+		args = append(args, req.ServiceName)
+		query += fmt.Sprintf(" AND service_name = $%d", len(args))
+	}
 
+	if req.Perspective == model.PerspectiveMember {
+		query, args = visibleCondition(ctx, query, args)
+	} else {
+		query, args = scopeCondition(ctx, query, args)
+	}
+	rows, err := r.conn().QueryContext(ctx, query+` ORDER BY id`, args...)
 	if err != nil {
-		return 0, fmt.Errorf("invalid end_period format: %w", err)
+		log.Printf("Error getting subscriptions for cost: %v", err)
+		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
 	}
+	defer rows.Close()
 
-	var totalCost int
-	err = r.db.QueryRow(query, endPeriod, startPeriod, userID, req.ServiceName).Scan(&totalCost)
+	var subs []*model.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+	rows.Close()
 
+	if err := loadDetails(ctx, r.conn(), subs...); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (r *subscriptionRepo) CalculateTotalCost(ctx context.Context, req *model.CalculateCostRequest) (int, error) {
+	subs, err := r.costSubscriptions(ctx, req)
 	if err != nil {
-		log.Printf("Error calculating total cost: %v", err)
-		return 0, fmt.Errorf("failed to calculate total cost: %w", err)
+		return 0, err
 	}
 
+	cycles, err := billedCycles(subs, req)
+	if err != nil {
+		return 0, err
+	}
+	totalCost := sumCycles(cycles)
+
 	log.Printf("Total cost calculated: %d for period %s to %s", totalCost, req.StartPeriod, req.EndPeriod)
 	return totalCost, nil
+}
+
+func (r *subscriptionRepo) CostBreakdown(ctx context.Context, req *model.CalculateCostRequest) ([]*model.CostGroup, error) {
+	subs, err := r.costSubscriptions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
 
+	cycles, err := billedCycles(subs, req)
+	if err != nil {
+		return nil, err
+	}
+	return groupCycles(cycles, req.GroupBy, req.Location)
 }
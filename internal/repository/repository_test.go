@@ -0,0 +1,30 @@
+package repository_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository/repositorytest"
+)
+
+// runContracts прогоняет все общие наборы проверок на хранилищах из newRepos
+func runContracts(t *testing.T, newRepos repositorytest.Factory) {
+	t.Run("Subscriptions", func(t *testing.T) { repositorytest.RunSubscriptionRepositoryContract(t, newRepos) })
+	t.Run("ServiceCatalog", func(t *testing.T) { repositorytest.RunServiceCatalogRepositoryContract(t, newRepos) })
+	t.Run("Users", func(t *testing.T) { repositorytest.RunUserRepositoryContract(t, newRepos) })
+	t.Run("Notifications", func(t *testing.T) { repositorytest.RunNotificationRepositoryContract(t, newRepos) })
+	t.Run("Webhooks", func(t *testing.T) { repositorytest.RunWebhookRepositoryContract(t, newRepos) })
+}
+
+// dbRepositories возвращает хранилища поверх базы db; подписки создаются
+// конструктором для ее диалекта
+func dbRepositories(db *sql.DB, subscriptions repository.SubscriptionRepository) repositorytest.Repositories {
+	return repositorytest.Repositories{
+		Subscriptions: subscriptions,
+		Services:      repository.NewServiceCatalogRepository(db),
+		Users:         repository.NewUserRepository(db),
+		Notifications: repository.NewNotificationRepository(db),
+		Webhooks:      repository.NewWebhookRepository(db),
+	}
+}
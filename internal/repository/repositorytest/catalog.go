@@ -0,0 +1,183 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+func createService(t *testing.T, catalog repository.ServiceCatalogRepository, name string, aliases ...string) *model.Service {
+	t.Helper()
+
+	svc, err := catalog.Create(context.Background(), &model.Service{
+		Name:    name,
+		Aliases: aliases,
+		Plans:   []model.ServicePlan{{Name: "Базовый", Price: 299}},
+	})
+	if err != nil {
+		t.Fatalf("Create service: %v", err)
+	}
+	if svc.ID == 0 {
+		t.Fatalf("Create service: expected assigned ID")
+	}
+	return svc
+}
+
+func serviceID(svc *model.Service) string {
+	return strconv.Itoa(svc.ID)
+}
+
+// RunServiceCatalogRepositoryContract проверяет реализацию ServiceCatalogRepository
+// и ее связь с подписками
+func RunServiceCatalogRepositoryContract(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+	newCatalog := func(t *testing.T) (repository.SubscriptionRepository, repository.ServiceCatalogRepository) {
+		repos := withUsers(t, newRepos)
+		return repos.Subscriptions, repos.Services
+	}
+
+	t.Run("CreateAndResolve", func(t *testing.T) {
+		_, catalog := newCatalog(t)
+		created := createService(t, catalog, "Yandex Plus", "Яндекс Плюс")
+
+		got, err := catalog.GetByID(ctx, serviceID(created))
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Yandex Plus" || len(got.Aliases) != 1 || len(got.Plans) != 1 || got.Plans[0].Price != 299 {
+			t.Errorf("GetByID: unexpected service %+v", got)
+		}
+
+		for _, name := range []string{"Yandex Plus", "  yandex   PLUS ", "яндекс плюс"} {
+			resolved, err := catalog.Resolve(ctx, name)
+			if err != nil || resolved.ID != created.ID {
+				t.Errorf("Resolve %q: expected service %d, got %+v (%v)", name, created.ID, resolved, err)
+			}
+		}
+		if _, err := catalog.Resolve(ctx, "Netflix"); !errors.Is(err, repository.ErrServiceNotFound) {
+			t.Errorf("Resolve unknown: expected ErrServiceNotFound, got %v", err)
+		}
+		if _, err := catalog.GetByID(ctx, "42"); !errors.Is(err, repository.ErrServiceNotFound) {
+			t.Errorf("GetByID missing: expected ErrServiceNotFound, got %v", err)
+		}
+
+		list, err := catalog.List(ctx)
+		if err != nil || len(list) != 1 {
+			t.Errorf("List: expected 1 service, got %d (%v)", len(list), err)
+		}
+	})
+
+	t.Run("Conflicts", func(t *testing.T) {
+		_, catalog := newCatalog(t)
+		yandex := createService(t, catalog, "Yandex Plus", "Яндекс Плюс")
+		netflix := createService(t, catalog, "Netflix")
+
+		if _, err := catalog.Create(ctx, &model.Service{Name: "ЯНДЕКС ПЛЮС"}); !errors.Is(err, repository.ErrServiceConflict) {
+			t.Errorf("Create with taken alias: expected ErrServiceConflict, got %v", err)
+		}
+		_, err := catalog.Update(ctx, serviceID(netflix), &model.Service{Name: "Netflix", Aliases: []string{"yandex plus"}})
+		if !errors.Is(err, repository.ErrServiceConflict) {
+			t.Errorf("Update with taken name: expected ErrServiceConflict, got %v", err)
+		}
+		if resolved, err := catalog.Resolve(ctx, "netflix"); err != nil || resolved.ID != netflix.ID {
+			t.Errorf("Update conflict: expected service to stay unchanged, got %+v (%v)", resolved, err)
+		}
+
+		// Свои название и псевдонимы при изменении конфликтом не считаются
+		if _, err := catalog.Update(ctx, serviceID(yandex), &model.Service{Name: "Яндекс Плюс", Aliases: []string{"Yandex Plus"}}); err != nil {
+			t.Errorf("Update with own names: %v", err)
+		}
+		if _, err := catalog.Update(ctx, "42", &model.Service{Name: "Missing"}); !errors.Is(err, repository.ErrServiceNotFound) {
+			t.Errorf("Update missing: expected ErrServiceNotFound, got %v", err)
+		}
+	})
+
+	t.Run("LinkSubscriptions", func(t *testing.T) {
+		subs, catalog := newCatalog(t)
+		first := create(t, subs, "yandex plus", 300, alice, "07-2025")
+		second := create(t, subs, "Яндекс Плюс", 400, alice, "07-2025")
+		other := create(t, subs, "Netflix", 800, alice, "07-2025")
+
+		svc := createService(t, catalog, "Yandex Plus", "Яндекс Плюс")
+
+		for _, sub := range []*model.Subscription{first, second} {
+			got, err := subs.GetByID(ctx, id(sub))
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if got.ServiceID == nil || *got.ServiceID != svc.ID || got.ServiceName != "Yandex Plus" {
+				t.Errorf("Create service: expected subscription %d to be linked, got %+v", sub.ID, got)
+			}
+		}
+		if got, _ := subs.GetByID(ctx, id(other)); got == nil || got.ServiceID != nil {
+			t.Errorf("Create service: expected unrelated subscription to stay unlinked, got %+v", got)
+		}
+
+		total, err := subs.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+			UserID:      alice.String(),
+			ServiceID:   &svc.ID,
+			StartPeriod: "07-2025",
+			EndPeriod:   "07-2025",
+		})
+		if err != nil || total != 700 {
+			t.Errorf("CalculateTotalCost by service: expected 700, got %d (%v)", total, err)
+		}
+
+		err = subs.Update(ctx, id(second), &model.UpdateSubscriptionRequest{Category: strPtr("music")})
+		if err != nil {
+			t.Fatalf("Update subscription: %v", err)
+		}
+		if _, err := catalog.Update(ctx, serviceID(svc), &model.Service{Name: "Яндекс Плюс", Category: "streaming"}); err != nil {
+			t.Fatalf("Update service: %v", err)
+		}
+		if got, _ := subs.GetByID(ctx, id(first)); got == nil || got.ServiceName != "Яндекс Плюс" || got.Category != "streaming" {
+			t.Errorf("Update service: expected linked subscription to get new name and category, got %+v", got)
+		}
+		if got, _ := subs.GetByID(ctx, id(second)); got == nil || got.Category != "music" {
+			t.Errorf("Update service: expected custom category to be kept, got %+v", got)
+		}
+
+		err = subs.Update(ctx, id(first), &model.UpdateSubscriptionRequest{ServiceName: strPtr("Something else")})
+		if err != nil {
+			t.Fatalf("Update subscription: %v", err)
+		}
+		if got, _ := subs.GetByID(ctx, id(first)); got == nil || got.ServiceID != nil {
+			t.Errorf("Update name without service_id: expected subscription to be unlinked, got %+v", got)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		subs, catalog := newCatalog(t)
+		sub := create(t, subs, "Yandex Plus", 300, alice, "07-2025")
+		used := createService(t, catalog, "Yandex Plus")
+		unused := createService(t, catalog, "Netflix")
+
+		if err := subs.Delete(ctx, id(sub), false); err != nil {
+			t.Fatalf("Delete subscription: %v", err)
+		}
+
+		if err := catalog.Delete(ctx, serviceID(used)); !errors.Is(err, repository.ErrServiceInUse) {
+			t.Errorf("Delete service with deleted subscription: expected ErrServiceInUse, got %v", err)
+		}
+		if err := catalog.Delete(ctx, serviceID(unused)); err != nil {
+			t.Errorf("Delete unused service: %v", err)
+		}
+		if _, err := catalog.Resolve(ctx, "Netflix"); !errors.Is(err, repository.ErrServiceNotFound) {
+			t.Errorf("Delete: expected names of deleted service to be released, got %v", err)
+		}
+		if err := catalog.Delete(ctx, serviceID(unused)); !errors.Is(err, repository.ErrServiceNotFound) {
+			t.Errorf("Delete missing: expected ErrServiceNotFound, got %v", err)
+		}
+
+		if err := subs.Delete(ctx, id(sub), true); err != nil {
+			t.Fatalf("Delete subscription permanently: %v", err)
+		}
+		if err := catalog.Delete(ctx, serviceID(used)); err != nil {
+			t.Errorf("Delete service after purge: %v", err)
+		}
+	})
+}
@@ -0,0 +1,808 @@
+// Package repositorytest содержит общие наборы проверок поведения
+// repository.SubscriptionRepository, repository.ServiceCatalogRepository,
+// repository.UserRepository, repository.NotificationRepository и repository.WebhookRepository.
+// Любая реализация хранилища должна их проходить; реализация подключает набор
+// в своем тесте, передавая фабрику пустых хранилищ:
+//
+//	func TestMemorySubscriptionRepository(t *testing.T) {
+//		repositorytest.RunSubscriptionRepositoryContract(t, func(t *testing.T) repositorytest.Repositories {
+//			subscriptions := repository.NewMemorySubscriptionRepository()
+//			return repositorytest.Repositories{
+//				Subscriptions: subscriptions,
+//				Services:      repository.NewMemoryServiceCatalogRepository(subscriptions),
+//				Users:         repository.NewMemoryUserRepository(subscriptions),
+//				Notifications: repository.NewMemoryNotificationRepository(subscriptions),
+//				Webhooks:      repository.NewMemoryWebhookRepository(subscriptions),
+//			}
+//		})
+//	}
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Repositories — хранилища одной проверки с общими данными
+type Repositories struct {
+	Subscriptions repository.SubscriptionRepository
+	Services      repository.ServiceCatalogRepository
+	Users         repository.UserRepository
+	Notifications repository.NotificationRepository
+	Webhooks      repository.WebhookRepository
+}
+
+// Factory возвращает пустые хранилища для одной проверки
+type Factory func(t *testing.T) Repositories
+
+var (
+	alice = uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	bob   = uuid.MustParse("0b9b2c55-7c7a-4c61-9d8c-1f6e2a3b4c5d")
+)
+
+func month(value string) time.Time {
+	parsed, err := time.Parse("01-2006", value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func strPtr(s string) *string { return &s }
+func intPtr(i int) *int       { return &i }
+
+func asUser(userID uuid.UUID) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID})
+}
+
+func asAdmin() context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Admin: true})
+}
+
+// withUsers создает хранилища и заводит в них пользователей alice и bob,
+// которым принадлежат подписки проверок
+func withUsers(t *testing.T, newRepos Factory) Repositories {
+	t.Helper()
+
+	repos := newRepos(t)
+	for _, userID := range []uuid.UUID{alice, bob} {
+		if _, err := repos.Users.Create(context.Background(), &model.User{ID: userID, Timezone: "UTC", Currency: "RUB"}); err != nil {
+			t.Fatalf("Create user: %v", err)
+		}
+	}
+	return repos
+}
+
+func create(t *testing.T, repo repository.SubscriptionRepository, serviceName string, price int, userID uuid.UUID, start string) *model.Subscription {
+	t.Helper()
+
+	startDate := month(start)
+	sub, err := repo.Create(context.Background(), &model.Subscription{
+		ServiceName: serviceName,
+		Price:       price,
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     startDate.AddDate(0, 1, 0),
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if sub.ID == 0 {
+		t.Fatalf("Create: expected assigned ID")
+	}
+	return sub
+}
+
+func id(sub *model.Subscription) string {
+	return strconv.Itoa(sub.ID)
+}
+
+// RunSubscriptionRepositoryContract проверяет реализацию SubscriptionRepository
+func RunSubscriptionRepositoryContract(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+	newRepo := func(t *testing.T) repository.SubscriptionRepository {
+		return withUsers(t, newRepos).Subscriptions
+	}
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+		created := create(t, repo, "Yandex Plus", 400, alice, "07-2025")
+
+		got, err := repo.GetByID(ctx, id(created))
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.ServiceName != "Yandex Plus" || got.Price != 400 || got.UserID != alice {
+			t.Errorf("GetByID: unexpected subscription %+v", got)
+		}
+		if !got.StartDate.Equal(month("07-2025")) || !got.EndDate.Equal(month("08-2025")) {
+			t.Errorf("GetByID: unexpected dates %v - %v", got.StartDate, got.EndDate)
+		}
+	})
+
+	t.Run("GetErrors", func(t *testing.T) {
+		repo := newRepo(t)
+		if _, err := repo.GetByID(ctx, "42"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetByID missing: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetByID(ctx, "abc"); err == nil {
+			t.Errorf("GetByID invalid id: expected error")
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		created := create(t, repo, "Yandex Plus", 400, alice, "07-2025")
+
+		err := repo.Update(ctx, id(created), &model.UpdateSubscriptionRequest{
+			Price:     intPtr(500),
+			StartDate: strPtr("09-2025"),
+		})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		got, err := repo.GetByID(ctx, id(created))
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Price != 500 || got.ServiceName != "Yandex Plus" {
+			t.Errorf("Update: unexpected subscription %+v", got)
+		}
+		if !got.StartDate.Equal(month("09-2025")) || !got.EndDate.Equal(month("10-2025")) {
+			t.Errorf("Update: unexpected dates %v - %v", got.StartDate, got.EndDate)
+		}
+
+		if err := repo.Update(ctx, "42", &model.UpdateSubscriptionRequest{Price: intPtr(1)}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Update missing: expected ErrNotFound, got %v", err)
+		}
+		if err := repo.Update(ctx, id(created), &model.UpdateSubscriptionRequest{UserID: strPtr("bad")}); err == nil {
+			t.Errorf("Update invalid user_id: expected error")
+		}
+	})
+
+	t.Run("UserScope", func(t *testing.T) {
+		repo := newRepo(t)
+		alices := create(t, repo, "Yandex Plus", 400, alice, "07-2025")
+		create(t, repo, "Netflix", 800, bob, "07-2025")
+
+		if _, err := repo.GetByID(asUser(bob), id(alices)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetByID by another user: expected ErrNotFound, got %v", err)
+		}
+		if err := repo.Update(asUser(bob), id(alices), &model.UpdateSubscriptionRequest{Price: intPtr(1)}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Update by another user: expected ErrNotFound, got %v", err)
+		}
+		if err := repo.Delete(asUser(bob), id(alices), false); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Delete by another user: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetByID(asAdmin(), id(alices)); err != nil {
+			t.Errorf("GetByID by admin: %v", err)
+		}
+
+		list, err := repo.List(asUser(alice), model.SubscriptionFilter{}, 10, 0)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(list) != 1 || list[0].UserID != alice {
+			t.Errorf("List scoped to user: unexpected result %+v", list)
+		}
+
+		total, err := repo.CalculateTotalCost(asUser(bob), &model.CalculateCostRequest{
+			UserID: alice.String(), ServiceName: "Yandex Plus", StartPeriod: "07-2025", EndPeriod: "07-2025",
+		})
+		if err != nil || total != 0 {
+			t.Errorf("CalculateTotalCost by another user: expected 0, got %d (%v)", total, err)
+		}
+	})
+
+	t.Run("ListPagination", func(t *testing.T) {
+		repo := newRepo(t)
+		var ids []int
+		for i := 0; i < 5; i++ {
+			ids = append(ids, create(t, repo, "Service", 100, alice, "01-2025").ID)
+		}
+
+		page, err := repo.List(ctx, model.SubscriptionFilter{}, 2, 1)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(page) != 2 || page[0].ID != ids[1] || page[1].ID != ids[2] {
+			t.Errorf("List(2, 1): unexpected page %+v", page)
+		}
+
+		page, err = repo.List(ctx, model.SubscriptionFilter{}, 10, 5)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(page) != 0 {
+			t.Errorf("List past the end: expected empty page, got %d items", len(page))
+		}
+	})
+
+	t.Run("SoftDeleteAndRestore", func(t *testing.T) {
+		repo := newRepo(t)
+		created := create(t, repo, "Yandex Plus", 400, alice, "07-2025")
+
+		if err := repo.Restore(ctx, id(created)); !errors.Is(err, repository.ErrNotDeleted) {
+			t.Errorf("Restore active: expected ErrNotDeleted, got %v", err)
+		}
+		if err := repo.Delete(ctx, id(created), false); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, id(created)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetByID deleted: expected ErrNotFound, got %v", err)
+		}
+		if err := repo.Delete(ctx, id(created), false); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Delete twice: expected ErrNotFound, got %v", err)
+		}
+		if list, _ := repo.List(ctx, model.SubscriptionFilter{}, 10, 0); len(list) != 0 {
+			t.Errorf("List: deleted subscription is listed")
+		}
+
+		if err := repo.Restore(ctx, id(created)); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, id(created)); err != nil {
+			t.Errorf("GetByID restored: %v", err)
+		}
+
+		if err := repo.Delete(ctx, id(created), true); err != nil {
+			t.Fatalf("Delete permanent: %v", err)
+		}
+		if err := repo.Restore(ctx, id(created)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Restore purged: expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("PurgeDeleted", func(t *testing.T) {
+		repo := newRepo(t)
+		deleted := create(t, repo, "Yandex Plus", 400, alice, "07-2025")
+		active := create(t, repo, "Netflix", 800, alice, "07-2025")
+
+		if err := repo.Delete(ctx, id(deleted), false); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		purged, err := repo.PurgeDeleted(ctx, time.Now().UTC().Add(-time.Hour))
+		if err != nil || purged != 0 {
+			t.Errorf("PurgeDeleted before deletion time: expected 0, got %d (%v)", purged, err)
+		}
+
+		purged, err = repo.PurgeDeleted(ctx, time.Now().UTC().Add(time.Hour))
+		if err != nil || purged != 1 {
+			t.Errorf("PurgeDeleted: expected 1, got %d (%v)", purged, err)
+		}
+		if err := repo.Restore(ctx, id(deleted)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Restore purged: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetByID(ctx, id(active)); err != nil {
+			t.Errorf("GetByID active after purge: %v", err)
+		}
+	})
+
+	t.Run("CalculateTotalCost", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "Yandex Plus", 400, alice, "01-2025")
+		create(t, repo, "Yandex Plus", 400, alice, "02-2025")
+		create(t, repo, "Yandex Plus", 400, alice, "06-2025")
+		create(t, repo, "Netflix", 800, alice, "01-2025")
+		create(t, repo, "Yandex Plus", 400, bob, "01-2025")
+		deleted := create(t, repo, "Yandex Plus", 400, alice, "03-2025")
+		if err := repo.Delete(ctx, id(deleted), false); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		total, err := repo.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+			UserID: alice.String(), ServiceName: "Yandex Plus", StartPeriod: "01-2025", EndPeriod: "03-2025",
+		})
+		if err != nil {
+			t.Fatalf("CalculateTotalCost: %v", err)
+		}
+		if total != 800 {
+			t.Errorf("CalculateTotalCost: expected 800, got %d", total)
+		}
+
+		if _, err := repo.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+			UserID: "bad", ServiceName: "Yandex Plus", StartPeriod: "01-2025", EndPeriod: "03-2025",
+		}); err == nil {
+			t.Errorf("CalculateTotalCost invalid user_id: expected error")
+		}
+	})
+
+	t.Run("CategoriesAndTags", func(t *testing.T) {
+		repo := newRepo(t)
+		startDate := month("01-2025")
+		tagged, err := repo.Create(ctx, &model.Subscription{
+			ServiceName: "Yandex Plus",
+			Price:       400,
+			UserID:      alice,
+			StartDate:   startDate,
+			EndDate:     startDate.AddDate(0, 1, 0),
+			Category:    "streaming",
+			Tags:        []string{"family", "music"},
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if !tagged.CustomCategory {
+			t.Errorf("Create with category: expected custom category")
+		}
+		plain := create(t, repo, "Netflix", 800, alice, "01-2025")
+
+		got, err := repo.GetByID(ctx, id(tagged))
+		if err != nil || got.Category != "streaming" || !slices.Equal(got.Tags, []string{"family", "music"}) {
+			t.Errorf("GetByID: expected category and tags to round-trip, got %+v (%v)", got, err)
+		}
+
+		list, err := repo.List(ctx, model.SubscriptionFilter{Tag: "music"}, 10, 0)
+		if err != nil || len(list) != 1 || list[0].ID != tagged.ID {
+			t.Errorf("List by tag: expected subscription %d, got %d (%v)", tagged.ID, len(list), err)
+		}
+		list, err = repo.List(ctx, model.SubscriptionFilter{Category: "streaming", Tag: "work"}, 10, 0)
+		if err != nil || len(list) != 0 {
+			t.Errorf("List by category and missing tag: expected nothing, got %d (%v)", len(list), err)
+		}
+
+		err = repo.Update(ctx, id(plain), &model.UpdateSubscriptionRequest{Category: strPtr("streaming"), Tags: []string{"work"}})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		err = repo.Update(ctx, id(tagged), &model.UpdateSubscriptionRequest{Tags: []string{}})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if got, _ := repo.GetByID(ctx, id(tagged)); got == nil || len(got.Tags) != 0 || got.Category != "streaming" {
+			t.Errorf("Update with empty tags: expected tags to be cleared, got %+v", got)
+		}
+
+		groups, err := repo.CostBreakdown(ctx, &model.CalculateCostRequest{
+			UserID: alice.String(), StartPeriod: "01-2025", EndPeriod: "01-2025", GroupBy: model.GroupByCategory,
+		})
+		if err != nil || len(groups) != 1 || groups[0].Key != "streaming" || groups[0].TotalCost != 1200 {
+			t.Errorf("CostBreakdown by category: expected streaming=1200, got %d groups (%v)", len(groups), err)
+		}
+		groups, err = repo.CostBreakdown(ctx, &model.CalculateCostRequest{
+			UserID: alice.String(), StartPeriod: "01-2025", EndPeriod: "01-2025", GroupBy: model.GroupByTag,
+		})
+		if err != nil || len(groups) != 2 || groups[0].Key != "" || groups[0].TotalCost != 400 || groups[1].Key != "work" || groups[1].TotalCost != 800 {
+			t.Errorf("CostBreakdown by tag: expected untagged=400 and work=800, got %d groups (%v)", len(groups), err)
+		}
+	})
+
+	t.Run("Trial", func(t *testing.T) {
+		repo := newRepo(t)
+		startDate := month("01-2025")
+		trialEndsAt, endDate := model.SubscriptionDates(startDate, 2, nil)
+		trial, err := repo.Create(ctx, &model.Subscription{
+			ServiceName: "Yandex Plus",
+			Price:       500,
+			UserID:      alice,
+			StartDate:   startDate,
+			EndDate:     endDate,
+			TrialMonths: 2,
+			TrialPrice:  99,
+			TrialEndsAt: trialEndsAt,
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := repo.GetByID(ctx, id(trial))
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.TrialMonths != 2 || got.TrialPrice != 99 || got.TrialEndsAt == nil ||
+			!got.TrialEndsAt.Equal(month("03-2025")) || !got.EndDate.Equal(month("04-2025")) {
+			t.Errorf("GetByID: expected trial until 03-2025 and end 04-2025, got %+v", got)
+		}
+
+		for _, tc := range []struct {
+			start, end string
+			want       int
+		}{{"01-2025", "01-2025", 99}, {"01-2025", "02-2025", 99}, {"03-2025", "03-2025", 500}, {"01-2025", "03-2025", 599}} {
+			total, err := repo.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+				UserID: alice.String(), ServiceName: "Yandex Plus", StartPeriod: tc.start, EndPeriod: tc.end,
+			})
+			if err != nil || total != tc.want {
+				t.Errorf("CalculateTotalCost for %s - %s: expected %d, got %d (%v)", tc.start, tc.end, tc.want, total, err)
+			}
+		}
+		groups, err := repo.CostBreakdown(ctx, &model.CalculateCostRequest{
+			UserID: alice.String(), StartPeriod: "03-2025", EndPeriod: "03-2025", GroupBy: model.GroupByCategory,
+		})
+		if err != nil || len(groups) != 1 || groups[0].TotalCost != 500 {
+			t.Errorf("CostBreakdown after trial: expected 500, got %d groups (%v)", len(groups), err)
+		}
+
+		if err := repo.Update(ctx, id(trial), &model.UpdateSubscriptionRequest{StartDate: strPtr("02-2025"), TrialMonths: intPtr(1)}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, _ = repo.GetByID(ctx, id(trial))
+		if got == nil || got.TrialEndsAt == nil || !got.TrialEndsAt.Equal(month("03-2025")) || !got.EndDate.Equal(month("04-2025")) || got.TrialPrice != 99 {
+			t.Errorf("Update trial: expected trial until 03-2025 and end 04-2025, got %+v", got)
+		}
+
+		if err := repo.Update(ctx, id(trial), &model.UpdateSubscriptionRequest{TrialMonths: intPtr(0), TrialPrice: intPtr(0)}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, _ = repo.GetByID(ctx, id(trial))
+		if got == nil || got.TrialEndsAt != nil || got.TrialMonths != 0 || !got.EndDate.Equal(month("03-2025")) {
+			t.Errorf("Update without trial: expected no trial and end 03-2025, got %+v", got)
+		}
+	})
+
+	t.Run("Discounts", func(t *testing.T) {
+		repo := newRepo(t)
+		sub, err := repo.Create(ctx, &model.Subscription{
+			ServiceName: "Yandex Plus", Price: 300, UserID: alice, StartDate: month("01-2025"), EndDate: month("07-2025"),
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		promo, err := repo.AddDiscount(ctx, id(sub), &model.Discount{Kind: model.DiscountPrice, Value: 99, StartsAt: month("01-2025"), Cycles: 3})
+		if err != nil {
+			t.Fatalf("AddDiscount: %v", err)
+		}
+		mayEnd := month("06-2025")
+		if _, err := repo.AddDiscount(ctx, id(sub), &model.Discount{
+			Kind: model.DiscountPercent, Value: 10, StartsAt: month("05-2025"), EndsAt: &mayEnd,
+		}); err != nil {
+			t.Fatalf("AddDiscount: %v", err)
+		}
+		if got, err := repo.GetByID(ctx, id(sub)); err != nil || len(got.Discounts) != 2 || got.Discounts[0].ID != promo.ID {
+			t.Errorf("GetByID: expected 2 discounts, got %+v (%v)", got, err)
+		}
+
+		for _, tc := range []struct {
+			start, end string
+			want       int
+		}{{"01-2025", "06-2025", 99*3 + 300 + 270 + 300}, {"04-2025", "04-2025", 300}, {"07-2025", "07-2025", 0}} {
+			total, err := repo.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+				UserID: alice.String(), ServiceName: "Yandex Plus", StartPeriod: tc.start, EndPeriod: tc.end,
+			})
+			if err != nil || total != tc.want {
+				t.Errorf("CalculateTotalCost for %s - %s: expected %d, got %d (%v)", tc.start, tc.end, tc.want, total, err)
+			}
+		}
+
+		groups, err := repo.CostBreakdown(ctx, &model.CalculateCostRequest{
+			UserID: alice.String(), StartPeriod: "12-2024", EndPeriod: "06-2025", GroupBy: model.GroupByMonth,
+		})
+		if err != nil || len(groups) != 6 {
+			t.Fatalf("CostBreakdown by month: expected 6 months, got %d (%v)", len(groups), err)
+		}
+		if *groups[0] != (model.CostGroup{Key: "01-2025", TotalCost: 99, Discount: 201}) ||
+			*groups[4] != (model.CostGroup{Key: "05-2025", TotalCost: 270, Discount: 30}) {
+			t.Errorf("CostBreakdown by month: unexpected groups %+v, %+v", groups[0], groups[4])
+		}
+
+		if err := repo.RemoveDiscount(ctx, id(sub), promo.ID); err != nil {
+			t.Fatalf("RemoveDiscount: %v", err)
+		}
+		if err := repo.RemoveDiscount(ctx, id(sub), promo.ID); !errors.Is(err, repository.ErrDiscountNotFound) {
+			t.Errorf("RemoveDiscount twice: expected ErrDiscountNotFound, got %v", err)
+		}
+		total, err := repo.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+			UserID: alice.String(), ServiceName: "Yandex Plus", StartPeriod: "01-2025", EndPeriod: "06-2025",
+		})
+		if err != nil || total != 300*5+270 {
+			t.Errorf("CalculateTotalCost after RemoveDiscount: expected %d, got %d (%v)", 300*5+270, total, err)
+		}
+
+		if _, err := repo.AddDiscount(asUser(bob), id(sub), &model.Discount{Kind: model.DiscountAmount, Value: 50, StartsAt: month("01-2025")}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("AddDiscount by another user: expected ErrNotFound, got %v", err)
+		}
+		history, err := repo.History(ctx, id(sub))
+		if err != nil || len(history) != 4 || history[3].Operation != "update" {
+			t.Errorf("History: expected create and 3 updates, got %d entries (%v)", len(history), err)
+		}
+	})
+
+	t.Run("SharedSubscriptions", func(t *testing.T) {
+		repos := withUsers(t, newRepos)
+		repo := repos.Subscriptions
+		dave := uuid.MustParse("e4b7a1c2-3d5f-4a6b-8c9d-0e1f2a3b4c5d")
+		for _, userID := range []uuid.UUID{carol, dave} {
+			if _, err := repos.Users.Create(ctx, &model.User{ID: userID, Timezone: "UTC", Currency: "RUB"}); err != nil {
+				t.Fatalf("Create user: %v", err)
+			}
+		}
+
+		if _, err := repo.Create(ctx, &model.Subscription{
+			ServiceName: "Kinopoisk", Price: 100, UserID: alice, StartDate: month("01-2025"), EndDate: month("02-2025"),
+			Members: []model.SubscriptionMember{{UserID: uuid.New(), Weight: 1}},
+		}); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Create with unknown member: expected ErrUserNotFound, got %v", err)
+		}
+
+		// 400 в месяц: dave платит 100, остаток 300 делится весами alice 1, bob 1, carol 2
+		sub, err := repo.Create(ctx, &model.Subscription{
+			ServiceName: "Yandex Plus", Price: 400, UserID: alice, StartDate: month("01-2025"), EndDate: month("03-2025"),
+			Members: []model.SubscriptionMember{{UserID: bob, Weight: 1}, {UserID: carol, Weight: 2}, {UserID: dave, Amount: intPtr(100)}},
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if got, err := repo.GetByID(asUser(bob), id(sub)); err != nil || len(got.Members) != 3 {
+			t.Errorf("GetByID by member: expected subscription with 3 members, got %+v (%v)", got, err)
+		}
+		if list, err := repo.List(asUser(carol), model.SubscriptionFilter{}, 10, 0); err != nil || len(list) != 1 || list[0].ID != sub.ID {
+			t.Errorf("List by member: expected shared subscription, got %v (%v)", list, err)
+		}
+		if err := repo.Update(asUser(bob), id(sub), &model.UpdateSubscriptionRequest{Price: intPtr(1)}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Update by member: expected ErrNotFound, got %v", err)
+		}
+
+		for _, tc := range []struct {
+			userID      uuid.UUID
+			perspective string
+			want        int
+		}{
+			{alice, model.PerspectivePayer, 800}, {alice, model.PerspectiveMember, 150}, {bob, model.PerspectivePayer, 0},
+			{bob, model.PerspectiveMember, 150}, {carol, model.PerspectiveMember, 300}, {dave, model.PerspectiveMember, 200},
+		} {
+			total, err := repo.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+				UserID: tc.userID.String(), ServiceName: "Yandex Plus", StartPeriod: "01-2025", EndPeriod: "02-2025", Perspective: tc.perspective,
+			})
+			if err != nil || total != tc.want {
+				t.Errorf("CalculateTotalCost for %s as %s: expected %d, got %d (%v)", tc.userID, tc.perspective, tc.want, total, err)
+			}
+		}
+
+		if err := repos.Users.Delete(ctx, carol.String(), false); err != nil {
+			t.Fatalf("Delete member: %v", err)
+		}
+		if got, err := repo.GetByID(ctx, id(sub)); err != nil || len(got.Members) != 2 {
+			t.Errorf("GetByID after member deletion: expected 2 members, got %+v (%v)", got, err)
+		}
+		total, err := repo.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+			UserID: alice.String(), ServiceName: "Yandex Plus", StartPeriod: "01-2025", EndPeriod: "01-2025", Perspective: model.PerspectiveMember,
+		})
+		if err != nil || total != 150 {
+			t.Errorf("CalculateTotalCost after member deletion: expected 150, got %d (%v)", total, err)
+		}
+
+		if err := repo.Update(ctx, id(sub), &model.UpdateSubscriptionRequest{Members: []model.SubscriptionMember{}}); err != nil {
+			t.Fatalf("Update members: %v", err)
+		}
+		if _, err := repo.GetByID(asUser(bob), id(sub)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetByID by former member: expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("StatusTransitions", func(t *testing.T) {
+		repo := newRepo(t)
+		sub, err := repo.Create(ctx, &model.Subscription{
+			ServiceName: "Yandex Plus", Price: 100, UserID: alice, StartDate: month("01-2025"), EndDate: month("07-2025"),
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if sub.Status != model.StatusActive {
+			t.Errorf("Create: expected status %q, got %q", model.StatusActive, sub.Status)
+		}
+
+		if _, err := repo.ChangeStatus(asUser(bob), id(sub), &model.StatusTransition{
+			From: model.StatusActive, To: model.StatusPaused, EffectiveAt: month("03-2025"),
+		}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("ChangeStatus by another user: expected ErrNotFound, got %v", err)
+		}
+
+		// Март начался до приостановки и оплачивается, апрель — нет; июнь начался с отменой
+		for _, transition := range []*model.StatusTransition{
+			{From: model.StatusActive, To: model.StatusPaused, EffectiveAt: month("03-2025").AddDate(0, 0, 9)},
+			{From: model.StatusPaused, To: model.StatusActive, EffectiveAt: month("04-2025").AddDate(0, 0, 19)},
+			{From: model.StatusActive, To: model.StatusCancelled, EffectiveAt: month("06-2025")},
+		} {
+			created, err := repo.ChangeStatus(ctx, id(sub), transition)
+			if err != nil {
+				t.Fatalf("ChangeStatus to %s: %v", transition.To, err)
+			}
+			if created.ID == 0 || created.CreatedAt.IsZero() || !created.EffectiveAt.Equal(transition.EffectiveAt) {
+				t.Errorf("ChangeStatus to %s: unexpected transition %+v", transition.To, created)
+			}
+		}
+
+		got, err := repo.GetByID(ctx, id(sub))
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != model.StatusCancelled || got.CancelAt == nil || !got.CancelAt.Equal(month("06-2025")) || len(got.Transitions) != 3 {
+			t.Errorf("GetByID: expected cancelled subscription with 3 transitions, got %+v", got)
+		}
+		if got.StatusAt(month("05-2025")) != model.StatusActive || got.StatusAt(month("06-2025")) != model.StatusCancelled {
+			t.Errorf("StatusAt: cancellation must take effect at cancel_at")
+		}
+
+		total, err := repo.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+			UserID: alice.String(), ServiceName: "Yandex Plus", StartPeriod: "01-2025", EndPeriod: "06-2025",
+		})
+		if err != nil || total != 400 {
+			t.Errorf("CalculateTotalCost: expected 400 without paused and cancelled months, got %d (%v)", total, err)
+		}
+
+		history, err := repo.History(ctx, id(sub))
+		if err != nil || len(history) != 4 {
+			t.Errorf("History: expected create and 3 status changes, got %d entries (%v)", len(history), err)
+		}
+	})
+
+	t.Run("Renewal", func(t *testing.T) {
+		repo := newRepo(t)
+		var subs []*model.Subscription
+		for _, autoRenew := range []bool{true, false, true} {
+			sub, err := repo.Create(ctx, &model.Subscription{
+				ServiceName: "Yandex Plus", Price: 100, UserID: alice, StartDate: month("01-2025"), EndDate: month("02-2025"), AutoRenew: autoRenew,
+			})
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			subs = append(subs, sub)
+		}
+		if _, err := repo.ChangeStatus(ctx, id(subs[2]), &model.StatusTransition{
+			From: model.StatusActive, To: model.StatusCancelled, EffectiveAt: month("02-2025"),
+		}); err != nil {
+			t.Fatalf("ChangeStatus: %v", err)
+		}
+
+		renewing, err := repo.RenewingBetween(ctx, month("01-2025"), month("02-2025"))
+		if err != nil || len(renewing) != 1 || renewing[0].ID != subs[0].ID {
+			t.Errorf("RenewingBetween: expected only the active auto-renewing subscription, got %d (%v)", len(renewing), err)
+		}
+		if renewing, err := repo.RenewingBetween(ctx, month("02-2025"), month("03-2025")); err != nil || len(renewing) != 0 {
+			t.Errorf("RenewingBetween after end_date: expected nothing, got %d (%v)", len(renewing), err)
+		}
+
+		now := month("03-2025").AddDate(0, 0, 14)
+		if due, err := repo.DueForRenewal(ctx, month("01-2025"), 10); err != nil || len(due) != 0 {
+			t.Errorf("DueForRenewal before end_date: expected nothing, got %d (%v)", len(due), err)
+		}
+		for attempt, want := range []bool{true, false} {
+			err := repo.WithTx(ctx, func(tx repository.SubscriptionRepository) error {
+				due, err := tx.DueForRenewal(ctx, now, 10)
+				if err != nil {
+					return err
+				}
+				if len(due) != 1 && want {
+					t.Errorf("DueForRenewal: expected only the auto-renewing subscription, got %d", len(due))
+				}
+				renewed, err := tx.Renew(ctx, id(subs[0]), now)
+				if renewed != want {
+					t.Errorf("Renew attempt %d: expected renewed=%t, got %t", attempt+1, want, renewed)
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatalf("Renew: %v", err)
+			}
+		}
+
+		got, err := repo.GetByID(ctx, id(subs[0]))
+		if err != nil || !got.EndDate.Equal(month("04-2025")) {
+			t.Errorf("GetByID after renewal: expected end_date %v, got %+v (%v)", month("04-2025"), got, err)
+		}
+		if renewed, err := repo.Renew(ctx, id(subs[1]), now); err != nil || renewed {
+			t.Errorf("Renew without auto_renew: expected no renewal, got %t (%v)", renewed, err)
+		}
+
+		total, err := repo.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+			UserID: alice.String(), ServiceName: "Yandex Plus", StartPeriod: "01-2025", EndPeriod: "04-2025",
+		})
+		if err != nil || total != 100*3+100+100 {
+			t.Errorf("CalculateTotalCost: expected %d, got %d (%v)", 100*3+100+100, total, err)
+		}
+
+		history, err := repo.History(ctx, id(subs[0]))
+		if err != nil || len(history) != 2 || history[1].Operation != "renew" {
+			t.Errorf("History: expected create and renew, got %d entries (%v)", len(history), err)
+		}
+	})
+
+	t.Run("History", func(t *testing.T) {
+		repo := newRepo(t)
+		created := create(t, repo, "Yandex Plus", 400, alice, "07-2025")
+
+		if err := repo.Update(asUser(alice), id(created), &model.UpdateSubscriptionRequest{Price: intPtr(500)}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := repo.Delete(ctx, id(created), false); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repo.Restore(ctx, id(created)); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+
+		history, err := repo.History(ctx, id(created))
+		if err != nil {
+			t.Fatalf("History: %v", err)
+		}
+
+		want := []string{"create", "update", "delete", "restore"}
+		if len(history) != len(want) {
+			t.Fatalf("History: expected %d entries, got %d", len(want), len(history))
+		}
+		for i, entry := range history {
+			if entry.Operation != want[i] || entry.SubscriptionID != created.ID {
+				t.Errorf("History[%d]: unexpected entry %+v", i, entry)
+			}
+		}
+		if history[0].Before != nil || history[0].After == nil {
+			t.Errorf("History create: expected only after snapshot")
+		}
+		if history[1].Actor != "user:"+alice.String() {
+			t.Errorf("History update: unexpected actor %q", history[1].Actor)
+		}
+
+		if _, err := repo.History(asUser(bob), id(created)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("History by another user: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.History(ctx, "42"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("History missing: expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("WithTx", func(t *testing.T) {
+		repo := newRepo(t)
+		kept := create(t, repo, "Yandex Plus", 400, alice, "07-2025")
+
+		err := repo.WithTx(ctx, func(tx repository.SubscriptionRepository) error {
+			locked, err := tx.GetForUpdate(ctx, id(kept))
+			if err != nil {
+				return err
+			}
+			if locked.Price != 400 {
+				t.Errorf("GetForUpdate: unexpected price %d", locked.Price)
+			}
+			return tx.Update(ctx, id(kept), &model.UpdateSubscriptionRequest{Price: intPtr(450)})
+		})
+		if err != nil {
+			t.Fatalf("WithTx commit: %v", err)
+		}
+		if got, _ := repo.GetByID(ctx, id(kept)); got == nil || got.Price != 450 {
+			t.Errorf("WithTx commit: expected committed price 450, got %+v", got)
+		}
+
+		errAbort := errors.New("abort")
+		var rolledBack *model.Subscription
+		err = repo.WithTx(ctx, func(tx repository.SubscriptionRepository) error {
+			rolledBack = create(t, tx, "Netflix", 900, alice, "07-2025")
+			if err := tx.Delete(ctx, id(kept), false); err != nil {
+				return err
+			}
+			return tx.WithTx(ctx, func(nested repository.SubscriptionRepository) error {
+				if _, err := nested.GetByID(ctx, id(rolledBack)); err != nil {
+					t.Errorf("nested WithTx: expected to see uncommitted subscription, got %v", err)
+				}
+				return errAbort
+			})
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("WithTx rollback: expected fn error, got %v", err)
+		}
+
+		if _, err := repo.GetByID(ctx, id(rolledBack)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("WithTx rollback: expected created subscription to be rolled back, got %v", err)
+		}
+		if _, err := repo.GetByID(ctx, id(kept)); err != nil {
+			t.Errorf("WithTx rollback: expected deletion to be rolled back, got %v", err)
+		}
+		history, err := repo.History(ctx, id(kept))
+		if err != nil || len(history) != 2 {
+			t.Errorf("WithTx rollback: expected 2 history entries, got %d (%v)", len(history), err)
+		}
+
+		if _, err := repo.GetForUpdate(asUser(bob), id(kept)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetForUpdate by another user: expected ErrNotFound, got %v", err)
+		}
+	})
+}
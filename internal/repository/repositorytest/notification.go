@@ -0,0 +1,136 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+// RunNotificationRepositoryContract проверяет реализацию NotificationRepository
+func RunNotificationRepositoryContract(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("Preferences", func(t *testing.T) {
+		repos := withUsers(t, newRepos)
+
+		if _, err := repos.Notifications.GetPreferences(ctx, alice.String()); !errors.Is(err, repository.ErrPreferencesNotFound) {
+			t.Errorf("GetPreferences before save: expected ErrPreferencesNotFound, got %v", err)
+		}
+		if _, err := repos.Notifications.SavePreferences(ctx, &model.NotificationPreferences{UserID: carol}); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("SavePreferences for missing user: expected ErrUserNotFound, got %v", err)
+		}
+
+		prefs := &model.NotificationPreferences{UserID: alice, RenewalReminders: true, WebhookURL: "https://example.com/hook", LeadDays: 5}
+		if _, err := repos.Notifications.SavePreferences(ctx, prefs); err != nil {
+			t.Fatalf("SavePreferences: %v", err)
+		}
+		prefs.Email, prefs.LeadDays = true, 0
+		saved, err := repos.Notifications.SavePreferences(ctx, prefs)
+		if err != nil || !saved.Email || saved.LeadDays != 0 || saved.UpdatedAt.IsZero() {
+			t.Fatalf("SavePreferences again: expected preferences to be replaced, got %+v (%v)", saved, err)
+		}
+
+		got, err := repos.Notifications.GetPreferences(ctx, alice.String())
+		if err != nil || got.UserID != alice || !got.RenewalReminders || !got.Email || got.WebhookURL != prefs.WebhookURL || got.LeadDays != 0 {
+			t.Errorf("GetPreferences: expected saved preferences, got %+v (%v)", got, err)
+		}
+
+		if err := repos.Users.Delete(ctx, alice.String(), false); err != nil {
+			t.Fatalf("Delete user: %v", err)
+		}
+		if _, err := repos.Notifications.GetPreferences(ctx, alice.String()); !errors.Is(err, repository.ErrPreferencesNotFound) {
+			t.Errorf("GetPreferences after user delete: expected ErrPreferencesNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Deliveries", func(t *testing.T) {
+		repos := withUsers(t, newRepos)
+		sub := create(t, repos.Subscriptions, "Netflix", 800, alice, "01-2025")
+		delivery := &model.NotificationDelivery{SubscriptionID: sub.ID, UserID: alice, Channel: model.ChannelEmail, RenewsAt: sub.EndDate}
+		now, lease := time.Now().UTC(), time.Minute
+
+		claimed, err := repos.Notifications.ClaimDelivery(ctx, delivery, 2, now, lease)
+		if err != nil || claimed == nil || claimed.Status != model.DeliveryPending || claimed.Attempts != 1 {
+			t.Fatalf("ClaimDelivery: expected pending delivery, got %+v (%v)", claimed, err)
+		}
+		if again, err := repos.Notifications.ClaimDelivery(ctx, delivery, 2, now, lease); err != nil || again != nil {
+			t.Errorf("ClaimDelivery while pending: expected nothing, got %+v (%v)", again, err)
+		}
+
+		if err := repos.Notifications.CompleteDelivery(ctx, claimed.ID, errors.New("connection refused")); err != nil {
+			t.Fatalf("CompleteDelivery failed: %v", err)
+		}
+		retried, err := repos.Notifications.ClaimDelivery(ctx, delivery, 2, now, lease)
+		if err != nil || retried == nil || retried.ID != claimed.ID || retried.Attempts != 2 || retried.Error != "" {
+			t.Fatalf("ClaimDelivery after failure: expected second attempt, got %+v (%v)", retried, err)
+		}
+		if err := repos.Notifications.CompleteDelivery(ctx, retried.ID, errors.New("connection refused")); err != nil {
+			t.Fatalf("CompleteDelivery failed: %v", err)
+		}
+		if again, err := repos.Notifications.ClaimDelivery(ctx, delivery, 2, now, lease); err != nil || again != nil {
+			t.Errorf("ClaimDelivery after max attempts: expected nothing, got %+v (%v)", again, err)
+		}
+
+		webhook := *delivery
+		webhook.Channel = model.ChannelWebhook
+		sent, err := repos.Notifications.ClaimDelivery(ctx, &webhook, 2, now, lease)
+		if err != nil || sent == nil {
+			t.Fatalf("ClaimDelivery for another channel: expected new delivery, got %+v (%v)", sent, err)
+		}
+		if err := repos.Notifications.CompleteDelivery(ctx, sent.ID, nil); err != nil {
+			t.Fatalf("CompleteDelivery: %v", err)
+		}
+		if again, err := repos.Notifications.ClaimDelivery(ctx, &webhook, 2, now, lease); err != nil || again != nil {
+			t.Errorf("ClaimDelivery after success: expected nothing, got %+v (%v)", again, err)
+		}
+
+		deliveries, err := repos.Notifications.ListDeliveries(ctx, alice.String(), 10, 0)
+		if err != nil || len(deliveries) != 2 {
+			t.Fatalf("ListDeliveries: expected 2 deliveries, got %d (%v)", len(deliveries), err)
+		}
+		if deliveries[0].ID != sent.ID || deliveries[0].Status != model.DeliverySent || deliveries[0].SentAt == nil {
+			t.Errorf("ListDeliveries: expected sent webhook first, got %+v", deliveries[0])
+		}
+		if deliveries[1].Status != model.DeliveryFailed || deliveries[1].Error != "connection refused" || deliveries[1].Attempts != 2 {
+			t.Errorf("ListDeliveries: expected failed email after 2 attempts, got %+v", deliveries[1])
+		}
+		if others, err := repos.Notifications.ListDeliveries(ctx, bob.String(), 10, 0); err != nil || len(others) != 0 {
+			t.Errorf("ListDeliveries of another user: expected nothing, got %d (%v)", len(others), err)
+		}
+
+		if err := repos.Subscriptions.Delete(ctx, id(sub), true); err != nil {
+			t.Fatalf("Delete subscription: %v", err)
+		}
+		if deliveries, err := repos.Notifications.ListDeliveries(ctx, alice.String(), 10, 0); err != nil || len(deliveries) != 0 {
+			t.Errorf("ListDeliveries after subscription delete: expected nothing, got %d (%v)", len(deliveries), err)
+		}
+	})
+
+	t.Run("AbandonedDelivery", func(t *testing.T) {
+		repos := withUsers(t, newRepos)
+		sub := create(t, repos.Subscriptions, "Netflix", 800, alice, "01-2025")
+		delivery := &model.NotificationDelivery{SubscriptionID: sub.ID, UserID: alice, Channel: model.ChannelEmail, RenewsAt: sub.EndDate}
+		now, lease := time.Now().UTC(), time.Minute
+
+		claimed, err := repos.Notifications.ClaimDelivery(ctx, delivery, 2, now, lease)
+		if err != nil || claimed == nil {
+			t.Fatalf("ClaimDelivery: expected pending delivery, got %+v (%v)", claimed, err)
+		}
+		if again, err := repos.Notifications.ClaimDelivery(ctx, delivery, 2, now.Add(lease/2), lease); err != nil || again != nil {
+			t.Errorf("ClaimDelivery within lease: expected nothing, got %+v (%v)", again, err)
+		}
+
+		reclaimed, err := repos.Notifications.ClaimDelivery(ctx, delivery, 2, now.Add(lease), lease)
+		if err != nil || reclaimed == nil || reclaimed.ID != claimed.ID || reclaimed.Attempts != 2 ||
+			reclaimed.ClaimedAt.Sub(now) < lease-time.Second {
+			t.Fatalf("ClaimDelivery after lease: expected abandoned delivery to be claimed again, got %+v (%v)", reclaimed, err)
+		}
+		if again, err := repos.Notifications.ClaimDelivery(ctx, delivery, 2, now.Add(3*lease), lease); err != nil || again != nil {
+			t.Errorf("ClaimDelivery after max attempts: expected nothing, got %+v (%v)", again, err)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+var carol = uuid.MustParse("9d3c1f0e-4b2a-4e8f-a7c6-5d4e3f2a1b0c")
+
+// RunUserRepositoryContract проверяет реализацию UserRepository и ее связь с подписками
+func RunUserRepositoryContract(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repos := newRepos(t)
+		created, err := repos.Users.Create(ctx, &model.User{
+			ID: carol, Name: "Carol", Email: "carol@example.com", Timezone: "Europe/Moscow", Currency: "EUR",
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+			t.Errorf("Create: expected timestamps to be set, got %+v", created)
+		}
+
+		got, err := repos.Users.GetByID(ctx, carol.String())
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Carol" || got.Email != "carol@example.com" || got.Timezone != "Europe/Moscow" || got.Currency != "EUR" {
+			t.Errorf("GetByID: unexpected user %+v", got)
+		}
+
+		if _, err := repos.Users.Create(ctx, &model.User{ID: carol, Timezone: "UTC", Currency: "RUB"}); !errors.Is(err, repository.ErrUserExists) {
+			t.Errorf("Create duplicate: expected ErrUserExists, got %v", err)
+		}
+		if _, err := repos.Users.GetByID(ctx, alice.String()); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("GetByID missing: expected ErrUserNotFound, got %v", err)
+		}
+		if _, err := repos.Users.GetByID(ctx, "not-a-uuid"); err == nil {
+			t.Errorf("GetByID invalid id: expected error")
+		}
+	})
+
+	t.Run("UpdateAndList", func(t *testing.T) {
+		repos := withUsers(t, newRepos)
+
+		updated, err := repos.Users.Update(ctx, &model.User{ID: alice, Name: "Alice", Timezone: "Asia/Tokyo", Currency: "USD"})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if updated.Name != "Alice" || updated.Timezone != "Asia/Tokyo" || updated.Currency != "USD" {
+			t.Errorf("Update: unexpected user %+v", updated)
+		}
+		if _, err := repos.Users.Update(ctx, &model.User{ID: carol, Timezone: "UTC", Currency: "RUB"}); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Update missing: expected ErrUserNotFound, got %v", err)
+		}
+
+		all, err := repos.Users.List(ctx, 10, 0)
+		if err != nil || len(all) != 2 {
+			t.Fatalf("List: expected 2 users, got %d (%v)", len(all), err)
+		}
+		page, err := repos.Users.List(ctx, 1, 1)
+		if err != nil || len(page) != 1 || page[0].ID != all[1].ID {
+			t.Errorf("List page: expected user %s, got %d users (%v)", all[1].ID, len(page), err)
+		}
+	})
+
+	t.Run("SubscriptionOwner", func(t *testing.T) {
+		repos := withUsers(t, newRepos)
+		subs := repos.Subscriptions
+
+		_, err := subs.Create(ctx, &model.Subscription{
+			ServiceName: "Netflix", Price: 800, UserID: carol, StartDate: month("01-2025"), EndDate: month("02-2025"),
+		})
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Create for unknown user: expected ErrUserNotFound, got %v", err)
+		}
+
+		sub := create(t, subs, "Netflix", 800, alice, "01-2025")
+		err = subs.Update(ctx, id(sub), &model.UpdateSubscriptionRequest{UserID: strPtr(carol.String())})
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Update to unknown user: expected ErrUserNotFound, got %v", err)
+		}
+		if err := subs.Update(ctx, id(sub), &model.UpdateSubscriptionRequest{UserID: strPtr(bob.String())}); err != nil {
+			t.Errorf("Update to existing user: %v", err)
+		}
+	})
+
+	t.Run("Location", func(t *testing.T) {
+		repos := withUsers(t, newRepos)
+		subs := repos.Subscriptions
+		moscow, err := time.LoadLocation("Europe/Moscow")
+		if err != nil {
+			t.Skipf("time zone database is not available: %v", err)
+		}
+
+		sub := create(t, subs, "Netflix", 800, alice, "01-2025")
+		err = subs.Update(ctx, id(sub), &model.UpdateSubscriptionRequest{StartDate: strPtr("03-2025"), Location: moscow})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := subs.GetByID(ctx, id(sub))
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		wantStart := time.Date(2025, 2, 28, 21, 0, 0, 0, time.UTC)
+		wantEnd := time.Date(2025, 3, 31, 21, 0, 0, 0, time.UTC)
+		if !got.StartDate.Equal(wantStart) || !got.EndDate.Equal(wantEnd) {
+			t.Errorf("Update with location: expected %v - %v, got %v - %v", wantStart, wantEnd, got.StartDate, got.EndDate)
+		}
+
+		for _, tc := range []struct {
+			period string
+			want   int
+		}{{"02-2025", 0}, {"03-2025", 800}} {
+			total, err := subs.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+				UserID: alice.String(), ServiceName: "Netflix", StartPeriod: tc.period, EndPeriod: tc.period, Location: moscow,
+			})
+			if err != nil || total != tc.want {
+				t.Errorf("CalculateTotalCost for %s: expected %d, got %d (%v)", tc.period, tc.want, total, err)
+			}
+		}
+	})
+
+	t.Run("OwnerLocation", func(t *testing.T) {
+		repos := newRepos(t)
+		if _, err := time.LoadLocation("Europe/Moscow"); err != nil {
+			t.Skipf("time zone database is not available: %v", err)
+		}
+		if _, err := repos.Users.Create(ctx, &model.User{ID: carol, Timezone: "Europe/Moscow", Currency: "RUB"}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		err := repos.Subscriptions.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+			loc, err := repo.OwnerLocation(ctx, carol)
+			if err != nil {
+				return err
+			}
+			if loc.String() != "Europe/Moscow" {
+				t.Errorf("OwnerLocation in transaction: expected Europe/Moscow, got %s", loc)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("WithTx: %v", err)
+		}
+
+		if _, err := repos.Subscriptions.OwnerLocation(ctx, uuid.New()); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("OwnerLocation of unknown user: expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("DeleteRestrict", func(t *testing.T) {
+		repos := withUsers(t, newRepos)
+		sub := create(t, repos.Subscriptions, "Netflix", 800, alice, "01-2025")
+		if err := repos.Subscriptions.Delete(ctx, id(sub), false); err != nil {
+			t.Fatalf("Delete subscription: %v", err)
+		}
+
+		if err := repos.Users.Delete(ctx, alice.String(), false); !errors.Is(err, repository.ErrUserInUse) {
+			t.Errorf("Delete user with deleted subscription: expected ErrUserInUse, got %v", err)
+		}
+		if _, err := repos.Users.GetByID(ctx, alice.String()); err != nil {
+			t.Errorf("Delete restricted: expected user to stay, got %v", err)
+		}
+
+		if err := repos.Users.Delete(ctx, bob.String(), false); err != nil {
+			t.Errorf("Delete user without subscriptions: %v", err)
+		}
+		if err := repos.Users.Delete(ctx, bob.String(), false); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Delete missing: expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("DeleteCascade", func(t *testing.T) {
+		repos := withUsers(t, newRepos)
+		active := create(t, repos.Subscriptions, "Netflix", 800, alice, "01-2025")
+		deleted := create(t, repos.Subscriptions, "Yandex Plus", 400, alice, "01-2025")
+		other := create(t, repos.Subscriptions, "Netflix", 800, bob, "01-2025")
+		if err := repos.Subscriptions.Delete(ctx, id(deleted), false); err != nil {
+			t.Fatalf("Delete subscription: %v", err)
+		}
+
+		if err := repos.Users.Delete(ctx, alice.String(), true); err != nil {
+			t.Fatalf("Delete cascade: %v", err)
+		}
+		if _, err := repos.Users.GetByID(ctx, alice.String()); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Delete cascade: expected user to be deleted, got %v", err)
+		}
+		for _, sub := range []*model.Subscription{active, deleted} {
+			if err := repos.Subscriptions.Restore(ctx, id(sub)); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("Delete cascade: expected subscription %d to be purged, got %v", sub.ID, err)
+			}
+		}
+		if _, err := repos.Subscriptions.GetByID(ctx, id(other)); err != nil {
+			t.Errorf("Delete cascade: expected subscription of another user to stay, got %v", err)
+		}
+
+		history, err := repos.Subscriptions.History(ctx, id(active))
+		if err != nil || len(history) != 2 || history[1].Operation != "delete" {
+			t.Errorf("Delete cascade: expected create and delete in history, got %d entries (%v)", len(history), err)
+		}
+	})
+}
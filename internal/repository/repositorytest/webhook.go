@@ -0,0 +1,179 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+func createWebhook(t *testing.T, repo repository.WebhookRepository, url string, events ...string) *model.Webhook {
+	t.Helper()
+
+	hook, err := repo.Create(context.Background(), &model.Webhook{URL: url, Secret: "whsec_contract_secret", Events: events})
+	if err != nil {
+		t.Fatalf("Create webhook: %v", err)
+	}
+	if hook.ID == 0 || hook.CreatedAt.IsZero() {
+		t.Fatalf("Create webhook: expected assigned ID and creation time, got %+v", hook)
+	}
+	return hook
+}
+
+func webhookID(hook *model.Webhook) string {
+	return strconv.Itoa(hook.ID)
+}
+
+// RunWebhookRepositoryContract проверяет реализацию WebhookRepository
+func RunWebhookRepositoryContract(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndDelete", func(t *testing.T) {
+		repo := newRepos(t).Webhooks
+		first := createWebhook(t, repo, "https://example.com/first", model.EventSubscriptionCreated, model.EventSubscriptionDeleted)
+		second := createWebhook(t, repo, "https://example.com/second", model.EventSubscriptionUpdated)
+
+		got, err := repo.GetByID(ctx, webhookID(first))
+		if err != nil || got.URL != first.URL || got.Secret != "whsec_contract_secret" || len(got.Events) != 2 ||
+			got.Events[0] != model.EventSubscriptionCreated || got.Events[1] != model.EventSubscriptionDeleted {
+			t.Errorf("GetByID: expected webhook with secret and events, got %+v (%v)", got, err)
+		}
+
+		hooks, err := repo.List(ctx)
+		if err != nil || len(hooks) != 2 || hooks[0].ID != first.ID || hooks[1].ID != second.ID {
+			t.Errorf("List: expected both webhooks in creation order, got %+v (%v)", hooks, err)
+		}
+
+		if err := repo.Delete(ctx, webhookID(first)); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, webhookID(first)); !errors.Is(err, repository.ErrWebhookNotFound) {
+			t.Errorf("GetByID after delete: expected ErrWebhookNotFound, got %v", err)
+		}
+		if err := repo.Delete(ctx, webhookID(first)); !errors.Is(err, repository.ErrWebhookNotFound) {
+			t.Errorf("Delete twice: expected ErrWebhookNotFound, got %v", err)
+		}
+		if _, err := repo.GetByID(ctx, "abc"); err == nil || errors.Is(err, repository.ErrWebhookNotFound) {
+			t.Errorf("GetByID with invalid id: expected format error, got %v", err)
+		}
+	})
+
+	t.Run("Deliveries", func(t *testing.T) {
+		repos := newRepos(t)
+		repo := repos.Webhooks
+		created := createWebhook(t, repo, "https://example.com/created", model.EventSubscriptionCreated)
+		all := createWebhook(t, repo, "https://example.com/all", model.WebhookEvents...)
+
+		event := &model.WebhookEvent{ID: "evt-1", Type: model.EventSubscriptionUpdated, CreatedAt: time.Now().UTC()}
+		enqueued, err := repos.Subscriptions.EnqueueEvent(ctx, event, []byte(`{"id":"evt-1"}`))
+		if err != nil || enqueued != 1 {
+			t.Fatalf("EnqueueEvent: expected delivery only to subscribed webhook, got %d (%v)", enqueued, err)
+		}
+		if deliveries, err := repo.ListDeliveries(ctx, webhookID(created), 10, 0); err != nil || len(deliveries) != 0 {
+			t.Errorf("ListDeliveries of unsubscribed webhook: expected nothing, got %+v (%v)", deliveries, err)
+		}
+
+		now := time.Now().UTC().Add(time.Second)
+		claimed, err := repo.ClaimNext(ctx, now, now.Add(time.Minute))
+		if err != nil || claimed == nil || claimed.WebhookID != all.ID || claimed.EventID != "evt-1" ||
+			claimed.Event != model.EventSubscriptionUpdated || string(claimed.Payload) != `{"id":"evt-1"}` ||
+			claimed.Status != model.DeliveryPending || claimed.Attempts != 0 {
+			t.Fatalf("ClaimNext: expected queued delivery, got %+v (%v)", claimed, err)
+		}
+		if again, err := repo.ClaimNext(ctx, now, now.Add(time.Minute)); err != nil || again != nil {
+			t.Errorf("ClaimNext while leased: expected nothing, got %+v (%v)", again, err)
+		}
+		if expired, err := repo.ClaimNext(ctx, now.Add(2*time.Minute), now.Add(3*time.Minute)); err != nil || expired == nil || expired.ID != claimed.ID {
+			t.Fatalf("ClaimNext after lease: expected the same delivery, got %+v (%v)", expired, err)
+		}
+
+		retryAt := now.Add(time.Hour)
+		claimed.Attempts, claimed.ResponseStatus, claimed.Error, claimed.NextAttemptAt = 1, 503, "unexpected status 503", &retryAt
+		if err := repo.SaveAttempt(ctx, claimed); err != nil {
+			t.Fatalf("SaveAttempt failed: %v", err)
+		}
+		if due, err := repo.ClaimNext(ctx, now.Add(5*time.Minute), now.Add(6*time.Minute)); err != nil || due != nil {
+			t.Errorf("ClaimNext before retry: expected nothing, got %+v (%v)", due, err)
+		}
+
+		retried, err := repo.ClaimNext(ctx, retryAt, retryAt.Add(time.Minute))
+		if err != nil || retried == nil || retried.Attempts != 1 || retried.ResponseStatus != 503 || retried.Error != "unexpected status 503" {
+			t.Fatalf("ClaimNext at retry: expected failed attempt to be kept, got %+v (%v)", retried, err)
+		}
+		deliveredAt := retryAt
+		retried.Status, retried.Attempts, retried.ResponseStatus, retried.Error = model.DeliverySent, 2, 200, ""
+		retried.NextAttemptAt, retried.DeliveredAt = nil, &deliveredAt
+		if err := repo.SaveAttempt(ctx, retried); err != nil {
+			t.Fatalf("SaveAttempt sent: %v", err)
+		}
+		if done, err := repo.ClaimNext(ctx, retryAt.Add(24*time.Hour), retryAt.Add(25*time.Hour)); err != nil || done != nil {
+			t.Errorf("ClaimNext after delivery: expected nothing, got %+v (%v)", done, err)
+		}
+
+		second := &model.WebhookEvent{ID: "evt-2", Type: model.EventSubscriptionCreated, CreatedAt: time.Now().UTC()}
+		if enqueued, err := repos.Subscriptions.EnqueueEvent(ctx, second, []byte(`{"id":"evt-2"}`)); err != nil || enqueued != 2 {
+			t.Fatalf("EnqueueEvent second: expected deliveries to both webhooks, got %d (%v)", enqueued, err)
+		}
+
+		deliveries, err := repo.ListDeliveries(ctx, webhookID(all), 10, 0)
+		if err != nil || len(deliveries) != 2 || deliveries[0].EventID != "evt-2" || deliveries[1].EventID != "evt-1" {
+			t.Fatalf("ListDeliveries: expected newest first, got %+v (%v)", deliveries, err)
+		}
+		if sent := deliveries[1]; sent.Status != model.DeliverySent || sent.Attempts != 2 || sent.ResponseStatus != 200 ||
+			sent.Error != "" || sent.NextAttemptAt != nil || sent.DeliveredAt == nil {
+			t.Errorf("ListDeliveries: expected saved attempt, got %+v", sent)
+		}
+		if page, err := repo.ListDeliveries(ctx, webhookID(all), 1, 1); err != nil || len(page) != 1 || page[0].EventID != "evt-1" {
+			t.Errorf("ListDeliveries page: expected oldest delivery, got %+v (%v)", page, err)
+		}
+
+		if err := repo.Delete(ctx, webhookID(all)); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.ListDeliveries(ctx, webhookID(all), 10, 0); !errors.Is(err, repository.ErrWebhookNotFound) {
+			t.Errorf("ListDeliveries after delete: expected ErrWebhookNotFound, got %v", err)
+		}
+		later := time.Now().UTC().Add(time.Second)
+		remaining, err := repo.ClaimNext(ctx, later, later.Add(time.Minute))
+		if err != nil || remaining == nil || remaining.WebhookID != created.ID {
+			t.Fatalf("ClaimNext after delete: expected only remaining webhook's delivery, got %+v (%v)", remaining, err)
+		}
+		if none, err := repo.ClaimNext(ctx, later, later.Add(time.Minute)); err != nil || none != nil {
+			t.Errorf("ClaimNext after delete: expected deleted webhook's deliveries to be gone, got %+v (%v)", none, err)
+		}
+	})
+	t.Run("EnqueueInTransaction", func(t *testing.T) {
+		repos := newRepos(t)
+		hook := createWebhook(t, repos.Webhooks, "https://example.com/hook", model.WebhookEvents...)
+		event := &model.WebhookEvent{ID: "evt-tx", Type: model.EventSubscriptionCreated, CreatedAt: time.Now().UTC()}
+
+		errRollback := errors.New("rollback")
+		err := repos.Subscriptions.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+			if _, err := repo.EnqueueEvent(ctx, event, []byte(`{"id":"evt-tx"}`)); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("WithTx: expected rollback error, got %v", err)
+		}
+		if deliveries, err := repos.Webhooks.ListDeliveries(ctx, webhookID(hook), 10, 0); err != nil || len(deliveries) != 0 {
+			t.Errorf("ListDeliveries after rollback: expected nothing, got %+v (%v)", deliveries, err)
+		}
+
+		err = repos.Subscriptions.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+			_, err := repo.EnqueueEvent(ctx, event, []byte(`{"id":"evt-tx"}`))
+			return err
+		})
+		if err != nil {
+			t.Fatalf("WithTx: %v", err)
+		}
+		if deliveries, err := repos.Webhooks.ListDeliveries(ctx, webhookID(hook), 10, 0); err != nil || len(deliveries) != 1 || deliveries[0].EventID != "evt-tx" {
+			t.Errorf("ListDeliveries after commit: expected the event, got %+v (%v)", deliveries, err)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository/repositorytest"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/pkg/database"
+)
+
+func TestSQLiteRepositories(t *testing.T) {
+	runContracts(t, func(t *testing.T) repositorytest.Repositories {
+		path := filepath.Join(t.TempDir(), "subscriptions.db")
+		if err := database.RunSQLiteMigrations(path); err != nil {
+			t.Fatalf("RunSQLiteMigrations: %v", err)
+		}
+		db, err := database.NewSQLite(path)
+		if err != nil {
+			t.Fatalf("NewSQLite: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		return dbRepositories(db.DB, repository.NewSQLiteSubscriptionRepository(db.DB))
+	})
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+const transitionColumns = `id, subscription_id, from_status, to_status, effective_at, created_at`
+
+// loadTransitions заполняет переходы между состояниями подписок в порядке их записи
+func loadTransitions(ctx context.Context, q querier, subs ...*model.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*model.Subscription, len(subs))
+	placeholders := make([]string, 0, len(subs))
+	args := make([]interface{}, 0, len(subs))
+	for _, sub := range subs {
+		sub.Transitions = nil
+		byID[sub.ID] = sub
+		args = append(args, sub.ID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	query := `SELECT ` + transitionColumns + ` FROM subscription_transitions
+    WHERE subscription_id IN (` + strings.Join(placeholders, ", ") + `) ORDER BY id`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to load subscription transitions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subscriptionID int
+		transition, err := scanTransition(rows, &subscriptionID)
+		if err != nil {
+			return fmt.Errorf("failed to scan subscription transition: %w", err)
+		}
+		if sub, ok := byID[subscriptionID]; ok {
+			sub.Transitions = append(sub.Transitions, transition)
+		}
+	}
+	return rows.Err()
+}
+
+func scanTransition(row interface{ Scan(...interface{}) error }, subscriptionID *int) (*model.StatusTransition, error) {
+	var transition model.StatusTransition
+	if err := row.Scan(&transition.ID, subscriptionID, &transition.From, &transition.To,
+		&transition.EffectiveAt, &transition.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &transition, nil
+}
+
+// ChangeStatus переводит подписку в состояние transition.To и записывает переход
+// и изменение в журнал. Допустимость перехода проверяет сервис.
+func (r *subscriptionRepo) ChangeStatus(ctx context.Context, id string, transition *model.StatusTransition) (*model.StatusTransition, error) {
+	query := `INSERT INTO subscription_transitions (subscription_id, from_status, to_status, effective_at, created_at)
+    VALUES ($1, $2, $3, $4, $5) RETURNING ` + transitionColumns
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	var created *model.StatusTransition
+	err = r.inTx(ctx, func(tx *subscriptionRepo) error {
+		before, err := tx.lockSubscription(ctx, idInt, false)
+		if err != nil {
+			return err
+		}
+
+		var cancelAt *time.Time
+		if transition.To == model.StatusCancelled {
+			cancelAt = &transition.EffectiveAt
+		}
+		after, err := scanSubscription(tx.conn().QueryRowContext(ctx, `UPDATE subscriptions SET status = $1, cancel_at = $2
+        WHERE id = $3 RETURNING `+subscriptionColumns, transition.To, cancelAt, idInt))
+		if err != nil {
+			log.Printf("Error changing subscription status: %v", err)
+			return fmt.Errorf("failed to change subscription status: %w", err)
+		}
+
+		var subscriptionID int
+		created, err = scanTransition(tx.conn().QueryRowContext(ctx, query, idInt, transition.From, transition.To,
+			transition.EffectiveAt, time.Now().UTC()), &subscriptionID)
+		if err != nil {
+			log.Printf("Error recording subscription transition: %v", err)
+			return fmt.Errorf("failed to change subscription status: %w", err)
+		}
+
+		after.Tags = before.Tags
+		after.Discounts = before.Discounts
+		after.Members = before.Members
+		after.Transitions = append(append([]*model.StatusTransition(nil), before.Transitions...), created)
+		return writeAudit(ctx, tx.conn(), auditUpdate, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Subscription %s status changed: %s -> %s", id, created.From, created.To)
+	return created, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+// loadTags заполняет теги подписок одним запросом
+func loadTags(ctx context.Context, q querier, subs ...*model.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*model.Subscription, len(subs))
+	placeholders := make([]string, 0, len(subs))
+	args := make([]interface{}, 0, len(subs))
+	for _, sub := range subs {
+		sub.Tags = nil
+		byID[sub.ID] = sub
+		args = append(args, sub.ID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	query := `SELECT subscription_id, tag FROM subscription_tags
+    WHERE subscription_id IN (` + strings.Join(placeholders, ", ") + `) ORDER BY tag`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to load subscription tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return fmt.Errorf("failed to scan subscription tag: %w", err)
+		}
+		if sub, ok := byID[id]; ok {
+			sub.Tags = append(sub.Tags, tag)
+		}
+	}
+	return rows.Err()
+}
+
+// replaceTags заменяет теги подписки
+func replaceTags(ctx context.Context, q querier, id int, tags []string) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM subscription_tags WHERE subscription_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update subscription tags: %w", err)
+	}
+	for _, tag := range tags {
+		if _, err := q.ExecContext(ctx, `INSERT INTO subscription_tags (subscription_id, tag) VALUES ($1, $2)`, id, tag); err != nil {
+			return fmt.Errorf("failed to update subscription tags: %w", err)
+		}
+	}
+	return nil
+}
+
+// refreshCategory подставляет подписке без своей категории категорию ее сервиса из каталога
+func refreshCategory(ctx context.Context, q querier, sub *model.Subscription) error {
+	if sub.CustomCategory {
+		return nil
+	}
+
+	var category string
+	if sub.ServiceID != nil {
+		err := q.QueryRowContext(ctx, `SELECT category FROM services WHERE id = $1`, *sub.ServiceID).Scan(&category)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to get service category: %w", err)
+		}
+	}
+	if category == sub.Category {
+		return nil
+	}
+
+	if _, err := q.ExecContext(ctx, `UPDATE subscriptions SET category = $1 WHERE id = $2`, category, sub.ID); err != nil {
+		return fmt.Errorf("failed to update subscription category: %w", err)
+	}
+	sub.Category = category
+	return nil
+}
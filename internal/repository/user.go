@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user already exists")
+	ErrUserInUse    = errors.New("user has subscriptions")
+)
+
+// UserRepository хранит профили пользователей. Подписка может принадлежать
+// только существующему пользователю.
+type UserRepository interface {
+	Create(ctx context.Context, user *model.User) (*model.User, error)
+	GetByID(ctx context.Context, id string) (*model.User, error)
+	List(ctx context.Context, limit, offset int) ([]*model.User, error)
+	// Update заменяет профиль пользователя user.ID
+	Update(ctx context.Context, user *model.User) (*model.User, error)
+	// Delete удаляет пользователя. Без cascade пользователя с подписками, включая
+	// помеченные удаленными, удалить нельзя (ErrUserInUse); с cascade его подписки
+	// удаляются окончательно с записью в журнал.
+	Delete(ctx context.Context, id string, cascade bool) error
+}
+
+type userRepo struct {
+	db *sql.DB
+}
+
+// NewUserRepository создает хранилище пользователей поверх PostgreSQL или SQLite
+func NewUserRepository(db *sql.DB) UserRepository {
+	return &userRepo{db: db}
+}
+
+func (r *userRepo) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+const userColumns = `id, name, email, timezone, currency, created_at, updated_at`
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*model.User, error) {
+	var user model.User
+	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Timezone, &user.Currency,
+		&user.CreatedAt, &user.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func parseUserID(id string) (uuid.UUID, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid id format: must be valid UUID")
+	}
+	return userID, nil
+}
+
+// checkUserExists возвращает ErrUserNotFound, если пользователя userID нет
+func checkUserExists(ctx context.Context, q querier, userID interface{}) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userRepo) Create(ctx context.Context, user *model.User) (*model.User, error) {
+	query := `INSERT INTO users (id, name, email, timezone, currency, created_at, updated_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $6) RETURNING ` + userColumns
+
+	var created *model.User
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		err := checkUserExists(ctx, tx, user.ID)
+		if err == nil {
+			return ErrUserExists
+		}
+		if !errors.Is(err, ErrUserNotFound) {
+			return err
+		}
+
+		created, err = scanUser(tx.QueryRowContext(ctx, query, user.ID, user.Name, user.Email,
+			user.Timezone, user.Currency, time.Now().UTC()))
+		if err != nil {
+			log.Printf("Error creating user: %v", err)
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("User created: %s", created.ID)
+	return created, nil
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id string) (*model.User, error) {
+	userID, err := parseUserID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func (r *userRepo) List(ctx context.Context, limit, offset int) ([]*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY created_at, id LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		log.Printf("Error listing users: %v", err)
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *userRepo) Update(ctx context.Context, user *model.User) (*model.User, error) {
+	query := `UPDATE users SET name = $1, email = $2, timezone = $3, currency = $4, updated_at = $5
+    WHERE id = $6 RETURNING ` + userColumns
+
+	updated, err := scanUser(r.db.QueryRowContext(ctx, query, user.Name, user.Email, user.Timezone, user.Currency,
+		time.Now().UTC(), user.ID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		log.Printf("Error updating user: %v", err)
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	log.Printf("User updated: %s", user.ID)
+	return updated, nil
+}
+
+func (r *userRepo) Delete(ctx context.Context, id string, cascade bool) error {
+	userID, err := parseUserID(id)
+	if err != nil {
+		return err
+	}
+
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := checkUserExists(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		subs, err := userSubscriptions(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		if len(subs) > 0 && !cascade {
+			return ErrUserInUse
+		}
+
+		for _, sub := range subs {
+			if err := writeAudit(ctx, tx, auditDelete, sub, nil); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM subscriptions WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("failed to delete user subscriptions: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+			log.Printf("Error deleting user: %v", err)
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		log.Printf("Deleted %d subscriptions of user %s", len(subs), userID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("User deleted: %s", id)
+	return nil
+}
+
+// userSubscriptions читает все подписки пользователя, включая помеченные удаленными
+func userSubscriptions(ctx context.Context, q querier, userID uuid.UUID) ([]*model.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE user_id = $1 ORDER BY id`
+
+	rows, err := q.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*model.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user subscriptions: %w", err)
+	}
+	rows.Close()
+
+	if err := loadDetails(ctx, q, subs...); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// claimCandidates — сколько готовых доставок ClaimNext пробует занять, прежде
+// чем сообщить, что доставлять нечего
+const claimCandidates = 10
+
+// WebhookRepository хранит webhook внешних систем и журнал доставки им событий
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *model.Webhook) (*model.Webhook, error)
+	// GetByID возвращает webhook вместе с секретом подписи
+	GetByID(ctx context.Context, id string) (*model.Webhook, error)
+	List(ctx context.Context) ([]*model.Webhook, error)
+	// Delete удаляет webhook вместе с журналом его доставок
+	Delete(ctx context.Context, id string) error
+	// ClaimNext занимает доставку, время попытки которой наступило к now, переносит
+	// ее next_attempt_at на leaseUntil и возвращает ее; nil, если доставлять нечего.
+	// Пока аренда не истекла, доставку не получит другая задача.
+	ClaimNext(ctx context.Context, now, leaseUntil time.Time) (*model.WebhookDelivery, error)
+	// SaveAttempt записывает результат попытки: статус, число попыток, время
+	// следующей попытки, код ответа и ошибку
+	SaveAttempt(ctx context.Context, delivery *model.WebhookDelivery) error
+	// ListDeliveries возвращает доставки webhook, начиная с последних
+	// (ErrWebhookNotFound, если webhook нет)
+	ListDeliveries(ctx context.Context, id string, limit, offset int) ([]*model.WebhookDelivery, error)
+}
+
+type webhookRepo struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepo{db: db}
+}
+
+const webhookColumns = `id, url, secret, events, created_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event, payload, status, attempts, next_attempt_at,
+    response_status, error, created_at, delivered_at`
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*model.Webhook, error) {
+	var hook model.Webhook
+	var events string
+	if err := row.Scan(&hook.ID, &hook.URL, &hook.Secret, &events, &hook.CreatedAt); err != nil {
+		return nil, err
+	}
+	hook.Events = strings.Fields(events)
+	return &hook, nil
+}
+
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	var payload string
+	var nextAttemptAt, deliveredAt sql.NullTime
+	if err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.Event, &payload, &delivery.Status,
+		&delivery.Attempts, &nextAttemptAt, &delivery.ResponseStatus, &delivery.Error, &delivery.CreatedAt, &deliveredAt); err != nil {
+		return nil, err
+	}
+	delivery.Payload = []byte(payload)
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return &delivery, nil
+}
+
+func parseWebhookID(id string) (int, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, ErrInvalidID
+	}
+	return idInt, nil
+}
+
+func (r *webhookRepo) Create(ctx context.Context, hook *model.Webhook) (*model.Webhook, error) {
+	query := `INSERT INTO webhooks (url, secret, events, created_at)
+    VALUES ($1, $2, $3, $4) RETURNING ` + webhookColumns
+
+	created, err := scanWebhook(r.db.QueryRowContext(ctx, query,
+		hook.URL, hook.Secret, strings.Join(hook.Events, " "), time.Now().UTC()))
+	if err != nil {
+		log.Printf("Error creating webhook: %v", err)
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	log.Printf("Webhook created: %d (%s)", created.ID, created.URL)
+	return created, nil
+}
+
+func (r *webhookRepo) GetByID(ctx context.Context, id string) (*model.Webhook, error) {
+	idInt, err := parseWebhookID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	hook, err := scanWebhook(r.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, idInt))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting webhook: %v", err)
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return hook, nil
+}
+
+func (r *webhookRepo) List(ctx context.Context) ([]*model.Webhook, error) {
+	return listWebhooks(ctx, r.db)
+}
+
+func listWebhooks(ctx context.Context, q querier) ([]*model.Webhook, error) {
+	rows, err := q.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		log.Printf("Error listing webhooks: %v", err)
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var hooks []*model.Webhook
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, rows.Err()
+}
+
+func (r *webhookRepo) Delete(ctx context.Context, id string) error {
+	idInt, err := parseWebhookID(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, idInt)
+	if err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	log.Printf("Webhook deleted: %s", id)
+	return nil
+}
+
+// EnqueueEvent создает доставки в транзакции хранилища подписок: внутри WithTx
+// они фиксируются или откатываются вместе с изменением подписки
+func (r *subscriptionRepo) EnqueueEvent(ctx context.Context, event *model.WebhookEvent, payload []byte) (int, error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload, status, attempts, next_attempt_at, created_at)
+    VALUES ($1, $2, $3, $4, $5, 0, $6, $6)`
+
+	enqueued := 0
+	err := r.inTx(ctx, func(tx *subscriptionRepo) error {
+		hooks, err := listWebhooks(ctx, tx.conn())
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, hook := range hooks {
+			if !slices.Contains(hook.Events, event.Type) {
+				continue
+			}
+			if _, err := tx.conn().ExecContext(ctx, query, hook.ID, event.ID, event.Type, string(payload), model.DeliveryPending, now); err != nil {
+				log.Printf("Error enqueuing webhook delivery: %v", err)
+				return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+			}
+			enqueued++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return enqueued, nil
+}
+
+// ClaimNext выбирает несколько готовых доставок и занимает первую, которую не успела
+// занять другая задача: условное обновление проходит только у одной из них
+func (r *webhookRepo) ClaimNext(ctx context.Context, now, leaseUntil time.Time) (*model.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM webhook_deliveries
+    WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at, id LIMIT $3`,
+		model.DeliveryPending, now, claimCandidates)
+	if err != nil {
+		log.Printf("Error getting due webhook deliveries: %v", err)
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+	rows.Close()
+
+	query := `UPDATE webhook_deliveries SET next_attempt_at = $1
+    WHERE id = $2 AND status = $3 AND next_attempt_at <= $4 RETURNING ` + webhookDeliveryColumns
+	for _, id := range ids {
+		delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, leaseUntil, id, model.DeliveryPending, now))
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			log.Printf("Error claiming webhook delivery: %v", err)
+			return nil, fmt.Errorf("failed to claim webhook delivery: %w", err)
+		}
+		return delivery, nil
+	}
+	return nil, nil
+}
+
+func (r *webhookRepo) SaveAttempt(ctx context.Context, delivery *model.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE webhook_deliveries
+    SET status = $1, attempts = $2, next_attempt_at = $3, response_status = $4, error = $5, delivered_at = $6
+    WHERE id = $7`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.ResponseStatus, delivery.Error,
+		delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		log.Printf("Error saving webhook delivery attempt: %v", err)
+		return fmt.Errorf("failed to save webhook delivery attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, id string, limit, offset int) ([]*model.WebhookDelivery, error) {
+	hook, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+    WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3`, hook.ID, limit, offset)
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v", err)
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
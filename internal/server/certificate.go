@@ -0,0 +1,48 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// CertificateReloader хранит текущий TLS-сертификат и подменяет его при перечитывании
+// файлов. Установленные соединения продолжают работать со старым сертификатом.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func NewCertificateReloader(certFile, keyFile string) (*CertificateReloader, error) {
+	r := &CertificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload перечитывает сертификат и ключ. При ошибке остается прежний сертификат.
+func (r *CertificateReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+
+	log.Printf("TLS certificate loaded from %s", r.certFile)
+	return nil
+}
+
+// GetCertificate подходит для tls.Config.GetCertificate
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
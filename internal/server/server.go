@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/config"
+)
+
+// Server — HTTP-сервер API с таймаутами и необязательным TLS из конфигурации
+type Server struct {
+	http    *http.Server
+	cfg     config.ServerConfig
+	reload  *CertificateReloader
+	stopped chan struct{}
+}
+
+func New(cfg config.ServerConfig, handler http.Handler) (*Server, error) {
+	srv := &http.Server{
+		Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	s := &Server{http: srv, cfg: cfg, stopped: make(chan struct{})}
+
+	if cfg.TLSEnabled() {
+		reloader, err := NewCertificateReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.reload = reloader
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+	} else if cfg.H2C {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetUnencryptedHTTP2(true)
+		srv.Protocols = protocols
+	}
+
+	return s, nil
+}
+
+// Run обслуживает запросы до отмены ctx, затем дожидается завершения активных
+// запросов не дольше ShutdownTimeout
+func (s *Server) Run(ctx context.Context) error {
+	go s.shutdownOnDone(ctx)
+
+	var err error
+	if s.reload != nil {
+		log.Printf("Server starting on %s (HTTPS)", s.http.Addr)
+		err = s.http.ListenAndServeTLS("", "")
+	} else {
+		log.Printf("Server starting on %s (HTTP, h2c=%t)", s.http.Addr, s.cfg.H2C)
+		err = s.http.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	<-s.stopped
+	return nil
+}
+
+// WatchReload перечитывает TLS-сертификат по каждому сигналу из hangups до отмены
+// ctx. Без TLS перечитывать нечего, и сигнал только записывается в журнал.
+func (s *Server) WatchReload(ctx context.Context, hangups <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-hangups:
+			if s.reload == nil {
+				log.Printf("Received %v, nothing to reload without TLS", sig)
+				continue
+			}
+			log.Printf("Received %v, reloading TLS certificate", sig)
+			if err := s.reload.Reload(); err != nil {
+				log.Printf("Error reloading TLS certificate, keeping the previous one: %v", err)
+			}
+		}
+	}
+}
+
+func (s *Server) shutdownOnDone(ctx context.Context) {
+	defer close(s.stopped)
+	<-ctx.Done()
+
+	log.Println("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown failed: %v", err)
+	}
+}
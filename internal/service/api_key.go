@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+const (
+	apiKeyPrefix    = "sk_"
+	apiKeyPrefixLen = len(apiKeyPrefix) + 8
+	// apiKeyTouchInterval — как часто записывать last_used_at одного ключа,
+	// чтобы каждый запрос с ключом не превращался в запись в базу
+	apiKeyTouchInterval = time.Minute
+)
+
+// ErrInvalidAPIKeyRequest оборачивает ошибки проверки запросов на создание и
+// изменение ключей, чтобы отличать их от ошибок хранилища
+var ErrInvalidAPIKeyRequest = errors.New("invalid API key request")
+
+type APIKeyService interface {
+	auth.APIKeyVerifier
+	CreateAPIKey(ctx context.Context, req *model.CreateAPIKeyRequest) (*model.APIKeyWithSecret, error)
+	ListAPIKeys(ctx context.Context) ([]*model.APIKey, error)
+	RotateAPIKey(ctx context.Context, id string) (*model.APIKeyWithSecret, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+}
+
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+
+	mu sync.Mutex
+	// touched хранит время последней записи last_used_at по ID ключа
+	touched map[int]time.Time
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo, touched: make(map[int]time.Time)}
+}
+
+// generateAPIKey возвращает новый ключ, его отображаемый префикс и хеш для хранения
+func generateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	key = apiKeyPrefix + hex.EncodeToString(secret)
+	return key, key[:apiKeyPrefixLen], hashAPIKey(key), nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, req *model.CreateAPIKeyRequest) (*model.APIKeyWithSecret, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAPIKeyRequest)
+	}
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+	for _, scope := range req.Scopes {
+		if !auth.IsValidScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q, allowed: %s", ErrInvalidAPIKeyRequest, scope, strings.Join(auth.Scopes, ", "))
+		}
+	}
+
+	key, prefix, hash, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, &model.APIKey{Name: req.Name, Prefix: prefix, Scopes: req.Scopes}, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.APIKeyWithSecret{APIKey: *created, Key: key}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *apiKeyService) RotateAPIKey(ctx context.Context, id string) (*model.APIKeyWithSecret, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: id is required", ErrInvalidAPIKeyRequest)
+	}
+
+	key, prefix, hash, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.repo.Rotate(ctx, id, prefix, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.APIKeyWithSecret{APIKey: *rotated, Key: key}, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: id is required", ErrInvalidAPIKeyRequest)
+	}
+	return s.repo.Revoke(ctx, id)
+}
+
+// shouldTouch сообщает, пора ли записать last_used_at ключа id: не чаще
+// раза в apiKeyTouchInterval, поэтому значение в списке ключей приблизительное
+func (s *apiKeyService) shouldTouch(id int, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.touched[id]; ok && now.Sub(last) < apiKeyTouchInterval {
+		return false
+	}
+	// Устаревшие отметки удаляются, чтобы карта не росла с отозванными ключами
+	for keyID, last := range s.touched {
+		if now.Sub(last) >= apiKeyTouchInterval {
+			delete(s.touched, keyID)
+		}
+	}
+	s.touched[id] = now
+	return true
+}
+
+func (s *apiKeyService) VerifyAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, fmt.Errorf("%w: malformed API key", auth.ErrUnauthorized)
+	}
+
+	apiKey, err := s.repo.GetActiveByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrUnauthorized, err)
+	}
+
+	if s.shouldTouch(apiKey.ID, time.Now()) {
+		if err := s.repo.TouchLastUsed(ctx, apiKey.ID); err != nil {
+			log.Printf("Error recording API key usage: %v", err)
+		}
+	}
+
+	return &auth.Principal{
+		Subject:  fmt.Sprintf("api_key:%d", apiKey.ID),
+		APIKeyID: apiKey.ID,
+		Scopes:   apiKey.Scopes,
+	}, nil
+}
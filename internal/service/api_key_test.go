@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+// touchCountingRepo считает записи last_used_at поверх хранилища в памяти
+type touchCountingRepo struct {
+	repository.APIKeyRepository
+	touches int
+}
+
+func (r *touchCountingRepo) TouchLastUsed(ctx context.Context, id int) error {
+	r.touches++
+	return r.APIKeyRepository.TouchLastUsed(ctx, id)
+}
+
+func newTestAPIKeyService() (*apiKeyService, *touchCountingRepo) {
+	repo := &touchCountingRepo{APIKeyRepository: repository.NewMemoryAPIKeyRepository()}
+	return NewAPIKeyService(repo).(*apiKeyService), repo
+}
+
+func createTestAPIKey(t *testing.T, s *apiKeyService, scopes ...string) *model.APIKeyWithSecret {
+	t.Helper()
+	key, err := s.CreateAPIKey(context.Background(), &model.CreateAPIKeyRequest{Name: "billing", Scopes: scopes})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	return key
+}
+
+func TestCreateAPIKeyValidation(t *testing.T) {
+	s, _ := newTestAPIKeyService()
+
+	tests := []struct {
+		name string
+		req  model.CreateAPIKeyRequest
+	}{
+		{name: "missing name", req: model.CreateAPIKeyRequest{Name: " ", Scopes: []string{auth.ScopeSubscriptionsRead}}},
+		{name: "no scopes", req: model.CreateAPIKeyRequest{Name: "billing"}},
+		{name: "unknown scope", req: model.CreateAPIKeyRequest{Name: "billing", Scopes: []string{"subscriptions:admin"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.CreateAPIKey(context.Background(), &tt.req); !errors.Is(err, ErrInvalidAPIKeyRequest) {
+				t.Fatalf("expected ErrInvalidAPIKeyRequest, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestAPIKeyService()
+	created := createTestAPIKey(t, s, auth.ScopeSubscriptionsRead)
+
+	if created.Prefix != created.Key[:apiKeyPrefixLen] {
+		t.Errorf("prefix %q does not match key", created.Prefix)
+	}
+
+	// Хранится только хеш: по нему находится ключ, а сам секрет ничего не находит
+	if _, err := repo.GetActiveByHash(ctx, hashAPIKey(created.Key)); err != nil {
+		t.Fatalf("key not found by hash: %v", err)
+	}
+	if _, err := repo.GetActiveByHash(ctx, created.Key); !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		t.Fatalf("expected the raw secret not to be stored, got %v", err)
+	}
+
+	principal, err := s.VerifyAPIKey(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("VerifyAPIKey: %v", err)
+	}
+	if principal.APIKeyID != created.ID || principal.Admin {
+		t.Errorf("unexpected principal %+v", principal)
+	}
+	if !principal.HasScope(auth.ScopeSubscriptionsRead) || principal.HasScope(auth.ScopeSubscriptionsWrite) {
+		t.Errorf("unexpected scopes %v", principal.Scopes)
+	}
+
+	for _, key := range []string{"", "not-a-key", created.Key + "0", apiKeyPrefix + "unknown"} {
+		if _, err := s.VerifyAPIKey(ctx, key); !errors.Is(err, auth.ErrUnauthorized) {
+			t.Errorf("key %q: expected ErrUnauthorized, got %v", key, err)
+		}
+	}
+}
+
+func TestVerifyRotatedAndRevokedAPIKey(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestAPIKeyService()
+	created := createTestAPIKey(t, s, auth.ScopeSubscriptionsRead)
+	id := strconv.Itoa(created.ID)
+
+	rotated, err := s.RotateAPIKey(ctx, id)
+	if err != nil {
+		t.Fatalf("RotateAPIKey: %v", err)
+	}
+	if rotated.Key == created.Key {
+		t.Fatal("rotation must issue a new secret")
+	}
+	if _, err := s.VerifyAPIKey(ctx, created.Key); !errors.Is(err, auth.ErrUnauthorized) {
+		t.Errorf("old secret after rotation: expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := s.VerifyAPIKey(ctx, rotated.Key); err != nil {
+		t.Errorf("new secret after rotation: %v", err)
+	}
+
+	if err := s.RevokeAPIKey(ctx, id); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	if _, err := s.VerifyAPIKey(ctx, rotated.Key); !errors.Is(err, auth.ErrUnauthorized) {
+		t.Errorf("revoked key: expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := s.RotateAPIKey(ctx, id); !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		t.Errorf("rotate revoked key: expected ErrAPIKeyNotFound, got %v", err)
+	}
+	if err := s.RevokeAPIKey(ctx, id); !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		t.Errorf("revoke twice: expected ErrAPIKeyNotFound, got %v", err)
+	}
+	if err := s.RevokeAPIKey(ctx, "abc"); !errors.Is(err, repository.ErrInvalidID) {
+		t.Errorf("malformed id: expected ErrInvalidID, got %v", err)
+	}
+}
+
+func TestVerifyAPIKeyThrottlesLastUsed(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestAPIKeyService()
+	created := createTestAPIKey(t, s, auth.ScopeSubscriptionsRead)
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.VerifyAPIKey(ctx, created.Key); err != nil {
+			t.Fatalf("VerifyAPIKey: %v", err)
+		}
+	}
+	if repo.touches != 1 {
+		t.Fatalf("expected a single last_used_at write, got %d", repo.touches)
+	}
+
+	keys, err := s.ListAPIKeys(ctx)
+	if err != nil {
+		t.Fatalf("ListAPIKeys: %v", err)
+	}
+	if len(keys) != 1 || keys[0].LastUsedAt == nil {
+		t.Fatalf("expected last_used_at to be recorded, got %+v", keys)
+	}
+}
+
+func TestShouldTouch(t *testing.T) {
+	s, _ := newTestAPIKeyService()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		id   int
+		at   time.Duration
+		want bool
+	}{
+		{id: 1, at: 0, want: true},
+		{id: 1, at: apiKeyTouchInterval / 2, want: false},
+		{id: 2, at: apiKeyTouchInterval / 2, want: true},
+		{id: 1, at: apiKeyTouchInterval, want: true},
+		{id: 2, at: apiKeyTouchInterval, want: false},
+		{id: 1, at: apiKeyTouchInterval + time.Second, want: false},
+	}
+
+	for i, step := range steps {
+		if got := s.shouldTouch(step.id, start.Add(step.at)); got != step.want {
+			t.Errorf("step %d (key %d at %s): shouldTouch = %v, want %v", i, step.id, step.at, got, step.want)
+		}
+	}
+
+	// Отметки старше интервала удаляются при следующей записи
+	s.shouldTouch(3, start.Add(3*apiKeyTouchInterval))
+	if len(s.touched) != 1 {
+		t.Errorf("expected stale entries to be swept, got %v", s.touched)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+type CatalogService interface {
+	CreateService(ctx context.Context, req *model.ServiceRequest) (*model.Service, error)
+	GetService(ctx context.Context, id string) (*model.Service, error)
+	ResolveService(ctx context.Context, name string) (*model.Service, error)
+	ListServices(ctx context.Context) ([]*model.Service, error)
+	UpdateService(ctx context.Context, id string, req *model.ServiceRequest) (*model.Service, error)
+	DeleteService(ctx context.Context, id string) error
+}
+
+type catalogService struct {
+	repo repository.ServiceCatalogRepository
+}
+
+func NewCatalogService(repo repository.ServiceCatalogRepository) CatalogService {
+	return &catalogService{repo: repo}
+}
+
+// buildService проверяет запрос и приводит его к сервису каталога. Псевдонимы,
+// совпадающие с названием или друг с другом после нормализации, отбрасываются.
+func buildService(req *model.ServiceRequest) (*model.Service, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	category, err := normalizeLabel("category", req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &model.Service{
+		Name:     name,
+		Aliases:  []string{},
+		Category: category,
+		Plans:    []model.ServicePlan{},
+	}
+
+	seen := map[string]bool{repository.NormalizeServiceName(name): true}
+	for _, alias := range req.Aliases {
+		alias = strings.TrimSpace(alias)
+		lookup := repository.NormalizeServiceName(alias)
+		if lookup == "" {
+			return nil, fmt.Errorf("aliases must not be empty")
+		}
+		if !seen[lookup] {
+			seen[lookup] = true
+			svc.Aliases = append(svc.Aliases, alias)
+		}
+	}
+
+	plans := make(map[string]bool)
+	for _, plan := range req.Plans {
+		plan.Name = strings.TrimSpace(plan.Name)
+		if plan.Name == "" {
+			return nil, fmt.Errorf("plan name is required")
+		}
+		if plans[strings.ToLower(plan.Name)] {
+			return nil, fmt.Errorf("duplicate plan %q", plan.Name)
+		}
+		if plan.Price <= 0 {
+			return nil, fmt.Errorf("price of plan %q must be positive", plan.Name)
+		}
+		plans[strings.ToLower(plan.Name)] = true
+		svc.Plans = append(svc.Plans, plan)
+	}
+
+	return svc, nil
+}
+
+func (s *catalogService) CreateService(ctx context.Context, req *model.ServiceRequest) (*model.Service, error) {
+	svc, err := buildService(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Create(ctx, svc)
+}
+
+func (s *catalogService) GetService(ctx context.Context, id string) (*model.Service, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *catalogService) ResolveService(ctx context.Context, name string) (*model.Service, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	return s.repo.Resolve(ctx, name)
+}
+
+func (s *catalogService) ListServices(ctx context.Context) ([]*model.Service, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *catalogService) UpdateService(ctx context.Context, id string, req *model.ServiceRequest) (*model.Service, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	svc, err := buildService(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Update(ctx, id, svc)
+}
+
+func (s *catalogService) DeleteService(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+)
+
+const (
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+// StorageChecker проверяет базу данных: соединение и версию схемы
+type StorageChecker interface {
+	PingContext(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (uint, bool, error)
+	ExpectedSchemaVersion() uint
+}
+
+type HealthService interface {
+	Readiness(ctx context.Context) *model.Readiness
+}
+
+type healthService struct {
+	storage StorageChecker
+}
+
+// NewHealthService создает проверку готовности. storage равен nil для хранилища
+// в памяти: такому хранилищу нечего проверять.
+func NewHealthService(storage StorageChecker) HealthService {
+	return &healthService{storage: storage}
+}
+
+func (s *healthService) Readiness(ctx context.Context) *model.Readiness {
+	if s.storage == nil {
+		return &model.Readiness{Status: StatusReady, Storage: "memory"}
+	}
+
+	expected := s.storage.ExpectedSchemaVersion()
+	readiness := &model.Readiness{Status: StatusNotReady, ExpectedSchemaVersion: &expected}
+
+	if err := s.storage.PingContext(ctx); err != nil {
+		readiness.Storage = "unavailable"
+		readiness.Error = err.Error()
+		return readiness
+	}
+	readiness.Storage = "ok"
+
+	version, dirty, err := s.storage.SchemaVersion(ctx)
+	if err != nil {
+		readiness.Error = err.Error()
+		return readiness
+	}
+	readiness.SchemaVersion = &version
+	readiness.SchemaDirty = dirty
+
+	switch {
+	case dirty:
+		readiness.Error = fmt.Sprintf("migration %d failed and requires manual repair", version)
+	case version < expected:
+		readiness.Error = fmt.Sprintf("schema version %d is behind expected %d", version, expected)
+	default:
+		readiness.Status = StatusReady
+	}
+	return readiness
+}
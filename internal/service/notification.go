@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/notify"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// maxLeadDays ограничивает срок напоминания, выбранный пользователем
+const maxLeadDays = 30
+
+type NotificationService interface {
+	GetPreferences(ctx context.Context, userID string) (*model.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID string, req *model.UpdateNotificationPreferencesRequest) (*model.NotificationPreferences, error)
+	ListDeliveries(ctx context.Context, userID string, limit, offset int) ([]*model.NotificationDelivery, error)
+}
+
+type notificationService struct {
+	repo  repository.NotificationRepository
+	users repository.UserRepository
+}
+
+func NewNotificationService(repo repository.NotificationRepository, users repository.UserRepository) NotificationService {
+	return &notificationService{repo: repo, users: users}
+}
+
+// defaultPreferences — настройки пользователя, который их не менял:
+// напоминания на почту за срок по умолчанию
+func defaultPreferences(userID uuid.UUID) *model.NotificationPreferences {
+	return &model.NotificationPreferences{UserID: userID, RenewalReminders: true, Email: true}
+}
+
+// loadPreferences возвращает настройки пользователя или настройки по умолчанию
+func loadPreferences(ctx context.Context, repo repository.NotificationRepository, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	prefs, err := repo.GetPreferences(ctx, userID.String())
+	if errors.Is(err, repository.ErrPreferencesNotFound) {
+		return defaultPreferences(userID), nil
+	}
+	return prefs, err
+}
+
+// normalizeWebhookURL проверяет адрес webhook в поле field: абсолютный URL http
+// или https, который не ведет на localhost, частный или link-local адрес.
+// Пустой адрес возвращается как есть.
+func normalizeWebhookURL(field, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if len(raw) > 2048 {
+		return "", fmt.Errorf("%s must be at most 2048 characters", field)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%s must be an absolute http or https URL", field)
+	}
+	if err := notify.CheckHost(u.Hostname()); err != nil {
+		return "", fmt.Errorf("%s must not point to a loopback, private, link-local or reserved address", field)
+	}
+	return raw, nil
+}
+
+func (s *notificationService) GetPreferences(ctx context.Context, userID string) (*model.NotificationPreferences, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if err := checkProfileAccess(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return loadPreferences(ctx, s.repo, user.ID)
+}
+
+func (s *notificationService) UpdatePreferences(ctx context.Context, userID string, req *model.UpdateNotificationPreferencesRequest) (*model.NotificationPreferences, error) {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RenewalReminders != nil {
+		prefs.RenewalReminders = *req.RenewalReminders
+	}
+	if req.Email != nil {
+		prefs.Email = *req.Email
+	}
+	if req.WebhookURL != nil {
+		if prefs.WebhookURL, err = normalizeWebhookURL("webhook_url", *req.WebhookURL); err != nil {
+			return nil, err
+		}
+	}
+	if req.LeadDays != nil {
+		if *req.LeadDays < 0 || *req.LeadDays > maxLeadDays {
+			return nil, fmt.Errorf("lead_days must be between 0 and %d", maxLeadDays)
+		}
+		prefs.LeadDays = *req.LeadDays
+	}
+
+	return s.repo.SavePreferences(ctx, prefs)
+}
+
+func (s *notificationService) ListDeliveries(ctx context.Context, userID string, limit, offset int) ([]*model.NotificationDelivery, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if err := checkProfileAccess(ctx, &userID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.ListDeliveries(ctx, userID, limit, offset)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/notify"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// deliveryLease — сколько ждать завершения отправки напоминания. Отправка, не
+// завершенная за это время, например из-за остановки процесса, повторяется.
+const deliveryLease = 15 * time.Minute
+
+// ReminderJob периодически напоминает владельцам подписок с auto_renew о скором
+// продлении. Напоминание по каждому каналу отправляется один раз на дату продления:
+// отправка сначала резервируется записью в хранилище, поэтому повторный запуск
+// и задачи других реплик его не дублируют.
+type ReminderJob struct {
+	subs          repository.SubscriptionRepository
+	users         repository.UserRepository
+	notifications repository.NotificationRepository
+	senders       map[string]notify.Sender
+	interval      time.Duration
+	// leadTime — срок напоминания для пользователей, не задавших свой lead_days
+	leadTime    time.Duration
+	maxAttempts int
+}
+
+func NewReminderJob(subs repository.SubscriptionRepository, users repository.UserRepository,
+	notifications repository.NotificationRepository, senders map[string]notify.Sender,
+	interval, leadTime time.Duration, maxAttempts int) *ReminderJob {
+	return &ReminderJob{
+		subs:          subs,
+		users:         users,
+		notifications: notifications,
+		senders:       senders,
+		interval:      interval,
+		leadTime:      leadTime,
+		maxAttempts:   maxAttempts,
+	}
+}
+
+// Run отправляет напоминания сразу и затем с заданным интервалом до отмены ctx
+func (j *ReminderJob) Run(ctx context.Context) {
+	log.Printf("Reminder job started: sending renewal reminders %s ahead every %s", j.leadTime, j.interval)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := j.SendDue(ctx, time.Now().UTC())
+		if err != nil {
+			log.Printf("Reminder job failed: %v", err)
+		}
+		if sent > 0 {
+			log.Printf("Reminder job sent %d renewal reminders", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Reminder job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reminderTarget — канал и получатель напоминания
+type reminderTarget struct {
+	channel, to string
+}
+
+// SendDue отправляет напоминания о подписках, продлевающихся в пределах срока
+// напоминания их владельцев после now, и возвращает число отправленных.
+// Неудачная отправка записывается и повторяется следующим запуском.
+func (j *ReminderJob) SendDue(ctx context.Context, now time.Time) (int, error) {
+	horizon := max(j.leadTime, maxLeadDays*24*time.Hour)
+	subs, err := j.subs.RenewingBetween(ctx, now, now.Add(horizon))
+	if err != nil {
+		return 0, err
+	}
+
+	users := make(map[uuid.UUID]*model.User)
+	prefs := make(map[uuid.UUID]*model.NotificationPreferences)
+	sent := 0
+	for _, sub := range subs {
+		userPrefs, ok := prefs[sub.UserID]
+		if !ok {
+			if userPrefs, err = loadPreferences(ctx, j.notifications, sub.UserID); err != nil {
+				return sent, err
+			}
+			prefs[sub.UserID] = userPrefs
+		}
+		if !userPrefs.RenewalReminders || sub.EndDate.After(now.Add(j.lead(userPrefs))) {
+			continue
+		}
+
+		user, ok := users[sub.UserID]
+		if !ok {
+			user, err = j.users.GetByID(ctx, sub.UserID.String())
+			if errors.Is(err, repository.ErrUserNotFound) {
+				continue
+			}
+			if err != nil {
+				return sent, err
+			}
+			users[sub.UserID] = user
+		}
+
+		reminder := &model.RenewalReminder{
+			SubscriptionID: sub.ID,
+			UserID:         sub.UserID,
+			ServiceName:    sub.ServiceName,
+			Price:          sub.Price,
+			Currency:       user.Currency,
+			RenewsAt:       sub.EndDate,
+		}
+
+		var targets []reminderTarget
+		if userPrefs.Email && user.Email != "" {
+			targets = append(targets, reminderTarget{model.ChannelEmail, user.Email})
+		}
+		if userPrefs.WebhookURL != "" {
+			targets = append(targets, reminderTarget{model.ChannelWebhook, userPrefs.WebhookURL})
+		}
+
+		for _, target := range targets {
+			ok, err := j.deliver(ctx, target, reminder)
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// lead возвращает срок напоминания пользователя
+func (j *ReminderJob) lead(prefs *model.NotificationPreferences) time.Duration {
+	if prefs.LeadDays > 0 {
+		return time.Duration(prefs.LeadDays) * 24 * time.Hour
+	}
+	return j.leadTime
+}
+
+// deliver резервирует и отправляет напоминание по одному каналу. Возвращает false,
+// если напоминание уже отправлено, отправляется другой задачей или не отправилось.
+func (j *ReminderJob) deliver(ctx context.Context, target reminderTarget, reminder *model.RenewalReminder) (bool, error) {
+	sender, ok := j.senders[target.channel]
+	if !ok {
+		return false, nil
+	}
+
+	claimed, err := j.notifications.ClaimDelivery(ctx, &model.NotificationDelivery{
+		SubscriptionID: reminder.SubscriptionID,
+		UserID:         reminder.UserID,
+		Channel:        target.channel,
+		RenewsAt:       reminder.RenewsAt,
+	}, j.maxAttempts, time.Now().UTC(), deliveryLease)
+	if err != nil || claimed == nil {
+		return false, err
+	}
+
+	sendErr := sender.Send(ctx, target.to, reminder)
+	if sendErr != nil {
+		log.Printf("Failed to send %s reminder for subscription %d (attempt %d): %v",
+			target.channel, reminder.SubscriptionID, claimed.Attempts, sendErr)
+	}
+	if err := j.notifications.CompleteDelivery(ctx, claimed.ID, sendErr); err != nil {
+		return false, err
+	}
+	return sendErr == nil, nil
+}
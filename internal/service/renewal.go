@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+// RenewalJob периодически продлевает на месяц подписки с auto_renew, чей end_date
+// наступил. Задачи нескольких реплик не мешают друг другу: каждая пачка выбирается
+// и продлевается в одной транзакции с блокировкой строк, а занятые строки пропускаются.
+// В той же транзакции для каждой продленной подписки ставится событие subscription.updated.
+type RenewalJob struct {
+	repo      repository.SubscriptionRepository
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRenewalJob(repo repository.SubscriptionRepository, interval time.Duration, batchSize int) *RenewalJob {
+	return &RenewalJob{repo: repo, interval: interval, batchSize: batchSize}
+}
+
+// Run выполняет продление сразу и затем с заданным интервалом до отмены ctx
+func (j *RenewalJob) Run(ctx context.Context) {
+	log.Printf("Renewal job started: renewing due subscriptions every %s", j.interval)
+
+	// Продление записывается в журнал от имени задачи, без ограничения по пользователю
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Admin: true, Subject: "renewal-job"})
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		renewed, err := j.RenewDue(ctx, time.Now().UTC())
+		if err != nil {
+			log.Printf("Renewal job failed: %v", err)
+		}
+		if renewed > 0 {
+			log.Printf("Renewal job renewed %d subscriptions", renewed)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Renewal job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RenewDue продлевает пачками все подписки, срок которых наступил к now, и возвращает
+// число продленных. Повторный запуск с тем же now ничего не меняет.
+func (j *RenewalJob) RenewDue(ctx context.Context, now time.Time) (int, error) {
+	total := 0
+	for {
+		var due, renewed int
+		err := j.repo.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+			subs, err := repo.DueForRenewal(ctx, now, j.batchSize)
+			if err != nil {
+				return err
+			}
+			due = len(subs)
+
+			for _, sub := range subs {
+				id := strconv.Itoa(sub.ID)
+				ok, err := repo.Renew(ctx, id, now)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+				if err := enqueueUpdated(ctx, repo, id); err != nil {
+					return err
+				}
+				renewed++
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += renewed
+		// Пачка без продлений означает, что оставшиеся подписки заняты другой репликой
+		if due < j.batchSize || renewed == 0 {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+// RetentionJob периодически удаляет подписки, помеченные удаленными дольше maxAge
+type RetentionJob struct {
+	repo     repository.SubscriptionRepository
+	maxAge   time.Duration
+	interval time.Duration
+}
+
+func NewRetentionJob(repo repository.SubscriptionRepository, maxAge, interval time.Duration) *RetentionJob {
+	return &RetentionJob{repo: repo, maxAge: maxAge, interval: interval}
+}
+
+// Run выполняет очистку сразу и затем с заданным интервалом до отмены ctx
+func (j *RetentionJob) Run(ctx context.Context) {
+	log.Printf("Retention job started: purging subscriptions deleted more than %s ago every %s", j.maxAge, j.interval)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("Retention job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *RetentionJob) purge(ctx context.Context) {
+	purged, err := j.repo.PurgeDeleted(ctx, time.Now().UTC().Add(-j.maxAge))
+	if err != nil {
+		log.Printf("Retention job failed: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Retention job purged %d deleted subscriptions", purged)
+	}
+}
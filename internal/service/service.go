@@ -1,38 +1,284 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
 	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
 	"github.com/google/uuid"
 )
 
 type SubscriptionService interface {
-	CreateSubscription(req *model.CreateSubscriptionRequest) (*model.Subscription, error)
-	GetSubscription(id string) (*model.Subscription, error)
-	UpdateSubscription(id string, req *model.UpdateSubscriptionRequest) error
-	DeleteSubscription(id string) error
-	ListSubscriptions(limit, offset int) ([]*model.Subscription, error)
-	CalculateTotalCost(req *model.CalculateCostRequest) (*model.CalculateCostResponse, error)
+	CreateSubscription(ctx context.Context, req *model.CreateSubscriptionRequest) (*model.Subscription, error)
+	GetSubscription(ctx context.Context, id string) (*model.Subscription, error)
+	UpdateSubscription(ctx context.Context, id string, req *model.UpdateSubscriptionRequest) error
+	DeleteSubscription(ctx context.Context, id string, permanent bool) error
+	RestoreSubscription(ctx context.Context, id string) error
+	ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, limit, offset int) ([]*model.Subscription, error)
+	CalculateTotalCost(ctx context.Context, req *model.CalculateCostRequest) (*model.CalculateCostResponse, error)
+	GetSubscriptionHistory(ctx context.Context, id string) ([]*model.SubscriptionAuditEntry, error)
+	AddDiscount(ctx context.Context, id string, req *model.DiscountRequest) (*model.Discount, error)
+	RemoveDiscount(ctx context.Context, id, discountID string) error
+	PauseSubscription(ctx context.Context, id string) (*model.Subscription, error)
+	ResumeSubscription(ctx context.Context, id string) (*model.Subscription, error)
+	CancelSubscription(ctx context.Context, id string, atPeriodEnd bool) (*model.Subscription, error)
 }
 
 type subscriptionService struct {
-	repo repository.SubscriptionRepository
+	repo    repository.SubscriptionRepository
+	catalog repository.ServiceCatalogRepository
+	users   repository.UserRepository
 }
 
-func NewSubscriptionService(repo repository.SubscriptionRepository) SubscriptionService {
-	return &subscriptionService{repo: repo}
+func NewSubscriptionService(repo repository.SubscriptionRepository, catalog repository.ServiceCatalogRepository,
+	users repository.UserRepository) SubscriptionService {
+	return &subscriptionService{repo: repo, catalog: catalog, users: users}
 }
 
-func (s *subscriptionService) CreateSubscription(req *model.CreateSubscriptionRequest) (*model.Subscription, error) {
-	if req.ServiceName == "" {
-		return nil, fmt.Errorf("service_name is required")
+// defaultProfile — профиль пользователя, который не заводил его через POST /users
+func defaultProfile(userID uuid.UUID) *model.User {
+	return &model.User{ID: userID, Timezone: defaultTimezone, Currency: defaultCurrency}
+}
+
+// userLocation возвращает профиль владельца подписок и его часовой пояс,
+// в котором считаются границы месяцев. Без сохраненного профиля действует
+// профиль по умолчанию.
+func (s *subscriptionService) userLocation(ctx context.Context, userID uuid.UUID) (*model.User, *time.Location, error) {
+	user, err := s.users.GetByID(ctx, userID.String())
+	if errors.Is(err, repository.ErrUserNotFound) {
+		user = defaultProfile(userID)
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load timezone of user %s: %w", userID, err)
+	}
+	return user, loc, nil
+}
+
+// ensureProfiles сохраняет профиль по умолчанию для пользователей, у которых его
+// еще нет: подписки и участники ссылаются на профиль, а клиенты, написанные до
+// появления POST /users, не создают его сами
+func (s *subscriptionService) ensureProfiles(ctx context.Context, userIDs ...uuid.UUID) error {
+	for _, userID := range userIDs {
+		_, err := s.users.GetByID(ctx, userID.String())
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Профиль мог одновременно создать другой запрос
+		if _, err := s.users.Create(ctx, defaultProfile(userID)); err != nil && !errors.Is(err, repository.ErrUserExists) {
+			return err
+		}
+		log.Printf("Service: Created default profile for user %s", userID)
+	}
+	return nil
+}
+
+// memberIDs возвращает идентификаторы участников подписки
+func memberIDs(members []model.SubscriptionMember) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.UserID)
+	}
+	return ids
+}
+
+// resolveService находит сервис каталога по serviceID или по названию и псевдонимам.
+// Название, которого нет в каталоге, допустимо: тогда возвращается nil и подписка
+// хранится со свободным названием, без привязки к каталогу.
+func (s *subscriptionService) resolveService(ctx context.Context, name string, serviceID *int) (*model.Service, error) {
+	if serviceID == nil {
+		svc, err := s.catalog.Resolve(ctx, name)
+		if errors.Is(err, repository.ErrServiceNotFound) {
+			return nil, nil
+		}
+		return svc, err
+	}
+
+	svc, err := s.catalog.GetByID(ctx, strconv.Itoa(*serviceID))
+	if errors.Is(err, repository.ErrServiceNotFound) {
+		return nil, fmt.Errorf("unknown service_id %d", *serviceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(name) != "" {
+		named, err := s.catalog.Resolve(ctx, name)
+		if err != nil && !errors.Is(err, repository.ErrServiceNotFound) {
+			return nil, err
+		}
+		if named == nil || named.ID != svc.ID {
+			return nil, fmt.Errorf("service_name %q does not match service_id %d", name, *serviceID)
+		}
+	}
+	return svc, nil
+}
+
+// subscriptionPrice возвращает цену из запроса, а если она не указана — цену
+// тарифа сервиса: названного в plan или первого в каталоге.
+func subscriptionPrice(req *model.CreateSubscriptionRequest, svc *model.Service) (int, error) {
+	if req.Plan == "" {
+		if req.Price > 0 {
+			return req.Price, nil
+		}
+		if req.Price == 0 && svc != nil && len(svc.Plans) > 0 {
+			return svc.Plans[0].Price, nil
+		}
+		return 0, fmt.Errorf("price must be positive")
+	}
+
+	if svc == nil {
+		return 0, fmt.Errorf("plan can only be used with a service from the catalog")
+	}
+	for _, plan := range svc.Plans {
+		if !strings.EqualFold(plan.Name, req.Plan) {
+			continue
+		}
+		if req.Price < 0 {
+			return 0, fmt.Errorf("price must be positive")
+		}
+		if req.Price == 0 {
+			return plan.Price, nil
+		}
+		return req.Price, nil
+	}
+	return 0, fmt.Errorf("unknown plan %q for service %q", req.Plan, svc.Name)
+}
+
+const maxTrialMonths = 12
+
+// checkTrial проверяет пробный период: длина от 0 до maxTrialMonths месяцев,
+// стоимость неотрицательна и без пробного периода равна нулю
+func checkTrial(months, price int) error {
+	if months < 0 || months > maxTrialMonths {
+		return fmt.Errorf("trial_months must be between 0 and %d", maxTrialMonths)
+	}
+	if price < 0 {
+		return fmt.Errorf("trial_price must not be negative")
+	}
+	if months == 0 && price != 0 {
+		return fmt.Errorf("trial_price requires trial_months")
+	}
+	return nil
+}
+
+// checkUserAccess не дает пользователю без роли администратора работать
+// с подписками других пользователей. Пустой userID заменяется на вызывающего.
+func checkUserAccess(ctx context.Context, userID *string) error {
+	scopeID, scoped := auth.UserScope(ctx)
+	if !scoped {
+		return nil
+	}
+	if *userID == "" {
+		*userID = scopeID.String()
+		return nil
+	}
+	if parsed, err := uuid.Parse(*userID); err == nil && parsed != scopeID {
+		return fmt.Errorf("%w: access to another user's subscriptions", auth.ErrForbidden)
+	}
+	return nil
+}
+
+const maxLabelLength = 64
+
+// foldLabel приводит категорию или тег к нижнему регистру без лишних пробелов
+func foldLabel(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(value)), " ")
+}
+
+// normalizeLabel нормализует категорию или тег и проверяет длину
+func normalizeLabel(kind, value string) (string, error) {
+	label := foldLabel(value)
+	if len([]rune(label)) > maxLabelLength {
+		return "", fmt.Errorf("%s %q is longer than %d characters", kind, value, maxLabelLength)
+	}
+	return label, nil
+}
+
+// normalizeTags нормализует теги, убирает повторы и сортирует их.
+// nil остается nil: в запросе на изменение это означает «теги не менять».
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
 	}
-	if req.Price <= 0 {
-		return nil, fmt.Errorf("price must be positive")
+
+	normalized := []string{}
+	for _, tag := range tags {
+		label, err := normalizeLabel("tag", tag)
+		if err != nil {
+			return nil, err
+		}
+		if label == "" {
+			return nil, fmt.Errorf("tags must not be empty")
+		}
+		if !slices.Contains(normalized, label) {
+			normalized = append(normalized, label)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+const maxMembers = 20
+
+// normalizeMembers проверяет участников общей подписки и сортирует их по user_id.
+// Участник задает либо фиксированную сумму amount, либо вес weight; без обоих вес равен 1.
+// nil остается nil: в запросе на изменение это означает «участников не менять».
+func normalizeMembers(members []model.SubscriptionMember) ([]model.SubscriptionMember, error) {
+	if members == nil {
+		return nil, nil
+	}
+	if len(members) > maxMembers {
+		return nil, fmt.Errorf("subscription can have at most %d members", maxMembers)
+	}
+
+	normalized := make([]model.SubscriptionMember, 0, len(members))
+	for _, member := range members {
+		if member.UserID == uuid.Nil {
+			return nil, fmt.Errorf("member user_id is required")
+		}
+		if slices.ContainsFunc(normalized, func(m model.SubscriptionMember) bool { return m.UserID == member.UserID }) {
+			return nil, fmt.Errorf("member %s is listed twice", member.UserID)
+		}
+		switch {
+		case member.Amount != nil && member.Weight != 0:
+			return nil, fmt.Errorf("member %s: weight and amount are mutually exclusive", member.UserID)
+		case member.Amount != nil && *member.Amount < 0:
+			return nil, fmt.Errorf("member %s: amount must not be negative", member.UserID)
+		case member.Weight < 0:
+			return nil, fmt.Errorf("member %s: weight must be positive", member.UserID)
+		case member.Amount == nil && member.Weight == 0:
+			member.Weight = 1
+		}
+		normalized = append(normalized, member)
+	}
+	slices.SortFunc(normalized, func(a, b model.SubscriptionMember) int {
+		return strings.Compare(a.UserID.String(), b.UserID.String())
+	})
+	return normalized, nil
+}
+
+func (s *subscriptionService) CreateSubscription(ctx context.Context, req *model.CreateSubscriptionRequest) (*model.Subscription, error) {
+	if err := checkUserAccess(ctx, &req.UserID); err != nil {
+		return nil, err
+	}
+	if req.ServiceName == "" && req.ServiceID == nil {
+		return nil, fmt.Errorf("service_name or service_id is required")
 	}
 	if req.UserID == "" {
 		return nil, fmt.Errorf("user_id is required")
@@ -46,67 +292,253 @@ func (s *subscriptionService) CreateSubscription(req *model.CreateSubscriptionRe
 		return nil, fmt.Errorf("invalid user_id format: must be valid UUID")
 	}
 
-	startDate, err := time.Parse("01-2006", req.StartDate)
+	_, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTrial(req.TrialMonths, req.TrialPrice); err != nil {
+		return nil, err
+	}
+	startDate, _, err := model.MonthRange(req.StartDate, loc)
 	if err != nil {
 		return nil, fmt.Errorf("invalid start_date format: %w", err)
 	}
+	trialEndsAt, endDate := model.SubscriptionDates(startDate, req.TrialMonths, loc)
+
+	svc, err := s.resolveService(ctx, req.ServiceName, req.ServiceID)
+	if err != nil {
+		return nil, err
+	}
 
-	endDate := startDate.AddDate(0, 1, 0)
+	price, err := subscriptionPrice(req, svc)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := normalizeLabel("category", req.Category)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	members, err := normalizeMembers(req.Members)
+	if err != nil {
+		return nil, err
+	}
 
 	subscription := &model.Subscription{
 		ServiceName: req.ServiceName,
-		Price:       req.Price,
+		Price:       price,
 		UserID:      userID,
 		StartDate:   startDate,
 		EndDate:     endDate,
+		Category:    category,
+		Tags:        tags,
+		Members:     members,
+		TrialMonths: req.TrialMonths,
+		TrialPrice:  req.TrialPrice,
+		TrialEndsAt: trialEndsAt,
+		AutoRenew:   req.AutoRenew,
+	}
+	if svc != nil {
+		subscription.ServiceName = svc.Name
+		subscription.ServiceID = &svc.ID
 	}
 
-	createdSubscription, err := s.repo.Create(subscription)
+	if err := s.ensureProfiles(ctx, append([]uuid.UUID{userID}, memberIDs(members)...)...); err != nil {
+		return nil, err
+	}
+
+	var createdSubscription *model.Subscription
+	err = s.repo.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+		created, err := repo.Create(ctx, subscription)
+		if err != nil {
+			return err
+		}
+		createdSubscription = created
+
+		withStatus(subscription)
+		return enqueueEvent(ctx, repo, model.EventSubscriptionCreated, subscription)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("Service: Created subscription %s for user %s", createdSubscription.ID, createdSubscription.UserID)
+	log.Printf("Service: Created subscription %d for user %s", createdSubscription.ID, createdSubscription.UserID)
 	return subscription, nil
 }
 
-func (s *subscriptionService) GetSubscription(id string) (*model.Subscription, error) {
+func (s *subscriptionService) GetSubscription(ctx context.Context, id string) (*model.Subscription, error) {
 	if id == "" {
 		return nil, fmt.Errorf("id is required")
 	}
-	return s.repo.GetByID(id)
+
+	sub, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	withStatus(sub)
+	return sub, nil
 }
 
-func (s *subscriptionService) UpdateSubscription(id string, req *model.UpdateSubscriptionRequest) error {
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, id string, req *model.UpdateSubscriptionRequest) error {
 	if id == "" {
 		return fmt.Errorf("id is required")
 	}
 
+	if req.UserID != nil {
+		if err := checkUserAccess(ctx, req.UserID); err != nil {
+			return err
+		}
+	}
+
+	var newOwner uuid.UUID
 	if req.UserID != nil && *req.UserID != "" {
-		_, err := uuid.Parse(*req.UserID)
-		if err != nil {
+		var err error
+		if newOwner, err = uuid.Parse(*req.UserID); err != nil {
 			return fmt.Errorf("invalid user_id format: must be valid UUID")
 		}
 	}
 
 	if req.StartDate != nil && *req.StartDate != "" {
-		if _, err := time.Parse("01-2006", *req.StartDate); err != nil {
+		if _, _, err := model.MonthRange(*req.StartDate, nil); err != nil {
 			return fmt.Errorf("invalid start_date format: %w", err)
 		}
 	}
 
-	return s.repo.Update(id, req)
+	// Без пробного периода нет и его стоимости
+	if req.TrialMonths != nil && *req.TrialMonths == 0 && req.TrialPrice == nil {
+		req.TrialPrice = new(int)
+	}
+
+	if req.Category != nil {
+		category, err := normalizeLabel("category", *req.Category)
+		if err != nil {
+			return err
+		}
+		req.Category = &category
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return err
+	}
+	req.Tags = tags
+	if req.Members, err = normalizeMembers(req.Members); err != nil {
+		return err
+	}
+
+	if req.ServiceName != nil || req.ServiceID != nil {
+		var name string
+		if req.ServiceName != nil {
+			name = *req.ServiceName
+		}
+		if name == "" && req.ServiceID == nil {
+			return fmt.Errorf("service_name must not be empty")
+		}
+
+		svc, err := s.resolveService(ctx, name, req.ServiceID)
+		if err != nil {
+			return err
+		}
+		req.ServiceID = nil
+		if svc != nil {
+			req.ServiceName = &svc.Name
+			req.ServiceID = &svc.ID
+		}
+	}
+
+	profiles := memberIDs(req.Members)
+	if newOwner != uuid.Nil {
+		profiles = append(profiles, newOwner)
+	}
+	if err := s.ensureProfiles(ctx, profiles...); err != nil {
+		return err
+	}
+
+	// Проверка и изменение выполняются в одной транзакции: между ними подписку
+	// не сможет изменить или удалить другой запрос
+	return s.repo.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+		current, err := repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Границы месяца start_date и конец пробного периода считаются в часовом
+		// поясе владельца подписки после изменения
+		owner := current.UserID
+		if newOwner != uuid.Nil {
+			owner = newOwner
+		}
+		if owner != current.UserID || (req.StartDate != nil && *req.StartDate != "") || req.TrialMonths != nil {
+			if req.Location, err = repo.OwnerLocation(ctx, owner); err != nil {
+				return err
+			}
+		}
+
+		trialMonths, trialPrice := current.TrialMonths, current.TrialPrice
+		if req.TrialMonths != nil {
+			trialMonths = *req.TrialMonths
+		}
+		if req.TrialPrice != nil {
+			trialPrice = *req.TrialPrice
+		}
+		if err := checkTrial(trialMonths, trialPrice); err != nil {
+			return err
+		}
+
+		if err := repo.Update(ctx, id, req); err != nil {
+			return err
+		}
+		return enqueueUpdated(ctx, repo, id)
+	})
 }
 
-func (s *subscriptionService) DeleteSubscription(id string) error {
+func (s *subscriptionService) DeleteSubscription(ctx context.Context, id string, permanent bool) error {
 	if id == "" {
 		return fmt.Errorf("id is required")
 	}
 
-	return s.repo.Delete(id)
+	// Без аутентифицированного администратора безвозвратное удаление запрещено,
+	// в том числе когда аутентификация отключена
+	if p, ok := auth.PrincipalFromContext(ctx); permanent && (!ok || !p.Admin) {
+		return fmt.Errorf("%w: permanent deletion requires admin role", auth.ErrForbidden)
+	}
+
+	// Событие содержит подписку до удаления. Окончательное удаление уже удаленной
+	// подписки события не порождает: о ее удалении уже сообщалось.
+	return s.repo.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+		deleted, err := repo.GetForUpdate(ctx, id)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		if err := repo.Delete(ctx, id, permanent); err != nil {
+			return err
+		}
+		if deleted == nil {
+			return nil
+		}
+		withStatus(deleted)
+		return enqueueEvent(ctx, repo, model.EventSubscriptionDeleted, deleted)
+	})
 }
 
-func (s *subscriptionService) ListSubscriptions(limit, offset int) ([]*model.Subscription, error) {
+func (s *subscriptionService) RestoreSubscription(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+
+	return s.repo.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+		if err := repo.Restore(ctx, id); err != nil {
+			return err
+		}
+		return enqueueUpdated(ctx, repo, id)
+	})
+}
+
+func (s *subscriptionService) ListSubscriptions(ctx context.Context, filter model.SubscriptionFilter, limit, offset int) ([]*model.Subscription, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -117,15 +549,35 @@ func (s *subscriptionService) ListSubscriptions(limit, offset int) ([]*model.Sub
 		offset = 0
 	}
 
-	return s.repo.List(limit, offset)
+	filter.Category = foldLabel(filter.Category)
+	filter.Tag = foldLabel(filter.Tag)
+
+	subscriptions, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	withStatus(subscriptions...)
+	return subscriptions, nil
 }
 
-func (s *subscriptionService) CalculateTotalCost(req *model.CalculateCostRequest) (*model.CalculateCostResponse, error) {
+func (s *subscriptionService) CalculateTotalCost(ctx context.Context, req *model.CalculateCostRequest) (*model.CalculateCostResponse, error) {
+	if err := checkUserAccess(ctx, &req.UserID); err != nil {
+		return nil, err
+	}
 	if req.StartPeriod == "" || req.EndPeriod == "" {
 		return nil, fmt.Errorf("start_period and end_period are required")
 	}
-	if req.ServiceName == "" {
-		return nil, fmt.Errorf("service_name are required")
+	if req.GroupBy != "" && req.GroupBy != model.GroupByCategory && req.GroupBy != model.GroupByTag && req.GroupBy != model.GroupByMonth {
+		return nil, fmt.Errorf("group_by must be %s, %s or %s", model.GroupByCategory, model.GroupByTag, model.GroupByMonth)
+	}
+	if req.ServiceName == "" && req.ServiceID == nil && req.GroupBy == "" {
+		return nil, fmt.Errorf("service_name or service_id is required")
+	}
+	if req.Perspective == "" {
+		req.Perspective = model.PerspectivePayer
+	}
+	if req.Perspective != model.PerspectivePayer && req.Perspective != model.PerspectiveMember {
+		return nil, fmt.Errorf("perspective must be %s or %s", model.PerspectivePayer, model.PerspectiveMember)
 	}
 	if req.UserID == "" {
 		return nil, fmt.Errorf("user_id are required")
@@ -136,16 +588,163 @@ func (s *subscriptionService) CalculateTotalCost(req *model.CalculateCostRequest
 		return nil, fmt.Errorf("invalid user_id format: must be valid UUID")
 	}
 
-	total, err := s.repo.CalculateTotalCost(req)
+	user, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	req.Location = loc
+
+	if req.ServiceName != "" || req.ServiceID != nil {
+		svc, err := s.resolveService(ctx, req.ServiceName, req.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		if svc != nil {
+			req.ServiceName = svc.Name
+			req.ServiceID = &svc.ID
+		}
+	}
+
+	total, err := s.repo.CalculateTotalCost(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	var groups []*model.CostGroup
+	if req.GroupBy != "" {
+		if groups, err = s.repo.CostBreakdown(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
 	return &model.CalculateCostResponse{
 		TotalCost:   total,
 		UserID:      userID,
 		ServiceName: req.ServiceName,
+		ServiceID:   req.ServiceID,
 		StartPeriod: req.StartPeriod,
 		EndPeriod:   req.EndPeriod,
+		GroupBy:     req.GroupBy,
+		Perspective: req.Perspective,
+		Currency:    user.Currency,
+		Groups:      groups,
 	}, nil
 }
+
+func (s *subscriptionService) GetSubscriptionHistory(ctx context.Context, id string) ([]*model.SubscriptionAuditEntry, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	return s.repo.History(ctx, id)
+}
+
+// checkDiscountValue проверяет размер скидки: процент от 1 до 100, сумма
+// положительна, цена на время скидки неотрицательна
+func checkDiscountValue(kind string, value int) error {
+	switch kind {
+	case model.DiscountPercent:
+		if value < 1 || value > 100 {
+			return fmt.Errorf("percent discount must be between 1 and 100")
+		}
+	case model.DiscountAmount:
+		if value <= 0 {
+			return fmt.Errorf("discount amount must be positive")
+		}
+	case model.DiscountPrice:
+		if value < 0 {
+			return fmt.Errorf("discounted price must not be negative")
+		}
+	default:
+		return fmt.Errorf("kind must be %s, %s or %s", model.DiscountPercent, model.DiscountAmount, model.DiscountPrice)
+	}
+	return nil
+}
+
+// AddDiscount добавляет скидку к подписке. Месяцы start_period и end_period
+// считаются в часовом поясе владельца подписки; без start_period скидка действует
+// с первого месяца после пробного периода.
+func (s *subscriptionService) AddDiscount(ctx context.Context, id string, req *model.DiscountRequest) (*model.Discount, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if err := checkDiscountValue(req.Kind, req.Value); err != nil {
+		return nil, err
+	}
+	if req.Cycles < 0 {
+		return nil, fmt.Errorf("cycles must not be negative")
+	}
+	if req.Cycles > 0 && req.EndPeriod != "" {
+		return nil, fmt.Errorf("end_period and cycles cannot be used together")
+	}
+	description := strings.TrimSpace(req.Description)
+	if len([]rune(description)) > 255 {
+		return nil, fmt.Errorf("description is longer than 255 characters")
+	}
+
+	sub, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	_, loc, err := s.userLocation(ctx, sub.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	discount := &model.Discount{
+		Kind:        req.Kind,
+		Value:       req.Value,
+		StartsAt:    sub.StartDate,
+		Cycles:      req.Cycles,
+		Description: description,
+	}
+	if sub.TrialEndsAt != nil {
+		discount.StartsAt = *sub.TrialEndsAt
+	}
+	if req.StartPeriod != "" {
+		if discount.StartsAt, _, err = model.MonthRange(req.StartPeriod, loc); err != nil {
+			return nil, fmt.Errorf("invalid start_period format: %w", err)
+		}
+	}
+	if req.EndPeriod != "" {
+		_, endsAt, err := model.MonthRange(req.EndPeriod, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_period format: %w", err)
+		}
+		if !endsAt.After(discount.StartsAt) {
+			return nil, fmt.Errorf("end_period must not be before the start of the discount")
+		}
+		discount.EndsAt = &endsAt
+	}
+
+	var created *model.Discount
+	err = s.repo.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+		added, err := repo.AddDiscount(ctx, id, discount)
+		if err != nil {
+			return err
+		}
+		created = added
+		return enqueueUpdated(ctx, repo, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Service: Added %s discount %d to subscription %s", created.Kind, created.ID, id)
+	return created, nil
+}
+
+func (s *subscriptionService) RemoveDiscount(ctx context.Context, id, discountID string) error {
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+	discountIDInt, err := strconv.Atoi(discountID)
+	if err != nil {
+		return fmt.Errorf("invalid discount id format: must be integer")
+	}
+	return s.repo.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+		if err := repo.RemoveDiscount(ctx, id, discountIDInt); err != nil {
+			return err
+		}
+		return enqueueUpdated(ctx, repo, id)
+	})
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+func newTestSubscriptionService() (SubscriptionService, repository.UserRepository) {
+	subs := repository.NewMemorySubscriptionRepository()
+	users := repository.NewMemoryUserRepository(subs)
+	catalog := repository.NewMemoryServiceCatalogRepository(subs)
+	return NewSubscriptionService(subs, catalog, users), users
+}
+
+func userContext(userID uuid.UUID) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: userID.String(), UserID: userID})
+}
+
+func adminContext() context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "admin", Admin: true})
+}
+
+func createTestSubscription(t *testing.T, s SubscriptionService, ctx context.Context, req *model.CreateSubscriptionRequest) string {
+	t.Helper()
+	sub, err := s.CreateSubscription(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+	return strconv.Itoa(sub.ID)
+}
+
+func totalCost(t *testing.T, s SubscriptionService, ctx context.Context, userID uuid.UUID, start, end string) int {
+	t.Helper()
+	resp, err := s.CalculateTotalCost(ctx, &model.CalculateCostRequest{
+		UserID:      userID.String(),
+		ServiceName: "Netflix",
+		StartPeriod: start,
+		EndPeriod:   end,
+	})
+	if err != nil {
+		t.Fatalf("CalculateTotalCost: %v", err)
+	}
+	return resp.TotalCost
+}
+
+func TestCreateSubscriptionValidation(t *testing.T) {
+	userID := uuid.NewString()
+	valid := func() model.CreateSubscriptionRequest {
+		return model.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 500, UserID: userID, StartDate: "01-2025"}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(req *model.CreateSubscriptionRequest)
+	}{
+		{"no service", func(req *model.CreateSubscriptionRequest) { req.ServiceName = "" }},
+		{"no user_id", func(req *model.CreateSubscriptionRequest) { req.UserID = "" }},
+		{"bad user_id", func(req *model.CreateSubscriptionRequest) { req.UserID = "not-a-uuid" }},
+		{"no start_date", func(req *model.CreateSubscriptionRequest) { req.StartDate = "" }},
+		{"bad start_date", func(req *model.CreateSubscriptionRequest) { req.StartDate = "2025-01" }},
+		{"zero price", func(req *model.CreateSubscriptionRequest) { req.Price = 0 }},
+		{"negative trial", func(req *model.CreateSubscriptionRequest) { req.TrialMonths = -1 }},
+		{"long trial", func(req *model.CreateSubscriptionRequest) { req.TrialMonths = maxTrialMonths + 1 }},
+		{"trial price without trial", func(req *model.CreateSubscriptionRequest) { req.TrialPrice = 100 }},
+		{"negative trial price", func(req *model.CreateSubscriptionRequest) { req.TrialMonths, req.TrialPrice = 1, -1 }},
+		{"plan without catalog", func(req *model.CreateSubscriptionRequest) { req.Plan = "Семейный" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestSubscriptionService()
+			req := valid()
+			tt.modify(&req)
+			if _, err := s.CreateSubscription(context.Background(), &req); err == nil {
+				t.Fatal("CreateSubscription succeeded, want validation error")
+			}
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		s, _ := newTestSubscriptionService()
+		req := valid()
+		if _, err := s.CreateSubscription(context.Background(), &req); err != nil {
+			t.Fatalf("CreateSubscription: %v", err)
+		}
+	})
+}
+
+func TestCreateSubscriptionCreatesDefaultProfile(t *testing.T) {
+	s, users := newTestSubscriptionService()
+	userID := uuid.New()
+
+	createTestSubscription(t, s, context.Background(), &model.CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 500, UserID: userID.String(), StartDate: "01-2025",
+	})
+
+	user, err := users.GetByID(context.Background(), userID.String())
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if user.Timezone != "UTC" || user.Currency != "RUB" {
+		t.Errorf("profile = %s/%s, want UTC/RUB", user.Timezone, user.Currency)
+	}
+}
+
+func TestSubscriptionUserScope(t *testing.T) {
+	s, _ := newTestSubscriptionService()
+	owner, other := uuid.New(), uuid.New()
+	ownerCtx, otherCtx := userContext(owner), userContext(other)
+
+	// Пустой user_id заполняется идентификатором вызывающего
+	req := &model.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 500, StartDate: "01-2025"}
+	id := createTestSubscription(t, s, ownerCtx, req)
+	if req.UserID != owner.String() {
+		t.Errorf("user_id = %q, want %q", req.UserID, owner)
+	}
+
+	_, err := s.CreateSubscription(otherCtx, &model.CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 500, UserID: owner.String(), StartDate: "01-2025",
+	})
+	if !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("create for another user: err = %v, want ErrForbidden", err)
+	}
+
+	if _, err := s.GetSubscription(otherCtx, id); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("get another user's subscription: err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteSubscription(otherCtx, id, false); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("delete another user's subscription: err = %v, want ErrNotFound", err)
+	}
+	_, err = s.CalculateTotalCost(otherCtx, &model.CalculateCostRequest{
+		UserID: owner.String(), ServiceName: "Netflix", StartPeriod: "01-2025", EndPeriod: "12-2025",
+	})
+	if !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("cost for another user: err = %v, want ErrForbidden", err)
+	}
+
+	if _, err := s.GetSubscription(ownerCtx, id); err != nil {
+		t.Errorf("get own subscription: %v", err)
+	}
+	if _, err := s.GetSubscription(adminContext(), id); err != nil {
+		t.Errorf("get as admin: %v", err)
+	}
+}
+
+func TestCalculateTotalCostTrialAndDiscount(t *testing.T) {
+	s, _ := newTestSubscriptionService()
+	userID := uuid.New()
+	ctx := context.Background()
+
+	// Пробный период январь–февраль оплачивается один раз, затем март по полной цене
+	id := createTestSubscription(t, s, ctx, &model.CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 1000, UserID: userID.String(), StartDate: "01-2025",
+		TrialMonths: 2, TrialPrice: 100,
+	})
+
+	if got := totalCost(t, s, ctx, userID, "01-2025", "12-2025"); got != 1100 {
+		t.Errorf("cost with trial = %d, want 1100", got)
+	}
+	if got := totalCost(t, s, ctx, userID, "03-2025", "12-2025"); got != 1000 {
+		t.Errorf("cost after trial = %d, want 1000", got)
+	}
+
+	// Без start_period скидка начинается после пробного периода
+	if _, err := s.AddDiscount(ctx, id, &model.DiscountRequest{Kind: model.DiscountPercent, Value: 30, Cycles: 1}); err != nil {
+		t.Fatalf("AddDiscount: %v", err)
+	}
+	if got := totalCost(t, s, ctx, userID, "01-2025", "12-2025"); got != 800 {
+		t.Errorf("cost with discount = %d, want 800", got)
+	}
+}
+
+func TestAddDiscountValidation(t *testing.T) {
+	s, _ := newTestSubscriptionService()
+	ctx := context.Background()
+	id := createTestSubscription(t, s, ctx, &model.CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 1000, UserID: uuid.NewString(), StartDate: "01-2025",
+	})
+
+	tests := []struct {
+		name string
+		req  model.DiscountRequest
+	}{
+		{"unknown kind", model.DiscountRequest{Kind: "gift", Value: 10}},
+		{"zero percent", model.DiscountRequest{Kind: model.DiscountPercent, Value: 0}},
+		{"percent over 100", model.DiscountRequest{Kind: model.DiscountPercent, Value: 101}},
+		{"zero amount", model.DiscountRequest{Kind: model.DiscountAmount, Value: 0}},
+		{"negative price", model.DiscountRequest{Kind: model.DiscountPrice, Value: -1}},
+		{"negative cycles", model.DiscountRequest{Kind: model.DiscountAmount, Value: 10, Cycles: -1}},
+		{"cycles with end", model.DiscountRequest{Kind: model.DiscountAmount, Value: 10, Cycles: 1, EndPeriod: "03-2025"}},
+		{"end before start", model.DiscountRequest{Kind: model.DiscountAmount, Value: 10, StartPeriod: "03-2025", EndPeriod: "02-2025"}},
+		{"bad start", model.DiscountRequest{Kind: model.DiscountAmount, Value: 10, StartPeriod: "2025-03"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			if _, err := s.AddDiscount(ctx, id, &req); err == nil {
+				t.Fatal("AddDiscount succeeded, want validation error")
+			}
+		})
+	}
+}
+
+func TestPauseExcludesMonthsUntilResume(t *testing.T) {
+	s, _ := newTestSubscriptionService()
+	userID := uuid.New()
+	ctx := context.Background()
+
+	// Пробный текущий месяц уже начался и оплачивается, следующий месяц
+	// по полной цене начнется во время паузы
+	now := time.Now().UTC()
+	start := now.Format(model.MonthLayout)
+	end := now.AddDate(0, 3, 1-now.Day()).Format(model.MonthLayout)
+	id := createTestSubscription(t, s, ctx, &model.CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 500, UserID: userID.String(), StartDate: start,
+		TrialMonths: 1, TrialPrice: 10,
+	})
+
+	if got := totalCost(t, s, ctx, userID, start, end); got != 510 {
+		t.Fatalf("cost before pause = %d, want 510", got)
+	}
+
+	sub, err := s.PauseSubscription(ctx, id)
+	if err != nil {
+		t.Fatalf("PauseSubscription: %v", err)
+	}
+	if sub.Status != model.StatusPaused {
+		t.Errorf("status = %q, want %q", sub.Status, model.StatusPaused)
+	}
+	if got := totalCost(t, s, ctx, userID, start, end); got != 10 {
+		t.Errorf("cost while paused = %d, want 10", got)
+	}
+	if _, err := s.PauseSubscription(ctx, id); err == nil {
+		t.Error("pausing a paused subscription succeeded")
+	}
+
+	if _, err := s.ResumeSubscription(ctx, id); err != nil {
+		t.Fatalf("ResumeSubscription: %v", err)
+	}
+	if got := totalCost(t, s, ctx, userID, start, end); got != 510 {
+		t.Errorf("cost after resume = %d, want 510", got)
+	}
+}
+
+func TestDeleteAndRestoreSubscription(t *testing.T) {
+	s, _ := newTestSubscriptionService()
+	owner := uuid.New()
+	ownerCtx := userContext(owner)
+	id := createTestSubscription(t, s, ownerCtx, &model.CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 500, StartDate: "01-2025",
+	})
+
+	if err := s.RestoreSubscription(ownerCtx, id); !errors.Is(err, repository.ErrNotDeleted) {
+		t.Errorf("restore active: err = %v, want ErrNotDeleted", err)
+	}
+
+	if err := s.DeleteSubscription(ownerCtx, id, false); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+	if _, err := s.GetSubscription(ownerCtx, id); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("get deleted: err = %v, want ErrNotFound", err)
+	}
+	if got := totalCost(t, s, ownerCtx, owner, "01-2025", "12-2025"); got != 0 {
+		t.Errorf("cost of deleted subscription = %d, want 0", got)
+	}
+
+	if err := s.RestoreSubscription(ownerCtx, id); err != nil {
+		t.Fatalf("RestoreSubscription: %v", err)
+	}
+	if _, err := s.GetSubscription(ownerCtx, id); err != nil {
+		t.Errorf("get restored: %v", err)
+	}
+	if got := totalCost(t, s, ownerCtx, owner, "01-2025", "12-2025"); got != 500 {
+		t.Errorf("cost of restored subscription = %d, want 500", got)
+	}
+
+	for _, ctx := range []context.Context{context.Background(), ownerCtx} {
+		if err := s.DeleteSubscription(ctx, id, true); !errors.Is(err, auth.ErrForbidden) {
+			t.Errorf("permanent delete without admin: err = %v, want ErrForbidden", err)
+		}
+	}
+
+	if err := s.DeleteSubscription(adminContext(), id, true); err != nil {
+		t.Fatalf("permanent delete as admin: %v", err)
+	}
+	if err := s.RestoreSubscription(adminContext(), id); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("restore permanently deleted: err = %v, want ErrNotFound", err)
+	}
+}
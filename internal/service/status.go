@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+)
+
+var ErrInvalidTransition = errors.New("invalid subscription status transition")
+
+// withStatus заменяет сохраненное состояние подписок состоянием на текущий момент:
+// назначенная отмена вступает в силу, а подписка с прошедшим end_date истекает
+func withStatus(subs ...*model.Subscription) {
+	now := time.Now().UTC()
+	for _, sub := range subs {
+		sub.Status = sub.StatusAt(now)
+	}
+}
+
+// changeStatus в одной транзакции читает подписку, получает у next переход из ее
+// текущего состояния и выполняет его. Возвращает подписку после перехода.
+func (s *subscriptionService) changeStatus(ctx context.Context, id string,
+	next func(sub *model.Subscription, status string, now time.Time) (*model.StatusTransition, error)) (*model.Subscription, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	var sub *model.Subscription
+	err := s.repo.WithTx(ctx, func(repo repository.SubscriptionRepository) error {
+		current, err := repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		transition, err := next(current, current.StatusAt(now), now)
+		if err != nil {
+			return err
+		}
+		if _, err := repo.ChangeStatus(ctx, id, transition); err != nil {
+			return err
+		}
+
+		if sub, err = repo.GetByID(ctx, id); err != nil {
+			return err
+		}
+		withStatus(sub)
+		return enqueueEvent(ctx, repo, model.EventSubscriptionUpdated, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Service: Subscription %s is now %s", id, sub.Status)
+	return sub, nil
+}
+
+// PauseSubscription приостанавливает активную подписку: месяцы, начавшиеся
+// до возобновления, не оплачиваются
+func (s *subscriptionService) PauseSubscription(ctx context.Context, id string) (*model.Subscription, error) {
+	return s.changeStatus(ctx, id, func(sub *model.Subscription, status string, now time.Time) (*model.StatusTransition, error) {
+		if status != model.StatusActive {
+			return nil, fmt.Errorf("%w: cannot pause %s subscription", ErrInvalidTransition, status)
+		}
+		if sub.CancelAt != nil {
+			return nil, fmt.Errorf("%w: subscription is scheduled for cancellation", ErrInvalidTransition)
+		}
+		return &model.StatusTransition{From: status, To: model.StatusPaused, EffectiveAt: now}, nil
+	})
+}
+
+// ResumeSubscription возобновляет приостановленную подписку
+func (s *subscriptionService) ResumeSubscription(ctx context.Context, id string) (*model.Subscription, error) {
+	return s.changeStatus(ctx, id, func(sub *model.Subscription, status string, now time.Time) (*model.StatusTransition, error) {
+		if status != model.StatusPaused {
+			return nil, fmt.Errorf("%w: cannot resume %s subscription", ErrInvalidTransition, status)
+		}
+		return &model.StatusTransition{From: status, To: model.StatusActive, EffectiveAt: now}, nil
+	})
+}
+
+// CancelSubscription отменяет подписку сразу или, с atPeriodEnd, в конце текущего
+// расчетного периода, который считается в часовом поясе владельца. Месяцы,
+// начавшиеся после отмены, не оплачиваются.
+func (s *subscriptionService) CancelSubscription(ctx context.Context, id string, atPeriodEnd bool) (*model.Subscription, error) {
+	var loc *time.Location
+	if atPeriodEnd && id != "" {
+		current, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if _, loc, err = s.userLocation(ctx, current.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.changeStatus(ctx, id, func(sub *model.Subscription, status string, now time.Time) (*model.StatusTransition, error) {
+		if status != model.StatusActive && (status != model.StatusPaused || atPeriodEnd) {
+			return nil, fmt.Errorf("%w: cannot cancel %s subscription", ErrInvalidTransition, status)
+		}
+
+		effectiveAt := now
+		if atPeriodEnd {
+			if sub.CancelAt != nil {
+				return nil, fmt.Errorf("%w: subscription is already scheduled for cancellation", ErrInvalidTransition)
+			}
+			effectiveAt = sub.PeriodEnd(now, loc)
+		}
+		return &model.StatusTransition{From: status, To: model.StatusCancelled, EffectiveAt: effectiveAt}, nil
+	})
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/auth"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTimezone = "UTC"
+	defaultCurrency = "RUB"
+)
+
+type UserService interface {
+	CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.User, error)
+	GetUser(ctx context.Context, id string) (*model.User, error)
+	ListUsers(ctx context.Context, limit, offset int) ([]*model.User, error)
+	UpdateUser(ctx context.Context, id string, req *model.UpdateUserRequest) (*model.User, error)
+	DeleteUser(ctx context.Context, id string) error
+}
+
+type userService struct {
+	repo repository.UserRepository
+	// cascadeDelete удаляет подписки вместе с пользователем; иначе пользователя
+	// с подписками удалить нельзя
+	cascadeDelete bool
+}
+
+func NewUserService(repo repository.UserRepository, cascadeDelete bool) UserService {
+	return &userService{repo: repo, cascadeDelete: cascadeDelete}
+}
+
+// checkProfileAccess не дает пользователю без роли администратора работать
+// с чужим профилем. Пустой id заменяется на вызывающего.
+func checkProfileAccess(ctx context.Context, id *string) error {
+	scopeID, scoped := auth.UserScope(ctx)
+	if !scoped {
+		return nil
+	}
+	if *id == "" {
+		*id = scopeID.String()
+		return nil
+	}
+	if parsed, err := uuid.Parse(*id); err == nil && parsed != scopeID {
+		return fmt.Errorf("%w: access to another user's profile", auth.ErrForbidden)
+	}
+	return nil
+}
+
+// normalizeTimezone проверяет название часового пояса IANA; пустое — UTC
+func normalizeTimezone(timezone string) (string, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		return defaultTimezone, nil
+	}
+	// Local зависит от настроек сервера, а не от пользователя
+	if timezone == "Local" {
+		return "", fmt.Errorf("unknown timezone %q", timezone)
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", fmt.Errorf("unknown timezone %q", timezone)
+	}
+	return timezone, nil
+}
+
+// normalizeCurrency проверяет трехбуквенный код валюты ISO 4217; пустой — RUB
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return defaultCurrency, nil
+	}
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("currency must be a three-letter ISO 4217 code, got %q", currency)
+	}
+	return currency, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", nil
+	}
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return "", fmt.Errorf("invalid email %q", email)
+	}
+	return email, nil
+}
+
+// applyProfile проверяет и переносит в user переданные поля профиля
+func applyProfile(user *model.User, name, email, timezone, currency *string) error {
+	var err error
+	if name != nil {
+		user.Name = strings.TrimSpace(*name)
+		if len([]rune(user.Name)) > 255 {
+			return fmt.Errorf("name is longer than 255 characters")
+		}
+	}
+	if email != nil {
+		if user.Email, err = normalizeEmail(*email); err != nil {
+			return err
+		}
+	}
+	if timezone != nil {
+		if user.Timezone, err = normalizeTimezone(*timezone); err != nil {
+			return err
+		}
+	}
+	if currency != nil {
+		if user.Currency, err = normalizeCurrency(*currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *userService) CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.User, error) {
+	if err := checkProfileAccess(ctx, &req.ID); err != nil {
+		return nil, err
+	}
+	if req.ID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	userID, err := uuid.Parse(req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id format: must be valid UUID")
+	}
+
+	user := &model.User{ID: userID}
+	if err := applyProfile(user, &req.Name, &req.Email, &req.Timezone, &req.Currency); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Service: Created user %s", created.ID)
+	return created, nil
+}
+
+func (s *userService) GetUser(ctx context.Context, id string) (*model.User, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if err := checkProfileAccess(ctx, &id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *userService) ListUsers(ctx context.Context, limit, offset int) ([]*model.User, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, limit, offset)
+}
+
+func (s *userService) UpdateUser(ctx context.Context, id string, req *model.UpdateUserRequest) (*model.User, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if err := checkProfileAccess(ctx, &id); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProfile(user, req.Name, req.Email, req.Timezone, req.Currency); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Update(ctx, user)
+}
+
+func (s *userService) DeleteUser(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+	if err := checkProfileAccess(ctx, &id); err != nil {
+		return err
+	}
+
+	err := s.repo.Delete(ctx, id, s.cascadeDelete)
+	if errors.Is(err, repository.ErrUserInUse) {
+		return fmt.Errorf("%w: delete them first", err)
+	}
+	return err
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/model"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/notify"
+	"github.com/ZeroZeroZerooZeroo/subscription-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	minWebhookSecretLen = 16
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.WebhookWithSecret, error)
+	ListWebhooks(ctx context.Context) ([]*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, id string, limit, offset int) ([]*model.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo}
+}
+
+// generateWebhookSecret возвращает случайный секрет подписи
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(secret), nil
+}
+
+// normalizeWebhookEvents проверяет типы событий; пустой список — все события
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return slices.Clone(model.WebhookEvents), nil
+	}
+	for _, event := range events {
+		if !slices.Contains(model.WebhookEvents, event) {
+			return nil, fmt.Errorf("unknown event %q, expected one of %v", event, model.WebhookEvents)
+		}
+	}
+	events = slices.Clone(events)
+	slices.Sort(events)
+	return slices.Compact(events), nil
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.WebhookWithSecret, error) {
+	url, err := normalizeWebhookURL("url", req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if url == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	} else if len(secret) < minWebhookSecretLen {
+		return nil, fmt.Errorf("secret must be at least %d characters", minWebhookSecretLen)
+	}
+
+	created, err := s.repo.Create(ctx, &model.Webhook{URL: url, Events: events, Secret: secret})
+	if err != nil {
+		return nil, err
+	}
+	return &model.WebhookWithSecret{Webhook: *created, Secret: secret}, nil
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, id string, limit, offset int) ([]*model.WebhookDelivery, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.ListDeliveries(ctx, id, limit, offset)
+}
+
+// enqueueEvent ставит событие eventType о подписке sub в очередь доставки webhook.
+// Вызывается в транзакции изменения подписки repo: если поставить событие не удалось,
+// изменение откатывается вместе с ним, и событие не теряется.
+func enqueueEvent(ctx context.Context, repo repository.SubscriptionRepository, eventType string, sub *model.Subscription) error {
+	event := &model.WebhookEvent{
+		ID:           uuid.NewString(),
+		Type:         eventType,
+		CreatedAt:    time.Now().UTC(),
+		Subscription: sub,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	enqueued, err := repo.EnqueueEvent(ctx, event, payload)
+	if err != nil {
+		return err
+	}
+	if enqueued > 0 {
+		log.Printf("Event %s %s for subscription %d queued for %d webhooks", eventType, event.ID, sub.ID, enqueued)
+	}
+	return nil
+}
+
+// enqueueUpdated ставит subscription.updated с состоянием подписки id после изменения
+func enqueueUpdated(ctx context.Context, repo repository.SubscriptionRepository, id string) error {
+	sub, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	withStatus(sub)
+	return enqueueEvent(ctx, repo, model.EventSubscriptionUpdated, sub)
+}
+
+// WebhookDispatcher периодически доставляет события из очереди на webhook. Каждая
+// доставка перед отправкой занимается на время ожидания ответа, поэтому задачи
+// нескольких реплик не отправляют одно событие одновременно.
+type WebhookDispatcher struct {
+	repo        repository.WebhookRepository
+	poster      *notify.EventPoster
+	interval    time.Duration
+	timeout     time.Duration
+	batchSize   int
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+func NewWebhookDispatcher(repo repository.WebhookRepository, interval, timeout time.Duration, batchSize, maxAttempts int,
+	backoff, maxBackoff time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:        repo,
+		poster:      notify.NewEventPoster(timeout),
+		interval:    interval,
+		timeout:     timeout,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		maxBackoff:  maxBackoff,
+	}
+}
+
+// Run доставляет события сразу и затем с заданным интервалом до отмены ctx
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	log.Printf("Webhook dispatcher started: delivering subscription events every %s", d.interval)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		delivered, err := d.DeliverDue(ctx)
+		if err != nil {
+			log.Printf("Webhook dispatcher failed: %v", err)
+		}
+		if delivered > 0 {
+			log.Printf("Webhook dispatcher delivered %d events", delivered)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue отправляет до batchSize событий, время попытки которых наступило,
+// и возвращает число успешно доставленных
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) (int, error) {
+	hooks := make(map[int]*model.Webhook)
+	delivered := 0
+	for i := 0; i < d.batchSize; i++ {
+		now := time.Now().UTC()
+		// Аренда с запасом покрывает ожидание ответа; после нее доставку
+		// повторит любая задача, например если реплика остановилась
+		delivery, err := d.repo.ClaimNext(ctx, now, now.Add(2*d.timeout))
+		if err != nil || delivery == nil {
+			return delivered, err
+		}
+
+		hook, ok := hooks[delivery.WebhookID]
+		if !ok {
+			hook, err = d.repo.GetByID(ctx, strconv.Itoa(delivery.WebhookID))
+			// Удаленный webhook удаляется вместе с журналом доставок
+			if errors.Is(err, repository.ErrWebhookNotFound) {
+				continue
+			}
+			if err != nil {
+				return delivered, err
+			}
+			hooks[hook.ID] = hook
+		}
+
+		if d.attempt(ctx, hook, delivery, now) {
+			delivered++
+		}
+		if err := d.repo.SaveAttempt(ctx, delivery); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// attempt отправляет событие и записывает результат в delivery. Неудачная попытка
+// откладывает следующую на backoff·2^(attempts-1), но не больше maxBackoff;
+// после maxAttempts попыток доставка получает статус failed.
+func (d *WebhookDispatcher) attempt(ctx context.Context, hook *model.Webhook, delivery *model.WebhookDelivery, now time.Time) bool {
+	status, err := d.poster.Post(ctx, hook, delivery, now)
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.NextAttemptAt = nil
+
+	if err == nil {
+		deliveredAt := time.Now().UTC()
+		delivery.Status = model.DeliverySent
+		delivery.Error = ""
+		delivery.DeliveredAt = &deliveredAt
+		return true
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Status = model.DeliveryFailed
+		log.Printf("Webhook delivery %d to %s failed after %d attempts: %v", delivery.ID, hook.URL, delivery.Attempts, err)
+		return false
+	}
+
+	delay := d.backoff
+	for i := 1; i < delivery.Attempts && delay < d.maxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.maxBackoff)
+	nextAttemptAt := now.Add(delay)
+	delivery.Status = model.DeliveryPending
+	delivery.NextAttemptAt = &nextAttemptAt
+	log.Printf("Webhook delivery %d to %s failed (attempt %d), retrying at %s: %v",
+		delivery.ID, hook.URL, delivery.Attempts, nextAttemptAt.Format(time.RFC3339), err)
+	return false
+}
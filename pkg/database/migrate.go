@@ -8,31 +8,144 @@ import (
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
 //go:embed "migrations/*.sql"
 var migrationsFS embed.FS
 
-func RunMigrations(connectionString string) error {
+// Схема SQLite отличается типами колонок, поэтому у нее свой набор миграций
+//
+//go:embed "migrations_sqlite/*.sql"
+var sqliteMigrationsFS embed.FS
+
+// Migrator управляет версией схемы по встроенному набору миграций
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// NewMigrator создает Migrator для PostgreSQL по строке подключения вида postgres://
+func NewMigrator(connectionString string) (*Migrator, error) {
 
 	d, err := iofs.New(migrationsFS, "migrations")
 	if err != nil {
-		return fmt.Errorf("failed to create migration source: %w", err)
+		return nil, fmt.Errorf("failed to create migration source: %w", err)
 	}
 
 	m, err := migrate.NewWithSourceInstance("iofs", d, connectionString)
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return nil, fmt.Errorf("failed to create migrate instance: %w", redactError(err, connectionString))
+	}
+
+	return &Migrator{m: m}, nil
+}
+
+// NewSQLiteMigrator создает Migrator для файла базы SQLite по пути path
+func NewSQLiteMigrator(path string) (*Migrator, error) {
+
+	d, err := iofs.New(sqliteMigrationsFS, "migrations_sqlite")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration source: %w", err)
+	}
+
+	// Драйвер миграций закрывает переданное соединение, поэтому открываем отдельное,
+	// с отключенными на время миграций внешними ключами
+	db, err := openSQLite(path, sqliteMigrationPragmas)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", d, "sqlite", driver)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+
+	return &Migrator{m: m}, nil
+}
+
+func (m *Migrator) Close() {
+	if srcErr, dbErr := m.m.Close(); srcErr != nil || dbErr != nil {
+		log.Printf("Error closing migrator: source=%v database=%v", srcErr, dbErr)
+	}
+}
+
+// Up применяет steps следующих миграций; steps <= 0 применяет все
+func (m *Migrator) Up(steps int) error {
+	if steps <= 0 {
+		return noChange(m.m.Up())
+	}
+	return noChange(m.m.Steps(steps))
+}
+
+// Down откатывает steps последних миграций; steps <= 0 откатывает все
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return noChange(m.m.Down())
+	}
+	return noChange(m.m.Steps(-steps))
+}
+
+// Goto переводит схему вверх или вниз к версии version
+func (m *Migrator) Goto(version uint) error {
+	return noChange(m.m.Migrate(version))
+}
+
+// Version возвращает текущую версию схемы и признак незавершенной миграции.
+// Для пустой базы возвращается версия 0.
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак
+// незавершенной миграции. Используется для ручного восстановления после сбоя.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+func noChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Println("No new migrations to run")
+		return nil
+	}
+	return err
+}
+
+func RunMigrations(connectionString string) error {
+	m, err := NewMigrator(connectionString)
+	if err != nil {
+		return err
 	}
 	defer m.Close()
 
+	return up(m)
+}
+
+// RunSQLiteMigrations применяет миграции к файлу базы SQLite по пути path
+func RunSQLiteMigrations(path string) error {
+	m, err := NewSQLiteMigrator(path)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return up(m)
+}
+
+func up(m *Migrator) error {
 	log.Println("Running database migrations")
-	if err := m.Up(); err != nil {
-		if errors.Is(err, migrate.ErrNoChange) {
-			log.Println("No new migrations to run")
-			return nil
-		}
+	if err := m.Up(0); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
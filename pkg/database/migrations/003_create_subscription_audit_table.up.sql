@@ -0,0 +1,12 @@
+CREATE TABLE subscription_audit (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL,
+    user_id UUID NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMP NOT NULL,
+    before_data JSONB,
+    after_data JSONB
+);
+
+CREATE INDEX idx_subscription_audit_subscription ON subscription_audit(subscription_id, changed_at);
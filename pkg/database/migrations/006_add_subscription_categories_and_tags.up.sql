@@ -0,0 +1,15 @@
+ALTER TABLE subscriptions ADD COLUMN category VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE subscriptions ADD COLUMN custom_category BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE subscriptions SET category = services.category
+FROM services WHERE services.id = subscriptions.service_id;
+
+CREATE INDEX idx_subscriptions_category ON subscriptions(user_id, category);
+
+CREATE TABLE subscription_tags (
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (subscription_id, tag)
+);
+
+CREATE INDEX idx_subscription_tags_tag ON subscription_tags(tag);
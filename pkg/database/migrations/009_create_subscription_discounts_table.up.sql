@@ -0,0 +1,13 @@
+CREATE TABLE subscription_discounts (
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    value INTEGER NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,
+    cycles INTEGER NOT NULL DEFAULT 0,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_subscription_discounts_subscription ON subscription_discounts(subscription_id);
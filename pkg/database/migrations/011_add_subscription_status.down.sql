@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS subscription_transitions;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS cancel_at;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS status;
@@ -0,0 +1,25 @@
+CREATE TABLE notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    renewal_reminders BOOLEAN NOT NULL DEFAULT TRUE,
+    email BOOLEAN NOT NULL DEFAULT TRUE,
+    webhook_url VARCHAR(2048) NOT NULL DEFAULT '',
+    lead_days INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE notification_deliveries (
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(16) NOT NULL,
+    renews_at TIMESTAMP NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP,
+    claimed_at TIMESTAMP,
+    UNIQUE (subscription_id, channel, renews_at)
+);
+
+CREATE INDEX idx_notification_deliveries_user ON notification_deliveries(user_id, id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_subscriptions_cost;
+DROP TABLE IF EXISTS subscriptions;
@@ -0,0 +1,10 @@
+CREATE TABLE subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_subscriptions_cost ON subscriptions(user_id, service_name, start_date, end_date);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_subscription_audit_subscription;
+DROP TABLE IF EXISTS subscription_audit;
@@ -0,0 +1,12 @@
+CREATE TABLE subscription_audit (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL,
+    before_data TEXT,
+    after_data TEXT
+);
+
+CREATE INDEX idx_subscription_audit_subscription ON subscription_audit(subscription_id, changed_at);
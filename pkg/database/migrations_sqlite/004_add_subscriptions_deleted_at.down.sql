@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_subscriptions_deleted_at;
+DELETE FROM subscriptions WHERE deleted_at IS NOT NULL;
+ALTER TABLE subscriptions DROP COLUMN deleted_at;
@@ -0,0 +1,22 @@
+-- SQLite не удаляет столбец с внешним ключом, поэтому таблица пересоздается
+CREATE TABLE subscriptions_without_service (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP
+);
+
+INSERT INTO subscriptions_without_service (id, service_name, price, user_id, start_date, end_date, deleted_at)
+SELECT id, service_name, price, user_id, start_date, end_date, deleted_at FROM subscriptions;
+
+DROP TABLE subscriptions;
+ALTER TABLE subscriptions_without_service RENAME TO subscriptions;
+
+CREATE INDEX idx_subscriptions_cost ON subscriptions(user_id, service_name, start_date, end_date);
+CREATE INDEX idx_subscriptions_deleted_at ON subscriptions(deleted_at) WHERE deleted_at IS NOT NULL;
+
+DROP TABLE IF EXISTS service_lookup;
+DROP TABLE IF EXISTS services;
@@ -0,0 +1,20 @@
+CREATE TABLE services (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    aliases TEXT NOT NULL DEFAULT '[]',
+    category TEXT NOT NULL DEFAULT '',
+    plans TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Нормализованные название и псевдонимы: по ним свободный текст сопоставляется с сервисом
+CREATE TABLE service_lookup (
+    lookup TEXT PRIMARY KEY,
+    service_id INTEGER NOT NULL REFERENCES services(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_service_lookup_service ON service_lookup(service_id);
+
+ALTER TABLE subscriptions ADD COLUMN service_id INTEGER REFERENCES services(id);
+
+CREATE INDEX idx_subscriptions_service_cost ON subscriptions(user_id, service_id, start_date, end_date);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS subscription_tags;
+DROP INDEX IF EXISTS idx_subscriptions_category;
+ALTER TABLE subscriptions DROP COLUMN custom_category;
+ALTER TABLE subscriptions DROP COLUMN category;
@@ -0,0 +1,15 @@
+ALTER TABLE subscriptions ADD COLUMN category TEXT NOT NULL DEFAULT '';
+ALTER TABLE subscriptions ADD COLUMN custom_category BOOLEAN NOT NULL DEFAULT 0;
+
+UPDATE subscriptions SET category = COALESCE(
+    (SELECT category FROM services WHERE services.id = subscriptions.service_id), '');
+
+CREATE INDEX idx_subscriptions_category ON subscriptions(user_id, category);
+
+CREATE TABLE subscription_tags (
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (subscription_id, tag)
+);
+
+CREATE INDEX idx_subscription_tags_tag ON subscription_tags(tag);
@@ -0,0 +1,28 @@
+-- SQLite не удаляет внешний ключ, поэтому таблица пересоздается
+CREATE TABLE subscriptions_without_user (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    service_id INTEGER REFERENCES services(id),
+    category TEXT NOT NULL DEFAULT '',
+    custom_category BOOLEAN NOT NULL DEFAULT 0
+);
+
+INSERT INTO subscriptions_without_user (id, service_name, price, user_id, start_date, end_date,
+    deleted_at, service_id, category, custom_category)
+SELECT id, service_name, price, user_id, start_date, end_date,
+    deleted_at, service_id, category, custom_category FROM subscriptions;
+
+DROP TABLE subscriptions;
+ALTER TABLE subscriptions_without_user RENAME TO subscriptions;
+
+CREATE INDEX idx_subscriptions_cost ON subscriptions(user_id, service_name, start_date, end_date);
+CREATE INDEX idx_subscriptions_deleted_at ON subscriptions(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_subscriptions_service_cost ON subscriptions(user_id, service_id, start_date, end_date);
+CREATE INDEX idx_subscriptions_category ON subscriptions(user_id, category);
+
+DROP TABLE IF EXISTS users;
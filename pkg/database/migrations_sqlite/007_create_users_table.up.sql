@@ -0,0 +1,40 @@
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    currency TEXT NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Пользователи, у которых уже есть подписки, получают профиль по умолчанию
+INSERT INTO users (id) SELECT DISTINCT user_id FROM subscriptions;
+
+-- SQLite добавляет внешний ключ только пересозданием таблицы. Миграции выполняются
+-- с отключенными внешними ключами, поэтому теги подписок при этом сохраняются.
+CREATE TABLE subscriptions_with_user (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id),
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    service_id INTEGER REFERENCES services(id),
+    category TEXT NOT NULL DEFAULT '',
+    custom_category BOOLEAN NOT NULL DEFAULT 0
+);
+
+INSERT INTO subscriptions_with_user (id, service_name, price, user_id, start_date, end_date,
+    deleted_at, service_id, category, custom_category)
+SELECT id, service_name, price, user_id, start_date, end_date,
+    deleted_at, service_id, category, custom_category FROM subscriptions;
+
+DROP TABLE subscriptions;
+ALTER TABLE subscriptions_with_user RENAME TO subscriptions;
+
+CREATE INDEX idx_subscriptions_cost ON subscriptions(user_id, service_name, start_date, end_date);
+CREATE INDEX idx_subscriptions_deleted_at ON subscriptions(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_subscriptions_service_cost ON subscriptions(user_id, service_id, start_date, end_date);
+CREATE INDEX idx_subscriptions_category ON subscriptions(user_id, category);
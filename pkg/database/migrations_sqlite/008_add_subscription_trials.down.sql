@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions DROP COLUMN trial_ends_at;
+ALTER TABLE subscriptions DROP COLUMN trial_price;
+ALTER TABLE subscriptions DROP COLUMN trial_months;
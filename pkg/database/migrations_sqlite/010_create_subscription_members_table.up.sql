@@ -0,0 +1,9 @@
+CREATE TABLE subscription_members (
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    weight INTEGER NOT NULL DEFAULT 0,
+    amount INTEGER,
+    PRIMARY KEY (subscription_id, user_id)
+);
+
+CREATE INDEX idx_subscription_members_user ON subscription_members(user_id);
@@ -0,0 +1,13 @@
+ALTER TABLE subscriptions ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active';
+ALTER TABLE subscriptions ADD COLUMN cancel_at TIMESTAMP;
+
+CREATE TABLE subscription_transitions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    effective_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_subscription_transitions_subscription ON subscription_transitions(subscription_id);
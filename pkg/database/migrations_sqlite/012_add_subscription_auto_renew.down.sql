@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_subscriptions_renewal;
+ALTER TABLE subscriptions DROP COLUMN auto_renew;
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions ADD COLUMN auto_renew BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_subscriptions_renewal ON subscriptions(end_date) WHERE auto_renew AND deleted_at IS NULL;
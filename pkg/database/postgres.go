@@ -1,28 +1,103 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	_ "github.com/lib/pq"
 )
 
+const (
+	initialRetryDelay = 500 * time.Millisecond
+	maxRetryDelay     = 5 * time.Second
+)
+
 type DB struct {
 	*sql.DB
+	// latest — версия последней встроенной миграции для драйвера этой базы
+	latest uint
 }
 
-func NewPostgres(connectionString string) (*DB, error) {
+// PoolOptions настраивает пул соединений. Нулевые значения оставляют
+// значения database/sql по умолчанию.
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// ConnectTimeout ограничивает общее время ожидания базы при подключении
+	ConnectTimeout time.Duration
+}
+
+func NewPostgres(ctx context.Context, connectionString string, opts PoolOptions) (*DB, error) {
 	db, err := sql.Open("postgres", connectionString)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, fmt.Errorf("failed to open database: %w", redactError(err, connectionString))
 	}
 
-	if err := db.Ping(); err != nil {
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	if opts.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(opts.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+
+	if err := pingWithRetry(ctx, db, opts.ConnectTimeout, connectionString); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	log.Println("Successfully connected to PostgreSQL")
-	return &DB{db}, nil
+	return &DB{DB: db, latest: latestPostgresVersion}, nil
+}
+
+// pingWithRetry повторяет проверку соединения с экспоненциальной задержкой,
+// пока база не ответит или не истечет timeout. Это позволяет сервису стартовать
+// одновременно с базой, например в docker compose.
+func pingWithRetry(ctx context.Context, db *sql.DB, timeout time.Duration, connectionString string) error {
+	if timeout <= 0 {
+		return redactError(db.PingContext(ctx), connectionString)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delay := initialRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := redactError(db.PingContext(ctx), connectionString)
+		if err == nil {
+			return nil
+		}
+
+		log.Printf("Database is not ready (attempt %d): %v, retrying in %s", attempt, err, delay)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("database is not available after %d attempts: %w", attempt, err)
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// LogStats периодически пишет в журнал статистику пула соединений до отмены ctx
+func (db *DB) LogStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := db.Stats()
+			log.Printf("Database pool: open=%d in_use=%d idle=%d wait_count=%d wait_duration=%s max_idle_closed=%d max_lifetime_closed=%d",
+				stats.OpenConnections, stats.InUse, stats.Idle, stats.WaitCount, stats.WaitDuration,
+				stats.MaxIdleClosed, stats.MaxLifetimeClosed)
+		}
+	}
 }
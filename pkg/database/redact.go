@@ -0,0 +1,80 @@
+package database
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var dsnPassword = regexp.MustCompile(`password\s*=\s*('(?:[^'\\]|\\.)*'|\S+)`)
+
+// redactError убирает пароль из текста ошибки: драйверы и golang-migrate
+// при ошибке разбора включают в сообщение исходную строку подключения
+func redactError(err error, connectionString string) error {
+	if err == nil {
+		return nil
+	}
+
+	message := err.Error()
+	for _, secret := range dsnSecrets(connectionString) {
+		if secret != "" && strings.Contains(message, secret) {
+			message = strings.ReplaceAll(message, secret, "xxxxx")
+		}
+	}
+	if message == err.Error() {
+		return err
+	}
+	return errors.New(message)
+}
+
+// dsnSecrets возвращает пароль из строки подключения в URL-формате (в учетных данных
+// или параметре password) или в формате ключ=значение, в исходном и экранированном виде
+func dsnSecrets(connectionString string) []string {
+	if strings.Contains(connectionString, "://") {
+		u, err := url.Parse(connectionString)
+		if err == nil {
+			var secrets []string
+			if password, ok := u.User.Password(); ok {
+				secrets = append(secrets, password, url.QueryEscape(password), url.PathEscape(password))
+			}
+			if password := u.Query().Get("password"); password != "" {
+				secrets = append(secrets, password, url.QueryEscape(password))
+			}
+			return secrets
+		}
+
+		// URL не разбирается: берем все между "user:" и последним "@" как есть
+		_, rest, _ := strings.Cut(connectionString, "://")
+		if at := strings.LastIndex(rest, "@"); at >= 0 {
+			if _, password, ok := strings.Cut(rest[:at], ":"); ok {
+				return []string{password}
+			}
+		}
+		return nil
+	}
+
+	// Значение в кавычках заменяется раньше, чем сам пароль, который драйвер
+	// может вывести без кавычек и экранирования
+	var secrets []string
+	for _, match := range dsnPassword.FindAllStringSubmatch(connectionString, -1) {
+		secrets = append(secrets, match[1], unquoteDSNValue(match[1]))
+	}
+	return secrets
+}
+
+// unquoteDSNValue снимает кавычки libpq и экранирование \' и \\ со значения
+func unquoteDSNValue(value string) string {
+	if len(value) < 2 || !strings.HasPrefix(value, "'") || !strings.HasSuffix(value, "'") {
+		return value
+	}
+
+	var unquoted strings.Builder
+	for i := 1; i < len(value)-1; i++ {
+		if value[i] == '\\' && i+1 < len(value)-1 {
+			i++
+		}
+		unquoted.WriteByte(value[i])
+	}
+	return unquoted.String()
+}
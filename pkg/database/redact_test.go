@@ -0,0 +1,75 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// quoteValue заключает значение в кавычки по правилам libpq
+func quoteValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+func TestRedactError(t *testing.T) {
+	passwords := []struct {
+		name, password string
+	}{
+		{"space", "pass word"},
+		{"at", "p@ssw0rd"},
+		{"quote", "it's-secret"},
+		{"backslash", `back\slash`},
+		{"equals", "key=value"},
+		{"question", "why?not"},
+		{"hash", "hash#tag"},
+		{"percent", "100%sure"},
+		{"all", `s p@c'e\=?#%25`},
+	}
+
+	for _, tt := range passwords {
+		t.Run(tt.name, func(t *testing.T) {
+			forms := map[string]string{
+				"key=value": "host=db user=app password=" + quoteValue(tt.password) + " dbname=subs",
+				"url": (&url.URL{Scheme: "postgres", User: url.UserPassword("app", tt.password),
+					Host: "db", Path: "/subs"}).String(),
+				"query": (&url.URL{Scheme: "postgres", Host: "db", Path: "/subs",
+					RawQuery: url.Values{"password": {tt.password}}.Encode()}).String(),
+			}
+
+			for form, dsn := range forms {
+				// Драйверы включают в ошибку строку подключения целиком или только пароль
+				for _, message := range []string{`cannot parse "` + dsn + `"`, "bad password " + tt.password} {
+					err := redactError(errors.New(message), dsn)
+					for _, leak := range []string{tt.password, url.QueryEscape(tt.password), url.PathEscape(tt.password)} {
+						if strings.Contains(err.Error(), leak) {
+							t.Errorf("%s: %q leaks password as %q", form, err, leak)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRedactErrorUnparsableURL(t *testing.T) {
+	dsn := "postgres://app:se%zzcret@db/subs"
+	err := redactError(fmt.Errorf("parse %q: invalid URL escape", dsn), dsn)
+	if strings.Contains(err.Error(), "se%zzcret") {
+		t.Errorf("%q leaks password", err)
+	}
+}
+
+func TestRedactErrorKeepsUnrelated(t *testing.T) {
+	if redactError(nil, "password=secret") != nil {
+		t.Error("redactError(nil) != nil")
+	}
+
+	err := errors.New("connection refused")
+	if got := redactError(err, "host=db password=secret"); got != err {
+		t.Errorf("redactError() = %v, want the original error", got)
+	}
+}
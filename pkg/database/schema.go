@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// migrationLockID — ключ рекомендательной блокировки, под которой реплики
+// по очереди проверяют и применяют миграции
+const migrationLockID int64 = 7_356_021_114
+
+const schemaPollInterval = time.Second
+
+var (
+	latestPostgresVersion = mustLatestVersion(migrationsFS, "migrations")
+	latestSQLiteVersion   = mustLatestVersion(sqliteMigrationsFS, "migrations_sqlite")
+)
+
+// mustLatestVersion возвращает версию последней встроенной миграции
+func mustLatestVersion(fsys fs.FS, dir string) uint {
+	d, err := iofs.New(fsys, dir)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read embedded migrations: %v", err))
+	}
+	defer d.Close()
+
+	version, err := d.First()
+	if err != nil {
+		panic(fmt.Sprintf("failed to read embedded migrations: %v", err))
+	}
+	for {
+		next, err := d.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version
+		}
+		if err != nil {
+			panic(fmt.Sprintf("failed to read embedded migrations: %v", err))
+		}
+		version = next
+	}
+}
+
+// ExpectedSchemaVersion возвращает версию схемы, с которой работает этот код
+func (db *DB) ExpectedSchemaVersion() uint {
+	return db.latest
+}
+
+// SchemaVersion читает текущую версию схемы из таблицы golang-migrate.
+// dirty означает, что последняя миграция завершилась с ошибкой.
+func (db *DB) SchemaVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	err = db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// schemaUpToDate сообщает, применены ли все встроенные миграции
+func (db *DB) schemaUpToDate(ctx context.Context) bool {
+	version, dirty, err := db.SchemaVersion(ctx)
+	return err == nil && !dirty && version >= db.latest
+}
+
+// MigratePostgres применяет миграции, пока держит рекомендательную блокировку.
+// Одновременно запущенные реплики ждут блокировку не дольше timeout; получив ее,
+// они видят схему, обновленную первой репликой, и ничего не применяют.
+func MigratePostgres(ctx context.Context, db *DB, connectionString string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if err := acquireMigrationLock(ctx, conn); err != nil {
+		return err
+	}
+	defer func() {
+		// Блокировка сессионная: снимаем ее даже после отмены ctx
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			log.Printf("Error releasing migration lock: %v", err)
+		}
+	}()
+
+	if db.schemaUpToDate(ctx) {
+		log.Printf("Schema is up to date (version %d)", db.latest)
+		return nil
+	}
+
+	return RunMigrations(connectionString)
+}
+
+func acquireMigrationLock(ctx context.Context, conn *sql.Conn) error {
+	for attempt := 1; ; attempt++ {
+		var acquired bool
+		if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, migrationLockID).Scan(&acquired); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if acquired {
+			return nil
+		}
+
+		if attempt == 1 {
+			log.Println("Migrations are running on another replica, waiting for the lock")
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for migration lock: %w", ctx.Err())
+		case <-time.After(schemaPollInterval):
+		}
+	}
+}
+
+// WaitForSchema ждет не дольше timeout, пока схема не достигнет ожидаемой версии.
+// Используется, когда миграции применяет не API, а отдельная команда.
+func WaitForSchema(ctx context.Context, db *DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		if db.schemaUpToDate(ctx) {
+			log.Printf("Schema is up to date (version %d)", db.latest)
+			return nil
+		}
+
+		if attempt == 1 {
+			log.Printf("Waiting for schema version %d", db.latest)
+		}
+		select {
+		case <-ctx.Done():
+			version, dirty, err := db.SchemaVersion(context.Background())
+			if err != nil {
+				return fmt.Errorf("schema is not ready: %w", err)
+			}
+			return fmt.Errorf("schema version %d (dirty=%t) did not reach %d in %s", version, dirty, db.latest, timeout)
+		case <-time.After(schemaPollInterval):
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqlitePragmas включают внешние ключи, ожидание блокировки и журнал WAL.
+// _txlock=immediate берет блокировку на запись в начале транзакции, иначе
+// параллельные транзакции чтение-запись падают с SQLITE_BUSY.
+var sqlitePragmas = url.Values{
+	"_pragma": {"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"},
+	"_txlock": {"immediate"},
+}
+
+// sqliteMigrationPragmas отключают внешние ключи на время миграций: SQLite меняет
+// ограничения таблицы только ее пересозданием, а DROP TABLE при включенных
+// внешних ключах каскадно удалил бы строки ссылающихся на нее таблиц
+var sqliteMigrationPragmas = url.Values{
+	"_pragma": {"foreign_keys(0)", "busy_timeout(5000)", "journal_mode(WAL)"},
+	"_txlock": {"immediate"},
+}
+
+func openSQLite(path string, pragmas url.Values) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return db, nil
+}
+
+func NewSQLite(path string) (*DB, error) {
+	db, err := openSQLite(path, sqlitePragmas)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Successfully connected to SQLite: %s", path)
+	return &DB{DB: db, latest: latestSQLiteVersion}, nil
+}